@@ -0,0 +1,182 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Alert 规则触发产生的告警
+// 通过 Notifier 发送时按 JSON 标签序列化
+type Alert struct {
+	Rule     string            `json:"rule"`
+	Severity Severity          `json:"severity"`
+	Message  string            `json:"message"`
+	Block    uint64            `json:"block,omitempty"` // 触发时的区块高度 (Values 中的 number 字段)
+	Time     time.Time         `json:"time"`
+	Values   Values            `json:"values,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"` // 附加信息，如代币、交易哈希
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("[%s] %s", a.Severity, a.Message)
+}
+
+// firing 规则最近一次触发的时间和区块，用于冷却判断
+type firing struct {
+	at    time.Time
+	block uint64
+}
+
+// Engine 按当前规则检查字段值，并在冷却期内抑制同一规则的重复告警
+// 规则可以在运行时通过 SetRules、LoadFile 或 Watch 替换，并发安全
+type Engine struct {
+	mu     sync.Mutex
+	rules  []Rule
+	fields []string
+	last   map[string]firing
+	now    func() time.Time
+}
+
+// NewEngine 创建 Engine，fields 为监控程序提供的字段名，用于加载时检查规则引用的字段
+// fields 为空时不检查
+func NewEngine(fields []string) *Engine {
+	return &Engine{
+		fields: fields,
+		last:   make(map[string]firing),
+		now:    time.Now,
+	}
+}
+
+// SetRules 检查并替换当前规则；检查失败时保留原有规则
+// 同名规则的冷却状态保留，已删除规则的冷却状态被清除
+func (e *Engine) SetRules(rs *RuleSet) error {
+	if err := rs.Validate(e.fields); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = append([]Rule(nil), rs.Rules...)
+	names := make(map[string]bool, len(e.rules))
+	for _, rule := range e.rules {
+		names[rule.Name] = true
+	}
+	for name := range e.last {
+		if !names[name] {
+			delete(e.last, name)
+		}
+	}
+	return nil
+}
+
+// LoadFile 读取规则文件并替换当前规则，返回加载的规则
+func (e *Engine) LoadFile(path string) (*RuleSet, error) {
+	rs, err := LoadRules(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.SetRules(rs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// Rules 返回当前规则的副本
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Rule(nil), e.rules...)
+}
+
+// Evaluate 用 values 检查所有启用的规则，返回触发且不在冷却期内的告警
+// 规则引用的字段不在 values 中时该规则不触发
+func (e *Engine) Evaluate(values Values) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	block := uint64(values["number"])
+
+	var alerts []Alert
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if rule.Disabled || !rule.match(values) {
+			continue
+		}
+		if last, ok := e.last[rule.Name]; ok && rule.cooling(last, now, block) {
+			continue
+		}
+		e.last[rule.Name] = firing{at: now, block: block}
+
+		alerts = append(alerts, Alert{
+			Rule:     rule.Name,
+			Severity: rule.Severity,
+			Message:  rule.format(values, rule.conditions()[0]),
+			Block:    block,
+			Time:     now,
+			Values:   values,
+		})
+	}
+	return alerts
+}
+
+// match 所有条件都满足时返回 true
+func (r *Rule) match(values Values) bool {
+	for _, c := range r.conditions() {
+		value, ok := values[c.Field]
+		if !ok || !c.Op.compare(value, c.Threshold) {
+			return false
+		}
+	}
+	return true
+}
+
+// cooling 判断规则是否仍在上次触发后的冷却期内 (时间或区块数任一未满足即为冷却中)
+func (r *Rule) cooling(last firing, now time.Time, block uint64) bool {
+	if r.Cooldown > 0 && now.Sub(last.at) < time.Duration(r.Cooldown) {
+		return true
+	}
+	if r.CooldownBlocks > 0 && block >= last.block && block-last.block < r.CooldownBlocks {
+		return true
+	}
+	return false
+}
+
+// Watch 每隔 interval 检查规则文件的修改时间和大小，变化时重新加载，直到 ctx 结束
+// 每次重新加载后调用 report: 成功时 err 为 nil，失败时保留原有规则并传入错误
+func (e *Engine) Watch(ctx context.Context, path string, interval time.Duration, report func(rs *RuleSet, err error)) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+
+	modTime, size := stat()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m, s := stat()
+		if m.Equal(modTime) && s == size {
+			continue
+		}
+		modTime, size = m, s
+
+		rs, err := e.LoadFile(path)
+		if report != nil {
+			report(rs, err)
+		}
+	}
+}
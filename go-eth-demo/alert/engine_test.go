@@ -0,0 +1,232 @@
+package alert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动前进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestEngine 创建使用 clock 的 Engine 并加载 rules
+func newTestEngine(t *testing.T, clock *fakeClock, rules ...Rule) *Engine {
+	t.Helper()
+	e := NewEngine(nil)
+	e.now = clock.Now
+	if err := e.SetRules(&RuleSet{Rules: rules}); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	return e
+}
+
+// fired 返回触发的规则名
+func fired(alerts []Alert) []string {
+	names := []string{}
+	for _, a := range alerts {
+		names = append(names, a.Rule)
+	}
+	return names
+}
+
+func busyRule() Rule {
+	return Rule{Name: "busy", Condition: Condition{Field: "tx_count", Op: OpGreater, Threshold: 200}}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	clock := newFakeClock()
+	multi := Rule{Name: "full and slow", Conditions: []Condition{
+		{Field: "gas_usage_percent", Op: OpGreaterEqual, Threshold: 95},
+		{Field: "block_time_seconds", Op: OpGreater, Threshold: 20},
+	}}
+	disabled := busyRule()
+	disabled.Name, disabled.Disabled = "disabled", true
+	e := newTestEngine(t, clock, busyRule(), multi, disabled)
+
+	tests := []struct {
+		values Values
+		want   []string
+	}{
+		{Values{"number": 1, "tx_count": 100}, []string{}},
+		{Values{"number": 2, "gas_usage_percent": 99}, []string{}}, // 缺少字段的规则不触发
+		{Values{"number": 3, "tx_count": 250, "gas_usage_percent": 95, "block_time_seconds": 25}, []string{"busy", "full and slow"}},
+	}
+	for _, tt := range tests {
+		if got := fired(e.Evaluate(tt.values)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Evaluate(%v) fired %v, want %v", tt.values, got, tt.want)
+		}
+	}
+
+	alert := e.Evaluate(Values{"number": 4, "tx_count": 300})
+	if len(alert) != 1 {
+		t.Fatalf("alerts = %v, want busy", alert)
+	}
+	if a := alert[0]; a.Severity != DefaultSeverity || a.Block != 4 || !a.Time.Equal(clock.now) || a.Message != "busy: tx_count > 200 (300)" {
+		t.Errorf("alert = %+v", a)
+	}
+}
+
+func TestEngineCooldown(t *testing.T) {
+	timeRule := busyRule()
+	timeRule.Name, timeRule.Cooldown = "time", Duration(time.Minute)
+	blockRule := busyRule()
+	blockRule.Name, blockRule.CooldownBlocks = "blocks", 3
+	bothRule := busyRule()
+	bothRule.Name, bothRule.Cooldown, bothRule.CooldownBlocks = "both", Duration(time.Minute), 3
+
+	clock := newFakeClock()
+	e := newTestEngine(t, clock, timeRule, blockRule, bothRule)
+
+	// 每一步前进 advance 后检查 block 高度的区块
+	steps := []struct {
+		advance time.Duration
+		block   float64
+		want    []string
+	}{
+		{0, 100, []string{"time", "blocks", "both"}},
+		{10 * time.Second, 101, []string{}},
+		{51 * time.Second, 102, []string{"time"}},   // 距上次 61 秒，区块冷却未满
+		{0, 103, []string{"blocks", "both"}},        // 距上次 3 个区块
+		{10 * time.Second, 106, []string{"blocks"}}, // 区块冷却已满，时间冷却未满
+		{0, 107, []string{}},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		got := fired(e.Evaluate(Values{"number": step.block, "tx_count": 300}))
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d (block %v): fired %v, want %v", i, step.block, got, step.want)
+		}
+	}
+}
+
+func TestEngineSetRulesKeepsCooldown(t *testing.T) {
+	clock := newFakeClock()
+	busy := busyRule()
+	busy.Cooldown = Duration(time.Minute)
+	other := busyRule()
+	other.Name, other.Cooldown = "other", Duration(time.Minute)
+	e := newTestEngine(t, clock, busy, other)
+
+	values := Values{"number": 1, "tx_count": 300}
+	if got := fired(e.Evaluate(values)); len(got) != 2 {
+		t.Fatalf("fired %v, want both rules", got)
+	}
+
+	// 修改 busy 的阈值并删除 other：busy 的冷却状态保留，other 的被清除
+	busy.Threshold = 250
+	if err := e.SetRules(&RuleSet{Rules: []Rule{busy}}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(10 * time.Second)
+	if got := fired(e.Evaluate(values)); len(got) != 0 {
+		t.Errorf("fired %v after reload, want busy still cooling", got)
+	}
+
+	if err := e.SetRules(&RuleSet{Rules: []Rule{busy, other}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fired(e.Evaluate(values)); !reflect.DeepEqual(got, []string{"other"}) {
+		t.Errorf("fired %v after re-adding other, want [other]", got)
+	}
+}
+
+func TestEngineSetRulesInvalidKeepsRules(t *testing.T) {
+	e := newTestEngine(t, newFakeClock(), busyRule())
+	e.fields = []string{"number", "tx_count"}
+
+	bad := busyRule()
+	bad.Field = "txcount"
+	if err := e.SetRules(&RuleSet{Rules: []Rule{bad}}); err == nil {
+		t.Fatal("SetRules() with unknown field succeeded")
+	}
+	if rules := e.Rules(); len(rules) != 1 || rules[0].Field != "tx_count" {
+		t.Errorf("Rules() = %+v, want original busy rule", rules)
+	}
+}
+
+func TestEngineWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(data string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// 显式修改时间，避免文件系统时间精度不足时检测不到变化
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("rules:\n  - {name: busy, field: tx_count, op: '>', threshold: 200}\n", start)
+
+	e := NewEngine([]string{"number", "tx_count"})
+	if _, err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		rs  *RuleSet
+		err error
+	}
+	reports := make(chan result, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Watch(ctx, path, 5*time.Millisecond, func(rs *RuleSet, err error) { reports <- result{rs, err} })
+	}()
+	time.Sleep(20 * time.Millisecond) // 等待 Watch 记录文件的初始状态
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	next := func() result {
+		t.Helper()
+		select {
+		case r := <-reports:
+			return r
+		case <-time.After(2 * time.Second):
+			t.Fatal("no reload reported")
+			return result{}
+		}
+	}
+
+	// 修改后重新加载
+	write("rules:\n  - {name: busy, field: tx_count, op: '>', threshold: 100}\n  - {name: empty, field: tx_count, op: '==', threshold: 0}\n", start.Add(time.Minute))
+	if r := next(); r.err != nil || len(r.rs.Rules) != 2 {
+		t.Fatalf("reload = %+v, want 2 rules", r)
+	}
+	if rules := e.Rules(); len(rules) != 2 || rules[0].Threshold != 100 {
+		t.Errorf("Rules() = %+v after reload", rules)
+	}
+
+	// 格式错误时报告错误并保留原有规则
+	write("rules:\n  - {name: busy, field: tx_count, op: '=>', threshold: 1}\n", start.Add(2*time.Minute))
+	if r := next(); r.err == nil || r.rs != nil {
+		t.Fatalf("reload of invalid rules = %+v, want error", r)
+	}
+	if rules := e.Rules(); len(rules) != 2 || rules[0].Threshold != 100 {
+		t.Errorf("Rules() = %+v after failed reload, want previous rules", rules)
+	}
+
+	// 文件未变化时不重新加载
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case r := <-reports:
+		t.Errorf("unexpected reload %+v without changes", r)
+	default:
+	}
+}
@@ -0,0 +1,104 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 告警渠道的默认设置
+const (
+	DefaultRateLimit = 30 // 每个渠道每分钟最多发送的告警数
+	DefaultBurst     = 5
+)
+
+// SinksFromEnv 按环境变量配置告警渠道，没有配置任何渠道时返回空列表
+// getenv 通常为 os.Getenv，可以替换为其他来源
+//
+//	ALERT_WEBHOOK_URL          通用 Webhook，请求体为告警的 JSON
+//	ALERT_SLACK_WEBHOOK_URL    Slack Incoming Webhook
+//	ALERT_DISCORD_WEBHOOK_URL  Discord Webhook
+//	ALERT_SMTP_ADDR            SMTP 服务器 host:port，需同时设置 ALERT_EMAIL_FROM 和 ALERT_EMAIL_TO (逗号分隔)
+//	ALERT_SMTP_USERNAME/ALERT_SMTP_PASSWORD  SMTP 认证，可选
+//	ALERT_LOG_FILE             追加写入的 JSON 告警日志文件
+//	ALERT_MIN_SEVERITY         发送的最低级别 (info/warning/critical)，默认 info
+//	ALERT_EMAIL_MIN_SEVERITY   邮件的最低级别，默认 warning
+//	ALERT_RATE_LIMIT           每个渠道每分钟最多发送的告警数，默认 30，0 表示不限流 (日志文件不限流)
+func SinksFromEnv(getenv func(string) string) ([]Sink, error) {
+	var err error
+	minSeverity := SeverityInfo
+	if v := getenv("ALERT_MIN_SEVERITY"); v != "" {
+		if minSeverity, err = ParseSeverity(v); err != nil {
+			return nil, fmt.Errorf("ALERT_MIN_SEVERITY: %w", err)
+		}
+	}
+	emailSeverity := SeverityWarning
+	if v := getenv("ALERT_EMAIL_MIN_SEVERITY"); v != "" {
+		if emailSeverity, err = ParseSeverity(v); err != nil {
+			return nil, fmt.Errorf("ALERT_EMAIL_MIN_SEVERITY: %w", err)
+		}
+	}
+	rateLimit := float64(DefaultRateLimit)
+	if v := getenv("ALERT_RATE_LIMIT"); v != "" {
+		if rateLimit, err = strconv.ParseFloat(v, 64); err != nil || rateLimit < 0 {
+			return nil, fmt.Errorf("ALERT_RATE_LIMIT: invalid value %q", v)
+		}
+	}
+
+	var sinks []Sink
+	add := func(name string, n Notifier, severity Severity, limit float64) {
+		sinks = append(sinks, Sink{
+			Name:        name,
+			Notifier:    n,
+			MinSeverity: severity,
+			RateLimit:   limit,
+			Burst:       DefaultBurst,
+		})
+	}
+
+	if url := getenv("ALERT_WEBHOOK_URL"); url != "" {
+		add("webhook", NewWebhookNotifier(url), minSeverity, rateLimit)
+	}
+	if url := getenv("ALERT_SLACK_WEBHOOK_URL"); url != "" {
+		add("slack", NewSlackNotifier(url), minSeverity, rateLimit)
+	}
+	if url := getenv("ALERT_DISCORD_WEBHOOK_URL"); url != "" {
+		add("discord", NewDiscordNotifier(url), minSeverity, rateLimit)
+	}
+	if addr := getenv("ALERT_SMTP_ADDR"); addr != "" {
+		from := getenv("ALERT_EMAIL_FROM")
+		to := splitList(getenv("ALERT_EMAIL_TO"))
+		if from == "" || len(to) == 0 {
+			return nil, fmt.Errorf("ALERT_SMTP_ADDR requires ALERT_EMAIL_FROM and ALERT_EMAIL_TO")
+		}
+		if emailSeverity.rank() < minSeverity.rank() {
+			emailSeverity = minSeverity
+		}
+		email := NewEmailNotifier(addr, getenv("ALERT_SMTP_USERNAME"), getenv("ALERT_SMTP_PASSWORD"), from, to)
+		add("email", email, emailSeverity, rateLimit)
+	}
+	if path := getenv("ALERT_LOG_FILE"); path != "" {
+		add("file", NewFileNotifier(path), minSeverity, 0)
+	}
+	return sinks, nil
+}
+
+// NewDispatcherFromEnv 按 SinksFromEnv 的环境变量创建分发器
+func NewDispatcherFromEnv(getenv func(string) string, onError ErrorHandler) (*Dispatcher, error) {
+	sinks, err := SinksFromEnv(getenv)
+	if err != nil {
+		return nil, err
+	}
+	return NewDispatcher(onError, sinks...), nil
+}
+
+// splitList 按逗号拆分并去掉空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
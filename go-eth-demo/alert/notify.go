@@ -0,0 +1,232 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/local/go-eth-demo/utils"
+)
+
+var (
+	// ErrRateLimited 告警超过渠道的发送速率被丢弃
+	ErrRateLimited = errors.New("alert dropped: sink rate limit exceeded")
+	// ErrQueueFull 渠道的发送队列已满，告警被丢弃
+	ErrQueueFull = errors.New("alert dropped: sink queue full")
+)
+
+// sendTimeout 单次发送的超时时间
+const sendTimeout = 30 * time.Second
+
+// Notifier 告警通知渠道
+// 返回 Permanent 包装的错误表示重试也不会成功 (如地址或认证错误)，其余错误按重试策略重试
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// NotifierFunc 将普通函数转换为 Notifier
+type NotifierFunc func(ctx context.Context, a Alert) error
+
+// Notify 实现 Notifier 接口
+func (f NotifierFunc) Notify(ctx context.Context, a Alert) error {
+	return f(ctx, a)
+}
+
+// permanentError 不需要重试的发送错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记 err 为不可重试的错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断 err 是否由 Permanent 标记
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// retryAfterError 带有服务端要求的最短重试等待时间的发送错误
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// RetryAfter 标记 err 至少等待 delay 后才能重试，通常来自响应的 Retry-After 头
+// 重试等待时间取 delay 和重试策略退避时间中的较大值
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// Sink 一个通知渠道及其发送策略
+type Sink struct {
+	Name        string
+	Notifier    Notifier
+	MinSeverity Severity          // 低于该级别的告警不发送，为空时全部发送
+	RateLimit   float64           // 每分钟最多发送的告警数，超出的告警被丢弃，0 表示不限流
+	Burst       int               // 允许的突发告警数，小于 1 时按 1 处理
+	Retry       utils.RetryPolicy // 发送失败的重试策略，MaxAttempts 为 0 时使用 utils.DefaultRetryPolicy
+	QueueSize   int               // 等待发送的告警数上限，0 时为 64
+}
+
+// ErrorHandler 接收告警发送失败、限流和队列已满的错误
+type ErrorHandler func(sink string, a Alert, err error)
+
+// Dispatcher 把告警分发到多个渠道
+// 每个渠道有独立的队列和发送协程，慢速或故障的渠道不会阻塞监控程序和其他渠道
+type Dispatcher struct {
+	mu      sync.RWMutex
+	closed  bool
+	workers []*sinkWorker
+	wg      sync.WaitGroup
+}
+
+// sinkWorker 单个渠道的发送队列和限流器
+type sinkWorker struct {
+	sink    Sink
+	queue   chan Alert
+	limiter *utils.RateLimiter
+	onError ErrorHandler
+}
+
+// NewDispatcher 创建分发器并启动各渠道的发送协程，onError 可以为 nil
+func NewDispatcher(onError ErrorHandler, sinks ...Sink) *Dispatcher {
+	if onError == nil {
+		onError = func(string, Alert, error) {}
+	}
+
+	d := &Dispatcher{}
+	for _, sink := range sinks {
+		if sink.Retry.MaxAttempts == 0 {
+			sink.Retry = utils.DefaultRetryPolicy()
+		}
+		if sink.QueueSize <= 0 {
+			sink.QueueSize = 64
+		}
+		w := &sinkWorker{
+			sink:    sink,
+			queue:   make(chan Alert, sink.QueueSize),
+			limiter: utils.NewRateLimiter(sink.RateLimit/60, sink.Burst),
+			onError: onError,
+		}
+		d.workers = append(d.workers, w)
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			w.run()
+		}()
+	}
+	return d
+}
+
+// Sinks 返回已配置的渠道名称
+func (d *Dispatcher) Sinks() []string {
+	if d == nil {
+		return nil
+	}
+	names := make([]string, len(d.workers))
+	for i, w := range d.workers {
+		names[i] = w.sink.Name
+	}
+	return names
+}
+
+// Notify 把告警放入各渠道的发送队列后立即返回，实际发送的结果通过 ErrorHandler 报告
+// nil 分发器和已关闭的分发器忽略告警
+func (d *Dispatcher) Notify(ctx context.Context, a Alert) error {
+	if d == nil {
+		return nil
+	}
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return nil
+	}
+
+	for _, w := range d.workers {
+		if a.Severity.rank() < w.sink.MinSeverity.rank() {
+			continue
+		}
+		select {
+		case w.queue <- a:
+		default:
+			w.onError(w.sink.Name, a, ErrQueueFull)
+		}
+	}
+	return nil
+}
+
+// Close 停止接收新告警，等待队列中的告警发送完毕
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// run 依次发送队列中的告警，直到队列关闭
+func (w *sinkWorker) run() {
+	for a := range w.queue {
+		if !w.limiter.Allow() {
+			w.onError(w.sink.Name, a, ErrRateLimited)
+			continue
+		}
+		if err := w.send(a); err != nil {
+			w.onError(w.sink.Name, a, err)
+		}
+	}
+}
+
+// send 发送一条告警，失败时按重试策略退避重试，不可重试的错误直接返回
+// 错误由 RetryAfter 标记时等待时间不少于其要求的时间
+func (w *sinkWorker) send(a Alert) error {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := w.sink.Notifier.Notify(ctx, a)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) || attempt >= w.sink.Retry.MaxAttempts {
+			return fmt.Errorf("failed after %d attempt(s): %w", attempt, err)
+		}
+
+		delay := w.sink.Retry.Backoff(attempt)
+		var retryAfter *retryAfterError
+		if errors.As(err, &retryAfter) && retryAfter.delay > delay {
+			delay = retryAfter.delay
+		}
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,487 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/local/go-eth-demo/utils"
+)
+
+// testAlert 测试使用的告警
+func testAlert() Alert {
+	return Alert{
+		Rule:     "high_gas",
+		Severity: SeverityCritical,
+		Message:  "Gas 使用率 97%",
+		Block:    100,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Values:   Values{"gas_utilization": 97},
+	}
+}
+
+// fastRetry 测试使用的重试策略，退避时间很短
+func fastRetry(attempts int) utils.RetryPolicy {
+	return utils.RetryPolicy{MaxAttempts: attempts, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1}
+}
+
+// errorRecorder 收集 ErrorHandler 报告的错误
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) handle(sink string, a Alert, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestWebhookPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		notifier func(url string) *WebhookNotifier
+		check    func(t *testing.T, body map[string]any)
+	}{
+		{
+			name:     "webhook",
+			notifier: NewWebhookNotifier,
+			check: func(t *testing.T, body map[string]any) {
+				if body["rule"] != "high_gas" || body["severity"] != "critical" || body["block"] != float64(100) {
+					t.Errorf("unexpected alert JSON: %v", body)
+				}
+				values, _ := body["values"].(map[string]any)
+				if values["gas_utilization"] != float64(97) {
+					t.Errorf("values = %v, want gas_utilization 97", body["values"])
+				}
+			},
+		},
+		{
+			name:     "slack",
+			notifier: NewSlackNotifier,
+			check: func(t *testing.T, body map[string]any) {
+				if want := "*[CRITICAL] high_gas*\nGas 使用率 97%"; body["text"] != want || len(body) != 1 {
+					t.Errorf("body = %v, want only text %q", body, want)
+				}
+			},
+		},
+		{
+			name:     "discord",
+			notifier: NewDiscordNotifier,
+			check: func(t *testing.T, body map[string]any) {
+				if want := "*[CRITICAL] high_gas*\nGas 使用率 97%"; body["content"] != want || len(body) != 1 {
+					t.Errorf("body = %v, want only content %q", body, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", ct)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid JSON body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			if err := tt.notifier(server.URL).Notify(context.Background(), testAlert()); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestWebhookStatusClassification(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		wantErr    bool
+		permanent  bool
+		wantDelay  time.Duration
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNoContent},
+		{status: http.StatusBadRequest, wantErr: true, permanent: true},
+		{status: http.StatusNotFound, wantErr: true, permanent: true},
+		{status: http.StatusTooManyRequests, wantErr: true},
+		{status: http.StatusTooManyRequests, retryAfter: "7", wantErr: true, wantDelay: 7 * time.Second},
+		{status: http.StatusInternalServerError, wantErr: true},
+		{status: http.StatusServiceUnavailable, retryAfter: "2", wantErr: true, wantDelay: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status)+tt.retryAfter, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookNotifier(server.URL).Notify(context.Background(), testAlert())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, IsPermanent(err), tt.permanent)
+			}
+
+			var retryAfter *retryAfterError
+			if tt.wantDelay > 0 {
+				if !errors.As(err, &retryAfter) || retryAfter.delay != tt.wantDelay {
+					t.Errorf("error %v does not carry Retry-After %v", err, tt.wantDelay)
+				}
+			} else if errors.As(err, &retryAfter) {
+				t.Errorf("unexpected Retry-After on %v", err)
+			}
+		})
+	}
+}
+
+func TestDispatcherRetriesRetryableStatus(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) < 3 {
+					w.WriteHeader(status)
+				}
+			}))
+			defer server.Close()
+
+			var rec errorRecorder
+			d := NewDispatcher(rec.handle, Sink{Name: "webhook", Notifier: NewWebhookNotifier(server.URL), Retry: fastRetry(3)})
+			d.Notify(context.Background(), testAlert())
+			d.Close()
+
+			if got := calls.Load(); got != 3 {
+				t.Errorf("webhook called %d times, want 3", got)
+			}
+			if errs := rec.errors(); len(errs) != 0 {
+				t.Errorf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestDispatcherHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	var rec errorRecorder
+	d := NewDispatcher(rec.handle, Sink{Name: "webhook", Notifier: NewWebhookNotifier(server.URL), Retry: fastRetry(2)})
+	start := time.Now()
+	d.Notify(context.Background(), testAlert())
+	d.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("webhook called %d times, want 2", got)
+	}
+	if errs := rec.errors(); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestDispatcherDoesNotRetryPermanent(t *testing.T) {
+	var calls atomic.Int32
+	notifier := NotifierFunc(func(ctx context.Context, a Alert) error {
+		calls.Add(1)
+		return Permanent(errors.New("bad credentials"))
+	})
+
+	var rec errorRecorder
+	d := NewDispatcher(rec.handle, Sink{Name: "test", Notifier: notifier, Retry: fastRetry(5)})
+	d.Notify(context.Background(), testAlert())
+	d.Close()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("notifier called %d times, want 1", got)
+	}
+	errs := rec.errors()
+	if len(errs) != 1 || !IsPermanent(errs[0]) {
+		t.Errorf("errors = %v, want one permanent error", errs)
+	}
+}
+
+func TestDispatcherRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	notifier := NotifierFunc(func(ctx context.Context, a Alert) error {
+		calls.Add(1)
+		return nil
+	})
+
+	var rec errorRecorder
+	d := NewDispatcher(rec.handle, Sink{Name: "test", Notifier: notifier, RateLimit: 1, Burst: 1})
+	for i := 0; i < 3; i++ {
+		d.Notify(context.Background(), testAlert())
+	}
+	d.Close()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("notifier called %d times, want 1", got)
+	}
+	errs := rec.errors()
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want 2 ErrRateLimited", errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("error = %v, want ErrRateLimited", err)
+		}
+	}
+}
+
+func TestDispatcherQueueFull(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	notifier := NotifierFunc(func(ctx context.Context, a Alert) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	var rec errorRecorder
+	d := NewDispatcher(rec.handle, Sink{Name: "test", Notifier: notifier, QueueSize: 1})
+
+	// 第一条告警被发送协程取出并阻塞，第二条占满队列，第三条被丢弃
+	d.Notify(context.Background(), testAlert())
+	<-started
+	d.Notify(context.Background(), testAlert())
+	d.Notify(context.Background(), testAlert())
+
+	errs := rec.errors()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrQueueFull) {
+		t.Errorf("errors = %v, want one ErrQueueFull", errs)
+	}
+
+	close(release)
+	<-started
+	d.Close()
+}
+
+func TestDispatcherMinSeverity(t *testing.T) {
+	var got []Severity
+	var mu sync.Mutex
+	notifier := NotifierFunc(func(ctx context.Context, a Alert) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, a.Severity)
+		return nil
+	})
+
+	d := NewDispatcher(nil, Sink{Name: "test", Notifier: notifier, MinSeverity: SeverityWarning})
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		a := testAlert()
+		a.Severity = severity
+		d.Notify(context.Background(), a)
+	}
+	d.Close()
+
+	if len(got) != 2 || got[0] != SeverityWarning || got[1] != SeverityCritical {
+		t.Errorf("sent severities = %v, want [warning critical]", got)
+	}
+}
+
+// smtpServer 最小的 SMTP 服务端，只支持 EHLO/MAIL/RCPT/DATA/QUIT，不提供 STARTTLS
+// rejectRcpt 非空时 RCPT 返回该响应
+type smtpServer struct {
+	listener   net.Listener
+	rejectRcpt string
+
+	mu   sync.Mutex
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T, rejectRcpt string) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpServer{listener: l, rejectRcpt: rejectRcpt}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-localhost")
+			reply("250 HELP")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = line[len("MAIL FROM:"):]
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if s.rejectRcpt != "" {
+				reply(s.rejectRcpt)
+				continue
+			}
+			s.mu.Lock()
+			s.to = append(s.to, line[len("RCPT TO:"):])
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := newSMTPServer(t, "")
+	n := NewEmailNotifier(server.listener.Addr().String(), "", "", "monitor@example.com", []string{"ops@example.com", "dev@example.com"})
+
+	if err := n.Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "<monitor@example.com>" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	if strings.Join(server.to, ",") != "<ops@example.com>,<dev@example.com>" {
+		t.Errorf("RCPT TO = %v", server.to)
+	}
+	for _, want := range []string{
+		"To: ops@example.com, dev@example.com\r\n",
+		"Subject: [CRITICAL] high_gas\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"Gas 使用率 97%\r\n",
+		"block: 100\r\n",
+		"gas_utilization: 97\r\n",
+	} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("message missing %q:\n%s", want, server.data)
+		}
+	}
+}
+
+func TestEmailNotifierRejectedIsPermanent(t *testing.T) {
+	server := newSMTPServer(t, "550 No such user")
+	n := NewEmailNotifier(server.listener.Addr().String(), "", "", "monitor@example.com", []string{"nobody@example.com"})
+
+	err := n.Notify(context.Background(), testAlert())
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("Notify() error = %v, want permanent error", err)
+	}
+}
+
+func TestFileNotifierWritesNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.log")
+	n := NewFileNotifier(path)
+
+	first := testAlert()
+	second := testAlert()
+	second.Rule = "reorg"
+	second.Severity = SeverityWarning
+	second.Labels = map[string]string{"depth": "2"}
+	for _, a := range []Alert{first, second} {
+		if err := n.Notify(context.Background(), a); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(string(data), "\n") {
+		t.Fatalf("want 2 newline-terminated lines, got %q", data)
+	}
+
+	for i, want := range []Alert{first, second} {
+		var got Alert
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i+1, err)
+		}
+		if got.Rule != want.Rule || got.Severity != want.Severity || !got.Time.Equal(want.Time) ||
+			got.Values["gas_utilization"] != 97 || got.Labels["depth"] != want.Labels["depth"] {
+			t.Errorf("line %d = %+v, want %+v", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package alert 按声明式规则检查监控指标并产生告警
+//
+// 规则写在 YAML 或 JSON 文件中，每条规则由一个或多个条件 (字段、比较运算符、阈值) 组成，
+// 全部条件满足时触发。字段由监控程序提供 (如区块的 tx_count、gas_usage_percent)，
+// 触发后在 cooldown 时间或 cooldown_blocks 个区块内不再重复告警。Engine.Watch 在运行期间
+// 重新加载修改过的规则文件，无需重启监控程序。
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity 告警级别
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// DefaultSeverity 未设置级别时使用的告警级别
+const DefaultSeverity = SeverityWarning
+
+// ParseSeverity 解析告警级别，空字符串返回 DefaultSeverity
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(strings.TrimSpace(s))); sev {
+	case "":
+		return DefaultSeverity, nil
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return sev, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q (info, warning, critical)", s)
+	}
+}
+
+// rank 级别的高低顺序，用于按最低级别过滤
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Operator 条件的比较运算符
+type Operator string
+
+const (
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpEqual        Operator = "=="
+	OpNotEqual     Operator = "!="
+)
+
+// compare 按运算符比较 value 和 threshold，未知运算符返回 false
+func (op Operator) compare(value, threshold float64) bool {
+	switch op {
+	case OpGreater:
+		return value > threshold
+	case OpGreaterEqual:
+		return value >= threshold
+	case OpLess:
+		return value < threshold
+	case OpLessEqual:
+		return value <= threshold
+	case OpEqual:
+		return value == threshold
+	case OpNotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+func (op Operator) valid() bool {
+	switch op {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual, OpEqual, OpNotEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Duration 规则文件中的时间长度，写作 "30s"、"5m" 等字符串
+type Duration time.Duration
+
+// UnmarshalText 解析 time.ParseDuration 格式的字符串
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText 输出 time.Duration 的字符串格式
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Condition 单个比较条件: 字段值 运算符 阈值
+type Condition struct {
+	Field     string   `yaml:"field" json:"field"`
+	Op        Operator `yaml:"op" json:"op"`
+	Threshold float64  `yaml:"threshold" json:"threshold"`
+}
+
+// Rule 告警规则
+// 只有一个条件时可以直接写 field/op/threshold，多个条件写在 conditions 中，全部满足时触发
+type Rule struct {
+	Name           string `yaml:"name" json:"name"`
+	Description    string `yaml:"description" json:"description"`
+	Condition      `yaml:",inline"`
+	Conditions     []Condition `yaml:"conditions" json:"conditions"`
+	Severity       Severity    `yaml:"severity" json:"severity"`               // 默认 DefaultSeverity (warning)
+	Cooldown       Duration    `yaml:"cooldown" json:"cooldown"`               // 触发后在该时间内不重复告警
+	CooldownBlocks uint64      `yaml:"cooldown_blocks" json:"cooldown_blocks"` // 触发后在该区块数内不重复告警
+	Message        string      `yaml:"message" json:"message"`                 // 支持 {字段名}、{value}、{threshold} 占位符
+	Disabled       bool        `yaml:"disabled" json:"disabled"`
+}
+
+// conditions 返回规则的全部条件，简写的单个条件排在最前
+func (r *Rule) conditions() []Condition {
+	if r.Field == "" {
+		return r.Conditions
+	}
+	return append([]Condition{r.Condition}, r.Conditions...)
+}
+
+// RuleSet 规则文件的内容
+type RuleSet struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Validate 检查规则名称唯一、运算符和级别合法；fields 非空时还检查条件引用的字段都存在
+// 未设置级别的规则使用 DefaultSeverity
+func (rs *RuleSet) Validate(fields []string) error {
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f] = true
+	}
+
+	var errs []error
+	names := make(map[string]bool, len(rs.Rules))
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("rule #%d: missing name", i+1))
+			continue
+		}
+		if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("rule %q: duplicate name", rule.Name))
+		}
+		names[rule.Name] = true
+
+		severity, err := ParseSeverity(string(rule.Severity))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+		}
+		rule.Severity = severity
+
+		conditions := rule.conditions()
+		if len(conditions) == 0 {
+			errs = append(errs, fmt.Errorf("rule %q: no conditions", rule.Name))
+		}
+		for _, c := range conditions {
+			if c.Field == "" {
+				errs = append(errs, fmt.Errorf("rule %q: condition missing field", rule.Name))
+			} else if len(known) > 0 && !known[c.Field] {
+				errs = append(errs, fmt.Errorf("rule %q: unknown field %q", rule.Name, c.Field))
+			}
+			if !c.Op.valid() {
+				errs = append(errs, fmt.Errorf("rule %q: invalid operator %q", rule.Name, c.Op))
+			}
+		}
+		if rule.Cooldown < 0 {
+			errs = append(errs, fmt.Errorf("rule %q: negative cooldown", rule.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseRules 解析 YAML 或 JSON 格式的规则，format 为 "yaml" 或 "json"，未知字段视为错误
+func ParseRules(data []byte, format string) (*RuleSet, error) {
+	rs := &RuleSet{}
+	switch format {
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(rs); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse rules: %w", err)
+		}
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(rs); err != nil {
+			return nil, fmt.Errorf("failed to parse rules: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rules format: %s", format)
+	}
+	return rs, nil
+}
+
+// LoadRules 根据扩展名读取 .yaml、.yml 或 .json 规则文件
+func LoadRules(path string) (*RuleSet, error) {
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = "yaml"
+	case ".json":
+		format = "json"
+	default:
+		return nil, fmt.Errorf("unsupported rules file format: %s (use .yaml, .yml or .json)", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	rs, err := ParseRules(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// Values 一次检查的字段值，如 {"number": 123, "tx_count": 250}
+type Values map[string]float64
+
+// Names 返回按字母排序的字段名，可作为 Validate 的已知字段列表
+func (v Values) Names() []string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// format 按规则消息模板替换占位符，未设置消息时使用 "名称: 字段 运算符 阈值 (实际值)"
+func (r *Rule) format(values Values, first Condition) string {
+	if r.Message == "" {
+		return fmt.Sprintf("%s: %s %s %s (%s)", r.Name, first.Field, first.Op,
+			formatValue(first.Threshold), formatValue(values[first.Field]))
+	}
+
+	pairs := []string{
+		"{value}", formatValue(values[first.Field]),
+		"{threshold}", formatValue(first.Threshold),
+		"{name}", r.Name,
+	}
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", formatValue(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(r.Message)
+}
+
+// formatValue 整数不带小数，其余保留两位小数
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,250 @@
+package alert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		wantErr string
+	}{
+		{
+			name:   "yaml",
+			format: "yaml",
+			data: `
+rules:
+  - name: busy
+    field: tx_count
+    op: ">"
+    threshold: 200
+    cooldown: 30s
+  - name: full and slow
+    conditions:
+      - {field: gas_usage_percent, op: ">=", threshold: 95}
+      - {field: block_time_seconds, op: ">", threshold: 20}
+    severity: critical
+    cooldown_blocks: 5
+`,
+		},
+		{
+			name:   "json",
+			format: "json",
+			data: `{"rules": [
+				{"name": "busy", "field": "tx_count", "op": ">", "threshold": 200, "cooldown": "30s"},
+				{"name": "full and slow", "severity": "critical", "cooldown_blocks": 5, "conditions": [
+					{"field": "gas_usage_percent", "op": ">=", "threshold": 95},
+					{"field": "block_time_seconds", "op": ">", "threshold": 20}
+				]}
+			]}`,
+		},
+		{
+			name:    "yaml unknown field",
+			format:  "yaml",
+			data:    "rules:\n  - name: busy\n    field: tx_count\n    op: '>'\n    treshold: 200\n",
+			wantErr: "treshold",
+		},
+		{
+			name:    "json unknown field",
+			format:  "json",
+			data:    `{"rules": [{"name": "busy", "field": "tx_count", "op": ">", "treshold": 200}]}`,
+			wantErr: "treshold",
+		},
+		{
+			name:    "invalid duration",
+			format:  "yaml",
+			data:    "rules:\n  - name: busy\n    field: tx_count\n    op: '>'\n    cooldown: soon\n",
+			wantErr: "invalid duration",
+		},
+		{
+			name:    "unsupported format",
+			format:  "toml",
+			data:    "",
+			wantErr: "unsupported rules format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := ParseRules([]byte(tt.data), tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRules() error = %v", err)
+			}
+
+			if len(rs.Rules) != 2 {
+				t.Fatalf("parsed %d rules, want 2", len(rs.Rules))
+			}
+			busy, full := rs.Rules[0], rs.Rules[1]
+			if c := busy.conditions(); len(c) != 1 || c[0] != (Condition{Field: "tx_count", Op: OpGreater, Threshold: 200}) {
+				t.Errorf("busy conditions = %+v", c)
+			}
+			if time.Duration(busy.Cooldown) != 30*time.Second {
+				t.Errorf("busy cooldown = %v, want 30s", time.Duration(busy.Cooldown))
+			}
+			if c := full.conditions(); len(c) != 2 || c[1].Field != "block_time_seconds" {
+				t.Errorf("full and slow conditions = %+v", c)
+			}
+			if full.Severity != SeverityCritical || full.CooldownBlocks != 5 {
+				t.Errorf("full and slow severity = %s, cooldown_blocks = %d", full.Severity, full.CooldownBlocks)
+			}
+			if err := rs.Validate([]string{"tx_count", "gas_usage_percent", "block_time_seconds"}); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRuleSetValidate(t *testing.T) {
+	valid := func(name string) Rule {
+		return Rule{Name: name, Condition: Condition{Field: "tx_count", Op: OpGreater, Threshold: 1}}
+	}
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		fields  []string
+		wantErr string
+	}{
+		{name: "valid", rules: []Rule{valid("a"), valid("b")}},
+		{name: "missing name", rules: []Rule{valid("")}, wantErr: "rule #1: missing name"},
+		{name: "duplicate name", rules: []Rule{valid("a"), valid("a")}, wantErr: `rule "a": duplicate name`},
+		{
+			name:    "invalid operator",
+			rules:   []Rule{{Name: "a", Condition: Condition{Field: "tx_count", Op: "=>"}}},
+			wantErr: `invalid operator "=>"`,
+		},
+		{
+			name:    "invalid operator in conditions",
+			rules:   []Rule{{Name: "a", Conditions: []Condition{{Field: "tx_count", Op: OpLess}, {Field: "gas_used", Op: "~"}}}},
+			wantErr: `invalid operator "~"`,
+		},
+		{name: "no conditions", rules: []Rule{{Name: "a"}}, wantErr: "no conditions"},
+		{
+			name:    "condition missing field",
+			rules:   []Rule{{Name: "a", Conditions: []Condition{{Op: OpLess}}}},
+			wantErr: "condition missing field",
+		},
+		{
+			name:    "unknown field",
+			rules:   []Rule{{Name: "a", Condition: Condition{Field: "txcount", Op: OpGreater}}},
+			fields:  []string{"tx_count"},
+			wantErr: `unknown field "txcount"`,
+		},
+		{
+			name:    "invalid severity",
+			rules:   []Rule{{Name: "a", Condition: Condition{Field: "tx_count", Op: OpGreater}, Severity: "urgent"}},
+			wantErr: `invalid severity "urgent"`,
+		},
+		{
+			name:    "negative cooldown",
+			rules:   []Rule{{Name: "a", Condition: Condition{Field: "tx_count", Op: OpGreater}, Cooldown: Duration(-time.Second)}},
+			wantErr: "negative cooldown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &RuleSet{Rules: tt.rules}
+			err := rs.Validate(tt.fields)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleSetValidateReportsAllErrors(t *testing.T) {
+	rs := &RuleSet{Rules: []Rule{
+		{Name: "a", Condition: Condition{Field: "tx_count", Op: "=>"}},
+		{Name: "a", Condition: Condition{Field: "tx_count", Op: OpGreater}},
+		{Name: "b"},
+	}}
+	err := rs.Validate(nil)
+	for _, want := range []string{"invalid operator", "duplicate name", "no conditions"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestRuleSetValidateDefaultSeverity(t *testing.T) {
+	rs := &RuleSet{Rules: []Rule{
+		{Name: "a", Condition: Condition{Field: "tx_count", Op: OpGreater}},
+		{Name: "b", Condition: Condition{Field: "tx_count", Op: OpGreater}, Severity: " Critical "},
+	}}
+	if err := rs.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Rules[0].Severity != DefaultSeverity || rs.Rules[1].Severity != SeverityCritical {
+		t.Errorf("severities = %s, %s; want %s, %s", rs.Rules[0].Severity, rs.Rules[1].Severity, DefaultSeverity, SeverityCritical)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	yml := write("rules.yml", "rules:\n  - {name: busy, field: tx_count, op: '>', threshold: 1}\n")
+	if rs, err := LoadRules(yml); err != nil || len(rs.Rules) != 1 {
+		t.Errorf("LoadRules(.yml) = %v, %v", rs, err)
+	}
+
+	bad := write("rules.json", `{"rules": [{"name": "busy", "feild": "tx_count"}]}`)
+	if _, err := LoadRules(bad); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("LoadRules() error = %v, want error naming %s", err, bad)
+	}
+
+	if _, err := LoadRules(write("rules.txt", "")); err == nil || !strings.Contains(err.Error(), "unsupported rules file format") {
+		t.Errorf("LoadRules(.txt) error = %v", err)
+	}
+}
+
+func TestRuleFormat(t *testing.T) {
+	values := Values{"number": 100, "tx_count": 250, "gas_usage_percent": 97.256}
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{
+			rule: Rule{Name: "busy", Condition: Condition{Field: "tx_count", Op: OpGreater, Threshold: 200}},
+			want: "busy: tx_count > 200 (250)",
+		},
+		{
+			rule: Rule{
+				Name:      "busy",
+				Condition: Condition{Field: "gas_usage_percent", Op: OpGreater, Threshold: 95},
+				Message:   "{name} #{number}: {value}% > {threshold}%, {tx_count} txs",
+			},
+			want: "busy #100: 97.26% > 95%, 250 txs",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.format(values, tt.rule.conditions()[0]); got != tt.want {
+			t.Errorf("format() = %q, want %q", got, tt.want)
+		}
+	}
+}
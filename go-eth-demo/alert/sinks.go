@@ -0,0 +1,254 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/local/go-eth-demo/utils"
+)
+
+// WebhookNotifier 以 JSON POST 请求发送告警
+// 2xx 响应视为成功，429 和 5xx 可重试并遵守响应的 Retry-After，其他状态码为不可重试的错误
+type WebhookNotifier struct {
+	URL     string
+	Header  http.Header     // 附加请求头，如认证令牌
+	Client  *http.Client    // nil 时使用 10 秒超时的客户端
+	Payload func(Alert) any // 请求体，nil 时发送 Alert 本身
+}
+
+// NewWebhookNotifier 创建通用 Webhook 渠道，请求体为 Alert 的 JSON
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url}
+}
+
+// NewSlackNotifier 创建 Slack Incoming Webhook 渠道，请求体为 {"text": "..."}
+func NewSlackNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Payload: func(a Alert) any {
+		return map[string]string{"text": chatText(a)}
+	}}
+}
+
+// NewDiscordNotifier 创建 Discord Webhook 渠道，请求体为 {"content": "..."}
+func NewDiscordNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Payload: func(a Alert) any {
+		return map[string]string{"content": chatText(a)}
+	}}
+}
+
+// chatText 聊天消息正文: 级别和规则名加粗，下一行为告警消息
+func chatText(a Alert) string {
+	return fmt.Sprintf("*[%s] %s*\n%s", strings.ToUpper(string(a.Severity)), a.Rule, a.Message)
+}
+
+var defaultWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notify 实现 Notifier 接口
+func (w *WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	var payload any = a
+	if w.Payload != nil {
+		payload = w.Payload(a)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to encode webhook payload: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid webhook request: %w", err))
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		if delay, ok := utils.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return RetryAfter(err, delay)
+		}
+		return err
+	}
+	return Permanent(err)
+}
+
+// EmailNotifier 通过 SMTP 发送告警邮件
+// 服务器支持 STARTTLS 时自动启用；5xx 响应为不可重试的错误
+type EmailNotifier struct {
+	Addr string    // SMTP 服务器地址 host:port
+	Auth smtp.Auth // nil 时不认证
+	From string
+	To   []string
+}
+
+// NewEmailNotifier 创建邮件渠道，username 为空时不认证，否则使用 PLAIN 认证
+func NewEmailNotifier(addr, username, password, from string, to []string) *EmailNotifier {
+	n := &EmailNotifier{Addr: addr, From: from, To: to}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		n.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return n
+}
+
+// Notify 实现 Notifier 接口
+func (e *EmailNotifier) Notify(ctx context.Context, a Alert) error {
+	err := e.send(ctx, e.message(a))
+
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// send 与 smtp.SendMail 相同的流程，连接和会话受 ctx 的超时控制
+func (e *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid SMTP address %q: %w", e.Addr, err))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if e.Auth != nil {
+		if err := c.Auth(e.Auth); err != nil {
+			return fmt.Errorf("SMTP auth failed: %w", err)
+		}
+	}
+	if err := c.Mail(e.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	return c.Quit()
+}
+
+// message 生成纯文本邮件，主题为 "[级别] 规则名"，正文为告警消息和字段值
+func (e *EmailNotifier) message(a Alert) []byte {
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(string(a.Severity)), a.Rule)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+
+	fmt.Fprintf(&buf, "%s\r\n\r\n", a.Message)
+	fmt.Fprintf(&buf, "time: %s\r\n", a.Time.Format(time.RFC3339))
+	if a.Block > 0 {
+		fmt.Fprintf(&buf, "block: %d\r\n", a.Block)
+	}
+	for _, name := range a.Values.Names() {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, formatValue(a.Values[name]))
+	}
+	keys := make([]string, 0, len(a.Labels))
+	for key := range a.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, a.Labels[key])
+	}
+	return buf.Bytes()
+}
+
+// FileNotifier 以每行一个 JSON 对象的格式把告警追加到文件
+// 每次写入时打开文件，日志轮转后自动写入新文件
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileNotifier 创建文件渠道，文件不存在时自动创建
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Notify 实现 Notifier 接口
+func (f *FileNotifier) Notify(ctx context.Context, a Alert) error {
+	line, err := json.Marshal(a)
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to encode alert: %w", err))
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open alert log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write alert log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/spf13/cobra"
+)
+
+// erc20ABI 查询代币信息所需的 ERC20 方法
+const erc20ABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+	{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}
+]`
+
+func newBalanceCommand() *cobra.Command {
+	var blockNumber int64
+
+	cmd := &cobra.Command{
+		Use:   "balance [地址]",
+		Short: "查询 ETH 余额，默认查询配置中签名者的地址",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			address, err := addressArg(client, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			var block *big.Int
+			if blockNumber >= 0 {
+				block = big.NewInt(blockNumber)
+			}
+
+			balance, err := client.BalanceAt(ctx, address, block)
+			if err != nil {
+				return fmt.Errorf("failed to get balance: %w", err)
+			}
+
+			return render(cmd, &output.Balance{Address: address, Block: block, Wei: balance})
+		},
+	}
+
+	cmd.Flags().Int64Var(&blockNumber, "block", -1, "查询指定区块的余额，默认最新区块")
+	return cmd
+}
+
+func newTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "ERC20 代币操作",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "balance <代币合约> [地址]",
+		Short: "查询 ERC20 代币余额，默认查询配置中签名者的地址",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			address, err := addressArg(client, args[1:])
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+			if err != nil {
+				return err
+			}
+
+			r := &output.TokenBalance{Token: token, Address: address}
+			if err := callContract(ctx, client, token, &parsed, "balanceOf", &r.Raw, address); err != nil {
+				return err
+			}
+			if err := callContract(ctx, client, token, &parsed, "decimals", &r.Decimals); err != nil {
+				return err
+			}
+			if err := callContract(ctx, client, token, &parsed, "symbol", &r.Symbol); err != nil {
+				return err
+			}
+
+			return render(cmd, r)
+		},
+	})
+	return cmd
+}
+
+// callContract 调用只读合约方法并将唯一的返回值写入 out
+func callContract(ctx context.Context, client *utils.EthClient, contract common.Address, contractABI *abi.ABI,
+	method string, out interface{}, args ...interface{}) error {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, utils.ExplainCallError(err, contractABI))
+	}
+
+	values, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", method, err)
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("%s returned %d values, expected 1", method, len(values))
+	}
+	return contractABI.Methods[method].Outputs.Copy(out, values)
+}
+
+// addressArg 返回参数中的地址，未提供时使用配置中签名者的地址
+func addressArg(client *utils.EthClient, args []string) (common.Address, error) {
+	if len(args) > 0 {
+		return parseAddress(args[0])
+	}
+
+	signer, err := utils.NewSignerFromConfig(client.GetConfig())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("no address given and no signer configured: %w", err)
+	}
+	return signer.Address(), nil
+}
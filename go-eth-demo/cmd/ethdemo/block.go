@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/output"
+	"github.com/spf13/cobra"
+)
+
+func newBlockCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "block [latest|区块号|区块哈希]",
+		Short: "查询区块，默认查询最新区块",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := "latest"
+			if len(args) > 0 {
+				id = args[0]
+			}
+
+			var (
+				number *big.Int
+				hash   *common.Hash
+			)
+			switch {
+			case id == "latest":
+			case strings.HasPrefix(id, "0x"):
+				h, err := parseHash(id)
+				if err != nil {
+					return err
+				}
+				hash = &h
+			default:
+				n, err := strconv.ParseUint(id, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid block identifier %q: use latest, a block number or a block hash", id)
+				}
+				number = new(big.Int).SetUint64(n)
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			var block *types.Block
+			if hash != nil {
+				block, err = client.BlockByHash(ctx, *hash)
+			} else {
+				block, err = client.BlockByNumber(ctx, number)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get block %s: %w", id, err)
+			}
+
+			return render(cmd, output.NewBlock(block))
+		},
+	}
+}
+
+func newTxCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tx <交易哈希>",
+		Short: "查询交易详情",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash, err := parseHash(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			tx, pending, err := client.TransactionByHash(ctx, hash)
+			if err != nil {
+				return fmt.Errorf("failed to get transaction %s: %w", hash.Hex(), err)
+			}
+
+			return render(cmd, output.NewTransaction(tx, nil, pending))
+		},
+	}
+}
+
+func newReceiptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "receipt <交易哈希>",
+		Short: "查询交易收据",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash, err := parseHash(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			receipt, err := client.TransactionReceipt(ctx, hash)
+			if err != nil {
+				return fmt.Errorf("failed to get receipt %s: %w", hash.Hex(), err)
+			}
+
+			return render(cmd, output.NewReceipt(receipt))
+		},
+	}
+}
+
+// parseHash 解析 32 字节十六进制哈希
+func parseHash(value string) (common.Hash, error) {
+	raw, err := hexutil.Decode(value)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid hash %q: %w", value, err)
+	}
+	if len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid hash %q: expected %d bytes, got %d", value, common.HashLength, len(raw))
+	}
+	return common.BytesToHash(raw), nil
+}
+
+// parseAddress 解析十六进制地址
+func parseAddress(value string) (common.Address, error) {
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("invalid address %q", value)
+	}
+	return common.HexToAddress(value), nil
+}
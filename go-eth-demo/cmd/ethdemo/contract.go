@@ -0,0 +1,506 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/spf13/cobra"
+)
+
+// contractArtifact scripts/compile_contracts.js 生成的编译输出
+type contractArtifact struct {
+	ContractName string          `json:"contractName"`
+	ABI          json.RawMessage `json:"abi"`
+	Bytecode     string          `json:"bytecode"`
+}
+
+// loadABI 读取 ABI，文件可以是编译输出 (含 abi 字段) 或纯 ABI 数组
+func loadABI(path string) (*abi.ABI, *contractArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	abiJSON := data
+	var artifact contractArtifact
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if len(artifact.ABI) == 0 {
+			return nil, nil, fmt.Errorf("%s has no abi field", path)
+		}
+		abiJSON = artifact.ABI
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse ABI in %s: %w", path, err)
+	}
+	return &parsed, &artifact, nil
+}
+
+// deployRecord 合约部署结果
+type deployRecord struct {
+	transferRecord
+	Contract string          // 编译输出中的合约名
+	Address  *common.Address // 部署成功前为 nil
+}
+
+// Fields 实现 output.Record
+func (r *deployRecord) Fields() []output.Field {
+	fields := []output.Field{
+		{Key: "contract", Label: "合约", Value: nilIfEmpty(r.Contract)},
+		{Key: "contract_address", Label: "合约地址", Value: r.Address},
+	}
+	for _, f := range r.transferRecord.Fields() {
+		// 部署交易没有接收方
+		if f.Key != "to" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func newDeployCommand() *cobra.Command {
+	var (
+		artifactPath  string
+		binPath       string
+		value         string
+		gasLimit      uint64
+		confirmations uint64
+		waitTimeout   time.Duration
+		yes           bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deploy [构造函数参数...]",
+		Short: "部署合约，默认使用 build/SimpleStorage.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contractABI, artifact, err := loadABI(artifactPath)
+			if err != nil {
+				return err
+			}
+
+			bytecodeHex := artifact.Bytecode
+			if binPath != "" {
+				data, err := os.ReadFile(binPath)
+				if err != nil {
+					return fmt.Errorf("failed to read bytecode: %w", err)
+				}
+				bytecodeHex = strings.TrimSpace(string(data))
+			}
+			if bytecodeHex == "" {
+				return fmt.Errorf("no bytecode: use an artifact with a bytecode field or --bin")
+			}
+			bytecode, err := hexutil.Decode(ensureHexPrefix(bytecodeHex))
+			if err != nil {
+				return fmt.Errorf("invalid bytecode: %w", err)
+			}
+
+			constructorArgs, err := convertArgs(contractABI.Constructor.Inputs, args)
+			if err != nil {
+				return fmt.Errorf("invalid constructor arguments: %w", err)
+			}
+			input, err := contractABI.Pack("", constructorArgs...)
+			if err != nil {
+				return fmt.Errorf("failed to pack constructor arguments: %w", err)
+			}
+			data := append(bytecode, input...)
+
+			amount, err := utils.ParseEther(value)
+			if err != nil {
+				return fmt.Errorf("invalid --value: %w", err)
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			signer, err := loadSigner(client)
+			if err != nil {
+				return err
+			}
+
+			if gasLimit == 0 {
+				ctx, cancel := commandContext(cmd)
+				gasLimit, err = estimateGas(ctx, client, contractABI, signer.Address(), nil, amount, data)
+				cancel()
+				if err != nil {
+					return err
+				}
+			}
+
+			signedTx, err := sendTransaction(cmd, client, signer, nil, amount, gasLimit, data, yes)
+			if err != nil {
+				return err
+			}
+
+			result, err := waitForTransaction(cmd, client, signedTx, signer.Address(), confirmations, waitTimeout)
+			r := &deployRecord{
+				transferRecord: transferRecord{
+					TxHash: signedTx.Hash(),
+					From:   signer.Address(),
+					Value:  amount,
+					Nonce:  signedTx.Nonce(),
+					Status: utils.TxStatusPending.String(),
+				},
+				Contract: artifact.ContractName,
+			}
+			r.applyResult(result)
+			// 部署回滚时收据中的合约地址没有代码，不输出
+			if result != nil && result.Receipt != nil && result.Receipt.Status == types.ReceiptStatusSuccessful {
+				r.Address = &result.Receipt.ContractAddress
+			}
+			if renderErr := render(cmd, r); renderErr != nil {
+				return renderErr
+			}
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&artifactPath, "artifact", "build/SimpleStorage.json", "编译输出或 ABI 文件")
+	flags.StringVar(&binPath, "bin", "", "字节码文件，覆盖编译输出中的 bytecode")
+	flags.StringVar(&value, "value", "0", "随部署发送的 ETH")
+	flags.Uint64Var(&gasLimit, "gas-limit", 0, "Gas 限制，0 表示自动估算")
+	flags.Uint64Var(&confirmations, "confirmations", 1, "等待的确认数")
+	flags.DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "等待确认的超时时间")
+	flags.BoolVarP(&yes, "yes", "y", false, "不确认直接部署")
+	return cmd
+}
+
+// callRecord 合约只读调用的结果
+type callRecord struct {
+	Contract common.Address
+	Method   string
+	Outputs  []outputValue
+}
+
+// outputValue 合约方法的一个返回值
+type outputValue struct {
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Fields 实现 output.Record，outputs 为返回值数组
+func (r *callRecord) Fields() []output.Field {
+	fields := []output.Field{
+		{Key: "contract", Label: "合约", Value: r.Contract},
+		{Key: "method", Label: "方法", Value: r.Method},
+		{Key: "outputs", Value: r.Outputs},
+	}
+	for i, out := range r.Outputs {
+		name := out.Name
+		if name == "" {
+			name = fmt.Sprintf("返回值 %d", i)
+		}
+		fields = append(fields, output.Field{Label: fmt.Sprintf("%s (%s)", name, out.Type), Text: out.Value})
+	}
+	return fields
+}
+
+func newContractCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contract",
+		Short: "与已部署的合约交互",
+	}
+
+	var (
+		address       string
+		abiPath       string
+		send          bool
+		value         string
+		gasLimit      uint64
+		confirmations uint64
+		waitTimeout   time.Duration
+		yes           bool
+	)
+
+	call := &cobra.Command{
+		Use:   "call <方法> [参数...]",
+		Short: "调用合约方法，默认只读调用，--send 时发送交易",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contract, err := parseAddress(address)
+			if err != nil {
+				return err
+			}
+			contractABI, _, err := loadABI(abiPath)
+			if err != nil {
+				return err
+			}
+
+			method, ok := contractABI.Methods[args[0]]
+			if !ok {
+				return fmt.Errorf("method %q not found in %s", args[0], abiPath)
+			}
+			methodArgs, err := convertArgs(method.Inputs, args[1:])
+			if err != nil {
+				return fmt.Errorf("invalid arguments for %s: %w", method.Sig, err)
+			}
+			data, err := contractABI.Pack(method.Name, methodArgs...)
+			if err != nil {
+				return fmt.Errorf("failed to pack %s: %w", method.Sig, err)
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			if !send {
+				result, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+				if err != nil {
+					return fmt.Errorf("failed to call %s: %w", method.Sig, utils.ExplainCallError(err, contractABI))
+				}
+				values, err := method.Outputs.Unpack(result)
+				if err != nil {
+					return fmt.Errorf("failed to unpack %s: %w", method.Sig, err)
+				}
+
+				r := &callRecord{Contract: contract, Method: method.Sig, Outputs: []outputValue{}}
+				for i, v := range values {
+					r.Outputs = append(r.Outputs, outputValue{
+						Name:  method.Outputs[i].Name,
+						Type:  method.Outputs[i].Type.String(),
+						Value: formatValue(v),
+					})
+				}
+				return render(cmd, r)
+			}
+
+			amount, err := utils.ParseEther(value)
+			if err != nil {
+				return fmt.Errorf("invalid --value: %w", err)
+			}
+
+			signer, err := loadSigner(client)
+			if err != nil {
+				return err
+			}
+
+			if gasLimit == 0 {
+				if gasLimit, err = estimateGas(ctx, client, contractABI, signer.Address(), &contract, amount, data); err != nil {
+					return err
+				}
+			}
+
+			signedTx, err := sendTransaction(cmd, client, signer, &contract, amount, gasLimit, data, yes)
+			if err != nil {
+				return err
+			}
+
+			r := &transferRecord{
+				TxHash: signedTx.Hash(),
+				From:   signer.Address(),
+				To:     contract,
+				Value:  amount,
+				Nonce:  signedTx.Nonce(),
+				Status: utils.TxStatusPending.String(),
+			}
+			result, err := waitForTransaction(cmd, client, signedTx, signer.Address(), confirmations, waitTimeout)
+			r.applyResult(result)
+			if renderErr := render(cmd, r); renderErr != nil {
+				return renderErr
+			}
+			return err
+		},
+	}
+
+	flags := call.Flags()
+	flags.StringVar(&address, "address", "", "合约地址")
+	flags.StringVar(&abiPath, "abi", "build/SimpleStorage.json", "编译输出或 ABI 文件")
+	flags.BoolVar(&send, "send", false, "发送交易而不是只读调用")
+	flags.StringVar(&value, "value", "0", "随交易发送的 ETH，仅 --send 时有效")
+	flags.Uint64Var(&gasLimit, "gas-limit", 0, "Gas 限制，0 表示自动估算，仅 --send 时有效")
+	flags.Uint64Var(&confirmations, "confirmations", 1, "等待的确认数，仅 --send 时有效")
+	flags.DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "等待确认的超时时间，仅 --send 时有效")
+	flags.BoolVarP(&yes, "yes", "y", false, "不确认直接发送，仅 --send 时有效")
+	_ = call.MarkFlagRequired("address")
+
+	cmd.AddCommand(call)
+	return cmd
+}
+
+// estimateGas 估算交易所需的 Gas 并按配置预留余量，估算失败时解码回滚原因
+func estimateGas(ctx context.Context, client *utils.EthClient, contractABI *abi.ABI,
+	from common.Address, to *common.Address, value *big.Int, data []byte) (uint64, error) {
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: to, Value: value, Data: data})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %w", utils.ExplainCallError(err, contractABI))
+	}
+	return utils.ApplyGasMargin(gas, client.GasMargin()), nil
+}
+
+// convertArgs 按 ABI 参数类型转换命令行参数
+func convertArgs(inputs abi.Arguments, args []string) ([]interface{}, error) {
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, input := range inputs {
+		v, err := convertArg(input.Type, args[i])
+		if err != nil {
+			name := input.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("argument %s (%s): %w", name, input.Type, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// convertArg 将字符串转换为 ABI 编码所需的 Go 类型
+// 数组参数使用 [a,b,c] 格式，整数支持十进制和 0x 十六进制
+func convertArg(typ abi.Type, arg string) (interface{}, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		return parseAddress(arg)
+
+	case abi.BoolTy:
+		return strconv.ParseBool(arg)
+
+	case abi.StringTy:
+		return arg, nil
+
+	case abi.BytesTy:
+		return hexutil.Decode(arg)
+
+	case abi.FixedBytesTy:
+		data, err := hexutil.Decode(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != typ.Size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", typ.Size, len(data))
+		}
+		v := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(data))
+		return v.Interface(), nil
+
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(arg, 0)
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", arg)
+		}
+		if err := checkIntRange(typ, n); err != nil {
+			return nil, fmt.Errorf("%q %w", arg, err)
+		}
+		// 64 位及以下的整数在 go-ethereum 中使用原生类型
+		goType := typ.GetType()
+		if goType.Kind() == reflect.Ptr {
+			return n, nil
+		}
+		if typ.T == abi.IntTy {
+			return reflect.ValueOf(n.Int64()).Convert(goType).Interface(), nil
+		}
+		return reflect.ValueOf(n.Uint64()).Convert(goType).Interface(), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		inner := strings.TrimSpace(arg)
+		if !strings.HasPrefix(inner, "[") || !strings.HasSuffix(inner, "]") {
+			return nil, fmt.Errorf("expected [a,b,...], got %q", arg)
+		}
+		inner = strings.TrimSpace(inner[1 : len(inner)-1])
+
+		var items []string
+		if inner != "" {
+			items = strings.Split(inner, ",")
+		}
+		if typ.T == abi.ArrayTy && len(items) != typ.Size {
+			return nil, fmt.Errorf("expected %d elements, got %d", typ.Size, len(items))
+		}
+
+		var v reflect.Value
+		if typ.T == abi.ArrayTy {
+			v = reflect.New(typ.GetType()).Elem()
+		} else {
+			v = reflect.MakeSlice(typ.GetType(), len(items), len(items))
+		}
+		for i, item := range items {
+			elem, err := convertArg(*typ.Elem, strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			v.Index(i).Set(reflect.ValueOf(elem))
+		}
+		return v.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported argument type %s", typ)
+}
+
+// checkIntRange 检查整数是否在 ABI 类型的取值范围内
+// uintN 为 [0, 2^N-1]，intN 为 [-2^(N-1), 2^(N-1)-1]，超出范围时打包会截断为错误的值
+func checkIntRange(typ abi.Type, n *big.Int) error {
+	if typ.T == abi.UintTy {
+		if n.Sign() < 0 {
+			return fmt.Errorf("is negative")
+		}
+		if n.BitLen() > typ.Size {
+			return fmt.Errorf("overflows %s", typ)
+		}
+		return nil
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1)) // 2^(N-1)
+	minValue := new(big.Int).Neg(limit)
+	maxValue := new(big.Int).Sub(limit, big.NewInt(1))
+	if n.Cmp(minValue) < 0 || n.Cmp(maxValue) > 0 {
+		return fmt.Errorf("out of range for %s [%s, %s]", typ, minValue, maxValue)
+	}
+	return nil
+}
+
+// formatValue 将 ABI 解码结果格式化为字符串，字节类型输出十六进制
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case []byte:
+		return hexutil.Encode(value)
+	case common.Address:
+		return value.Hex()
+	case *big.Int:
+		return value.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		data := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(data), rv)
+		return hexutil.Encode(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// ensureHexPrefix 为十六进制字符串补上 0x 前缀
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
+	}
+	return "0x" + s
+}
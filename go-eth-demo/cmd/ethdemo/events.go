@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/local/go-eth-demo/output"
+	"github.com/spf13/cobra"
+)
+
+func newEventsCommand() *cobra.Command {
+	var (
+		addresses []string
+		abiPath   string
+		eventName string
+		fromBlock int64
+		toBlock   int64
+		lastN     uint64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "查询合约事件日志",
+		Long: `查询合约事件日志。
+
+--event 可以是 ABI 中的事件名 (配合 --abi 解码参数)，
+也可以是事件签名，如 "Transfer(address,address,uint256)"。`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ethereum.FilterQuery{}
+			for _, a := range addresses {
+				address, err := parseAddress(a)
+				if err != nil {
+					return err
+				}
+				query.Addresses = append(query.Addresses, address)
+			}
+
+			var contractABI *abi.ABI
+			if abiPath != "" {
+				parsed, _, err := loadABI(abiPath)
+				if err != nil {
+					return err
+				}
+				contractABI = parsed
+			}
+
+			if eventName != "" {
+				topic, err := eventTopic(contractABI, eventName)
+				if err != nil {
+					return err
+				}
+				query.Topics = [][]common.Hash{{topic}}
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			if toBlock >= 0 {
+				query.ToBlock = big.NewInt(toBlock)
+			}
+			switch {
+			case fromBlock >= 0:
+				query.FromBlock = big.NewInt(fromBlock)
+			case lastN > 0:
+				latest, err := client.BlockNumber(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get latest block number: %w", err)
+				}
+				if query.ToBlock != nil {
+					latest = query.ToBlock.Uint64()
+				}
+				start := uint64(0)
+				if latest >= lastN {
+					start = latest - lastN + 1
+				}
+				query.FromBlock = new(big.Int).SetUint64(start)
+			}
+
+			logs, err := client.FilterLogs(ctx, query)
+			if err != nil {
+				return fmt.Errorf("failed to filter logs: %w", err)
+			}
+
+			records := make([]output.Record, 0, len(logs))
+			for i := range logs {
+				records = append(records, decodeLog(&logs[i], contractABI))
+			}
+			if len(records) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "未找到匹配的事件")
+			}
+			return renderList(cmd, records)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&addresses, "address", nil, "合约地址，可重复指定")
+	flags.StringVar(&abiPath, "abi", "", "编译输出或 ABI 文件，用于解码事件参数")
+	flags.StringVar(&eventName, "event", "", "事件名或事件签名")
+	flags.Int64Var(&fromBlock, "from", -1, "起始区块")
+	flags.Int64Var(&toBlock, "to", -1, "结束区块，默认最新区块")
+	flags.Uint64Var(&lastN, "last", 100, "未指定 --from 时查询最近的区块数")
+	return cmd
+}
+
+// eventTopic 返回事件的 topic0，name 为签名时直接计算哈希，否则在 ABI 中查找
+func eventTopic(contractABI *abi.ABI, name string) (common.Hash, error) {
+	if strings.Contains(name, "(") {
+		return crypto.Keccak256Hash([]byte(strings.ReplaceAll(name, " ", ""))), nil
+	}
+	if contractABI == nil {
+		return common.Hash{}, fmt.Errorf("event %q needs --abi, or use a signature such as %s(address,uint256)", name, name)
+	}
+	event, ok := contractABI.Events[name]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("event %q not found in ABI", name)
+	}
+	return event.ID, nil
+}
+
+// decodeLog 转换日志，ABI 中能找到事件时解码 indexed 参数和数据
+func decodeLog(log *types.Log, contractABI *abi.ABI) *output.Log {
+	r := output.NewLog(log)
+	if contractABI == nil || len(log.Topics) == 0 {
+		return r
+	}
+	event, err := contractABI.EventByID(log.Topics[0])
+	if err != nil {
+		return r
+	}
+	r.Event = event.Sig
+
+	args := make(map[string]interface{})
+	if err := contractABI.UnpackIntoMap(args, event.Name, log.Data); err != nil {
+		return r
+	}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+		return r
+	}
+
+	r.Args = make(map[string]string, len(args))
+	for name, value := range args {
+		r.Args[name] = formatValue(value)
+	}
+	return r
+}
@@ -0,0 +1,140 @@
+// ethdemo 以太坊命令行工具，整合 examples 目录下各示例的功能
+//
+// 用法示例：
+//
+//	ethdemo block latest
+//	ethdemo balance 0x742d35Cc6634C0532925a3b8D0C9e3e0C8b0e4c2
+//	ethdemo transfer --to 0x... --amount 0.001
+//	ethdemo --network local wallet info
+//	ethdemo -o json tx 0x...
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/spf13/cobra"
+)
+
+// globalOptions 所有子命令共用的参数
+type globalOptions struct {
+	configFile string
+	network    string
+	rpcURL     string
+	timeout    time.Duration
+	output     string
+	format     output.Format
+}
+
+var globals globalOptions
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand 创建根命令并注册所有子命令
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "ethdemo",
+		Short:         "以太坊命令行工具：查询区块、交易、余额，转账，管理钱包，订阅事件和部署合约",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.ParseFormat(globals.output)
+			if err != nil {
+				return err
+			}
+			globals.format = format
+			return nil
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&globals.configFile, "config", "", "配置文件路径 (YAML 或 TOML)，默认读取 CONFIG_FILE 或当前目录下的 config.yaml")
+	flags.StringVar(&globals.network, "network", "", "网络名称 (sepolia / mainnet / local)，覆盖 NETWORK_NAME")
+	flags.StringVar(&globals.rpcURL, "rpc", "", "RPC 节点地址，覆盖配置中的 ETHEREUM_RPC_URL")
+	flags.DurationVar(&globals.timeout, "timeout", 30*time.Second, "单个命令的超时时间，不含等待用户确认和交易上链的时间")
+	flags.StringVarP(&globals.output, "output", "o", "text", "输出格式: "+strings.Join(output.Formats(), ", "))
+
+	root.AddCommand(
+		newBlockCommand(),
+		newTxCommand(),
+		newReceiptCommand(),
+		newBalanceCommand(),
+		newTokenCommand(),
+		newTransferCommand(),
+		newWalletCommand(),
+		newSubscribeCommand(),
+		newEventsCommand(),
+		newDeployCommand(),
+		newContractCommand(),
+	)
+	return root
+}
+
+// loadConfig 加载配置，命令行参数优先于配置文件和环境变量
+func loadConfig() (*config.Config, error) {
+	if globals.network != "" {
+		if err := os.Setenv("NETWORK_NAME", globals.network); err != nil {
+			return nil, err
+		}
+	}
+
+	var cfg *config.Config
+	var err error
+	if globals.configFile != "" {
+		cfg, err = config.LoadConfigFile(globals.configFile)
+	} else {
+		cfg, err = config.LoadConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if globals.rpcURL != "" {
+		cfg.EthereumRPCURL = globals.rpcURL
+		cfg.RPCURLs = nil
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// connect 加载配置并连接节点，连接时会校验链 ID
+func connect() (*utils.EthClient, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := utils.NewEthClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client.SetTimeout(globals.timeout)
+	return client, nil
+}
+
+// loadSigner 加载配置中的签名者，只允许为已连接节点的链签名
+func loadSigner(client *utils.EthClient) (utils.Signer, error) {
+	signer, err := utils.NewSignerFromConfig(client.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	return client.GuardSigner(signer), nil
+}
+
+// commandContext 返回带全局超时的上下文
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), globals.timeout)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/local/go-eth-demo/output"
+	"github.com/spf13/cobra"
+)
+
+// render 按 --output 指定的格式输出单条结果
+func render(cmd *cobra.Command, record output.Record) error {
+	return output.Render(cmd.OutOrStdout(), globals.format, record)
+}
+
+// renderList 按 --output 指定的格式输出多条结果，JSON 格式始终为数组
+func renderList(cmd *cobra.Command, records []output.Record) error {
+	return output.RenderList(cmd.OutOrStdout(), globals.format, records)
+}
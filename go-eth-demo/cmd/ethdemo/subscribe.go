@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/local/go-eth-demo/follower"
+	"github.com/local/go-eth-demo/output"
+	"github.com/spf13/cobra"
+)
+
+func newSubscribeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "subscribe",
+		Short: "订阅链上数据",
+	}
+
+	var (
+		count    int
+		interval time.Duration
+		poll     bool
+	)
+
+	blocks := &cobra.Command{
+		Use:   "blocks",
+		Short: "订阅新区块，配置了 ETHEREUM_WS_URL 时使用 WebSocket (断开时自动重连)，否则轮询",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			// 订阅持续运行，直到收到中断信号或达到 --count
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// WebSocket 断开时自动重新订阅，期间轮询并补齐错过的区块
+			opts := follower.DefaultOptions()
+			opts.PollInterval = interval
+			if !poll {
+				opts.WSURL = client.GetConfig().WSURL
+			}
+			opts.OnEvent = func(event follower.Event) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "[follower] %s\n", event)
+			}
+			heads := follower.NewHeadFollower(client, opts)
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go heads.Run(ctx)
+
+			// 每个区块立即输出，JSON 格式下每行一个对象
+			// 通道在用户中断时关闭，属于正常退出
+			stream := output.NewStream(cmd.OutOrStdout(), globals.format)
+			received := 0
+			for header := range heads.Headers() {
+				if err := stream.Write(output.NewHeader(header)); err != nil {
+					return err
+				}
+				received++
+				if count > 0 && received >= count {
+					break
+				}
+			}
+			return nil
+		},
+	}
+
+	blocks.Flags().IntVar(&count, "count", 0, "收到指定数量的区块后退出，0 表示一直运行")
+	blocks.Flags().DurationVar(&interval, "interval", 12*time.Second, "轮询模式下的查询间隔")
+	blocks.Flags().BoolVar(&poll, "poll", false, "即使配置了 WebSocket 也使用轮询")
+
+	cmd.AddCommand(blocks)
+	return cmd
+}
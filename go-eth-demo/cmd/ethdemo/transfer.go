@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/spf13/cobra"
+)
+
+// transferRecord 转账或合约交易的结果
+type transferRecord struct {
+	TxHash        common.Hash
+	From          common.Address
+	To            common.Address
+	Value         *big.Int
+	Nonce         uint64
+	Status        string
+	BlockNumber   *uint64 // 未等待上链时为 nil
+	GasUsed       *uint64
+	Confirmations uint64
+}
+
+// Fields 实现 output.Record
+func (r *transferRecord) Fields() []output.Field {
+	gasUsed := ""
+	if r.GasUsed != nil {
+		gasUsed = utils.FormatNumber(*r.GasUsed)
+	}
+	return []output.Field{
+		{Key: "transaction_hash", Label: "交易哈希", Value: r.TxHash},
+		{Key: "from", Label: "发送方", Value: r.From},
+		{Key: "to", Label: "接收方", Value: r.To},
+		{Key: "value", Label: "金额", Value: r.Value, Text: utils.WeiToEther(r.Value) + " ETH"},
+		{Key: "nonce", Label: "Nonce", Value: r.Nonce},
+		{Key: "status", Label: "状态", Value: r.Status},
+		{Key: "block_number", Label: "区块号", Value: r.BlockNumber},
+		{Key: "gas_used", Label: "Gas 使用", Value: r.GasUsed, Text: gasUsed},
+		{Key: "confirmations", Label: "确认数", Value: r.Confirmations},
+	}
+}
+
+// applyResult 用等待结果更新状态、区块号和 Gas 使用
+func (r *transferRecord) applyResult(result *utils.TxResult) {
+	if result == nil {
+		return
+	}
+	r.Status = result.Status.String()
+	r.Confirmations = result.Confirmations
+	if result.Receipt != nil {
+		blockNumber := result.Receipt.BlockNumber.Uint64()
+		gasUsed := result.Receipt.GasUsed
+		r.BlockNumber = &blockNumber
+		r.GasUsed = &gasUsed
+	}
+}
+
+func newTransferCommand() *cobra.Command {
+	var (
+		to            string
+		amount        string
+		gasLimit      uint64
+		confirmations uint64
+		noWait        bool
+		waitTimeout   time.Duration
+		yes           bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "transfer --to <地址> --amount <ETH>",
+		Short: "使用配置中的签名者发送 ETH",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			toAddress, err := parseAddress(to)
+			if err != nil {
+				return err
+			}
+			value, err := utils.ParseEther(amount)
+			if err != nil {
+				return fmt.Errorf("invalid --amount: %w", err)
+			}
+
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			signer, err := loadSigner(client)
+			if err != nil {
+				return err
+			}
+
+			signedTx, err := sendTransaction(cmd, client, signer, &toAddress, value, gasLimit, nil, yes)
+			if err != nil {
+				return err
+			}
+
+			r := &transferRecord{
+				TxHash: signedTx.Hash(),
+				From:   signer.Address(),
+				To:     toAddress,
+				Value:  value,
+				Nonce:  signedTx.Nonce(),
+				Status: utils.TxStatusPending.String(),
+			}
+			if noWait {
+				return render(cmd, r)
+			}
+
+			result, err := waitForTransaction(cmd, client, signedTx, signer.Address(), confirmations, waitTimeout)
+			r.applyResult(result)
+			if renderErr := render(cmd, r); renderErr != nil {
+				return renderErr
+			}
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&to, "to", "", "接收方地址")
+	flags.StringVar(&amount, "amount", "", "转账金额 (ETH)，如 0.001")
+	flags.Uint64Var(&gasLimit, "gas-limit", 0, "Gas 限制，0 表示自动估算")
+	flags.Uint64Var(&confirmations, "confirmations", 1, "等待的确认数")
+	flags.BoolVar(&noWait, "no-wait", false, "发送后立即返回，不等待确认")
+	flags.DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "等待确认的超时时间")
+	flags.BoolVarP(&yes, "yes", "y", false, "不确认直接发送")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagRequired("amount")
+	return cmd
+}
+
+// sendTransaction 构建、签名并广播交易，to 为 nil 时创建合约
+// gasLimit 为 0 时按估算值加配置的余量；费用类型由配置的 FEE_MODE 决定
+// 签名前在标准错误输出上打印费用预览，余额不足以支付金额和最高手续费时不签名，yes 为 false 时需要确认
+// 估算和发送分别使用 --timeout 超时，等待用户确认的时间不计入
+func sendTransaction(cmd *cobra.Command, client *utils.EthClient, signer utils.Signer,
+	to *common.Address, value *big.Int, gasLimit uint64, data []byte, yes bool) (*types.Transaction, error) {
+	chainID, err := client.GetChainID()
+	if err != nil {
+		return nil, err
+	}
+
+	estimateCtx, cancelEstimate := commandContext(cmd)
+	cost, err := client.EstimateTxCost(estimateCtx, ethereum.CallMsg{
+		From: signer.Address(), To: to, Value: value, Gas: gasLimit, Data: data,
+	})
+	cancelEstimate()
+	if err != nil {
+		return nil, err
+	}
+	cost.Print(cmd.ErrOrStderr())
+	if err := cost.CheckBalance(); err != nil {
+		return nil, err
+	}
+	if !yes && !confirm(cmd, "确认发送以上交易? (y/N): ") {
+		return nil, errors.New("transaction cancelled")
+	}
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	nonces, err := client.NonceManager()
+	if err != nil {
+		return nil, err
+	}
+	from := signer.Address()
+	nonce, err := nonces.Next(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := cost.NewTx(chainID, nonce, data)
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		nonces.Release(from, nonce)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		nonces.HandleSendError(ctx, from, nonce, err)
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// confirm 在标准错误输出上打印提示并从标准输入读取一行，输入 y 或 yes 时返回 true
+func confirm(cmd *cobra.Command, message string) bool {
+	fmt.Fprint(cmd.ErrOrStderr(), message)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(cmd.ErrOrStderr())
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// waitForTransaction 等待交易达到指定确认数，交易失败、被替换或丢弃时返回错误
+func waitForTransaction(cmd *cobra.Command, client *utils.EthClient, tx *types.Transaction,
+	from common.Address, confirmations uint64, timeout time.Duration) (*utils.TxResult, error) {
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	result, err := utils.WaitForTransaction(ctx, client, tx, utils.WaitOptions{
+		Confirmations: confirmations,
+		From:          from,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to wait for %s: %w", tx.Hash().Hex(), err)
+	}
+	return result, result.Err()
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// walletRecord 新建或导入的钱包
+type walletRecord struct {
+	Address    common.Address
+	Keystore   string // 未加密保存时为空
+	PrivateKey string // 只在未加密保存时输出
+}
+
+// Fields 实现 output.Record
+func (r *walletRecord) Fields() []output.Field {
+	return []output.Field{
+		{Key: "address", Label: "地址", Value: r.Address},
+		{Key: "keystore", Label: "KeyStore 文件", Value: nilIfEmpty(r.Keystore)},
+		{Key: "private_key", Label: "私钥", Value: nilIfEmpty(r.PrivateKey)},
+	}
+}
+
+// walletInfoRecord 账户链上信息
+type walletInfoRecord struct {
+	Address      common.Address
+	Balance      *big.Int
+	Nonce        uint64
+	PendingNonce uint64
+	CodeSize     int
+}
+
+// Fields 实现 output.Record
+func (r *walletInfoRecord) Fields() []output.Field {
+	accountType := "外部账户 (EOA)"
+	if r.CodeSize > 0 {
+		accountType = fmt.Sprintf("合约账户 (代码 %d bytes)", r.CodeSize)
+	}
+	return []output.Field{
+		{Key: "address", Label: "地址", Value: r.Address},
+		{Key: "is_contract", Label: "类型", Value: r.CodeSize > 0, Text: accountType},
+		{Key: "code_size", Value: r.CodeSize},
+		{Key: "balance", Label: "余额", Value: r.Balance, Text: utils.WeiToEther(r.Balance) + " ETH"},
+		{Key: "nonce", Label: "Nonce", Value: r.Nonce},
+		{Key: "pending_nonce", Label: "待处理 Nonce", Value: r.PendingNonce},
+	}
+}
+
+// nilIfEmpty 将空字符串转换为 nil，使其在文本中省略、在 JSON 中输出为 null
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func newWalletCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wallet",
+		Short: "创建、导入和查看钱包",
+	}
+	cmd.AddCommand(newWalletNewCommand(), newWalletImportCommand(), newWalletInfoCommand())
+	return cmd
+}
+
+func newWalletNewCommand() *cobra.Command {
+	var keystoreDir, passwordFile string
+
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "生成新钱包，指定 --keystore 时加密保存，否则输出私钥",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keystoreDir == "" {
+				key, err := crypto.GenerateKey()
+				if err != nil {
+					return fmt.Errorf("failed to generate key: %w", err)
+				}
+				fmt.Fprintln(cmd.ErrOrStderr(), "⚠️  私钥未加密保存，请妥善保管，不要泄露给任何人")
+				return render(cmd, &walletRecord{
+					Address:    crypto.PubkeyToAddress(key.PublicKey),
+					PrivateKey: hexutil.Encode(crypto.FromECDSA(key)),
+				})
+			}
+
+			password, err := readPassword(cmd, passwordFile, true)
+			if err != nil {
+				return err
+			}
+
+			ks := keystore.NewKeyStore(keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+			account, err := ks.NewAccount(password)
+			if err != nil {
+				return fmt.Errorf("failed to create account: %w", err)
+			}
+			return render(cmd, &walletRecord{Address: account.Address, Keystore: account.URL.Path})
+		},
+	}
+
+	cmd.Flags().StringVar(&keystoreDir, "keystore", "", "KeyStore 目录，指定时以加密文件保存私钥")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "包含 KeyStore 密码的文件，默认使用 KEYSTORE_PASSWORD 或交互输入")
+	return cmd
+}
+
+func newWalletImportCommand() *cobra.Command {
+	var keystoreDir, passwordFile, privateKeyFile string
+
+	cmd := &cobra.Command{
+		Use:   "import --keystore <目录>",
+		Short: "将私钥导入加密 KeyStore，私钥从 --private-key-file 或 PRIVATE_KEY 读取",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			privateKeyHex := os.Getenv("PRIVATE_KEY")
+			if privateKeyFile != "" {
+				data, err := os.ReadFile(privateKeyFile)
+				if err != nil {
+					return fmt.Errorf("failed to read private key file: %w", err)
+				}
+				privateKeyHex = string(data)
+			}
+			if strings.TrimSpace(privateKeyHex) == "" {
+				return fmt.Errorf("no private key given: use --private-key-file or PRIVATE_KEY")
+			}
+
+			key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x"))
+			if err != nil {
+				return fmt.Errorf("invalid private key: %w", err)
+			}
+
+			password, err := readPassword(cmd, passwordFile, true)
+			if err != nil {
+				return err
+			}
+
+			ks := keystore.NewKeyStore(keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+			account, err := ks.ImportECDSA(key, password)
+			if err != nil {
+				return fmt.Errorf("failed to import key: %w", err)
+			}
+			return render(cmd, &walletRecord{Address: account.Address, Keystore: account.URL.Path})
+		},
+	}
+
+	cmd.Flags().StringVar(&keystoreDir, "keystore", "", "KeyStore 目录")
+	cmd.Flags().StringVar(&privateKeyFile, "private-key-file", "", "包含十六进制私钥的文件，默认使用 PRIVATE_KEY")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "包含 KeyStore 密码的文件，默认使用 KEYSTORE_PASSWORD 或交互输入")
+	_ = cmd.MarkFlagRequired("keystore")
+	return cmd
+}
+
+func newWalletInfoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info [地址]",
+		Short: "查看账户余额、nonce 和类型，默认查看配置中签名者的地址",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			address, err := addressArg(client, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			r := &walletInfoRecord{Address: address}
+			if r.Balance, err = client.BalanceAt(ctx, address, nil); err != nil {
+				return fmt.Errorf("failed to get balance: %w", err)
+			}
+			if r.Nonce, err = client.NonceAt(ctx, address, nil); err != nil {
+				return fmt.Errorf("failed to get nonce: %w", err)
+			}
+			if r.PendingNonce, err = client.PendingNonceAt(ctx, address); err != nil {
+				return fmt.Errorf("failed to get pending nonce: %w", err)
+			}
+			code, err := client.CodeAt(ctx, address, nil)
+			if err != nil {
+				return fmt.Errorf("failed to get code: %w", err)
+			}
+			r.CodeSize = len(code)
+
+			return render(cmd, r)
+		},
+	}
+}
+
+// readPassword 依次从密码文件、KEYSTORE_PASSWORD 环境变量和终端输入读取密码
+// confirm 为 true 时终端输入需要重复确认
+func readPassword(cmd *cobra.Command, passwordFile string, confirm bool) (string, error) {
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password := os.Getenv("KEYSTORE_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no password given: use --password-file or KEYSTORE_PASSWORD")
+	}
+
+	fmt.Fprint(cmd.ErrOrStderr(), "请输入 KeyStore 密码: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	if len(password) == 0 {
+		return "", fmt.Errorf("password must not be empty")
+	}
+
+	if confirm {
+		fmt.Fprint(cmd.ErrOrStderr(), "请再次输入密码: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		if string(again) != string(password) {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	}
+	return string(password), nil
+}
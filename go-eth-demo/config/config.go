@@ -1,73 +1,272 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/joho/godotenv"
 )
 
 // Config 存储应用程序配置
+//
+// 配置来源的优先级从低到高依次为：
+//  1. 内置网络配置 (sepolia / mainnet / local)
+//  2. 配置文件 (YAML 或 TOML，由 CONFIG_FILE 指定或当前目录下的 config.yaml / config.yml / config.toml)
+//  3. .env 文件
+//  4. 系统环境变量
 type Config struct {
 	// 以太坊网络配置
-	EthereumRPCURL string // RPC 节点地址
-	ChainID        int64  // 链 ID (Sepolia: 11155111)
-	NetworkName    string // 网络名称 (sepolia)
+	EthereumRPCURL string   // RPC 节点地址
+	RPCURLs        []string // 备用 RPC 节点地址 (ETHEREUM_RPC_URLS，逗号分隔)
+	WSURL          string   // WebSocket 节点地址，用于订阅 (ETHEREUM_WS_URL)
+	ChainID        int64    // 链 ID (Sepolia: 11155111)
+	NetworkName    string   // 网络名称 (sepolia)
 
-	// 账户配置 (后续阶段使用)
+	// RPC 请求策略
+	RPCMaxAttempts int64   // 单个请求的最大尝试次数 (RPC_MAX_ATTEMPTS)
+	RPCRateLimit   float64 // 每秒最多请求数，0 表示不限流 (RPC_RATE_LIMIT)
+	RPCRateBurst   int64   // 允许的突发请求数 (RPC_RATE_BURST)
+
+	// 账户配置
 	PrivateKey       string // 私钥 (用于交易签名)
 	KeystorePath     string // KeyStore 文件路径
 	KeystorePassword string // KeyStore 密码
+	ToAddress        string // 默认接收方地址 (用于转账测试)
+
+	// 交易配置
+	FeeMode   string // 交易费用模式: auto / legacy / eip1559
+	GasMargin int64  // 在 Gas 估算值基础上增加的余量百分比 (GAS_MARGIN_PERCENT)
+
+	// 安全配置
+	SkipChainIDCheck bool // 连接时跳过链 ID 校验 (SKIP_CHAIN_ID_CHECK)，仅用于调试
+	AllowMainnet     bool // 允许在主网签名交易 (ALLOW_MAINNET)
+
+	ConfigFile string // 实际加载的配置文件路径，未使用配置文件时为空
+
+	networkChainID int64 // NETWORK_NAME 对应网络配置中的链 ID，用于检查与 CHAIN_ID 是否一致
 }
 
-// LoadConfig 从环境变量加载配置
+// LoadConfig 加载配置，配置文件由 CONFIG_FILE 环境变量指定，未指定时查找当前目录下的默认配置文件
 func LoadConfig() (*Config, error) {
-	// 尝试加载 .env 文件
-	if err := godotenv.Load(); err != nil {
+	if err := loadDotEnv(); err != nil {
+		return nil, err
+	}
+	return load(findConfigFile())
+}
+
+// LoadConfigFile 从指定的配置文件加载配置，.env 和环境变量仍然优先于文件内容
+func LoadConfigFile(path string) (*Config, error) {
+	if err := loadDotEnv(); err != nil {
+		return nil, err
+	}
+	return load(path)
+}
+
+// loadDotEnv 将 .env 文件中的变量加载到环境变量，已存在的环境变量不会被覆盖
+func loadDotEnv() error {
+	if _, err := os.Stat(".env"); err != nil {
 		// .env 文件不存在不是错误，可能使用系统环境变量
-		fmt.Println("Warning: .env file not found, using system environment variables")
+		return nil
+	}
+	if err := godotenv.Load(); err != nil {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+	return nil
+}
+
+// load 按优先级合并内置网络、配置文件和环境变量，path 为空时不使用配置文件
+func load(path string) (*Config, error) {
+	file := &fileConfig{}
+	if path != "" {
+		var err error
+		if file, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// 1. 确定网络，并用配置文件中的同名网络覆盖内置配置
+	networkName := strings.ToLower(firstNonEmpty(os.Getenv("NETWORK_NAME"), file.Network, NetworkSepolia))
+	profile, known := builtinNetworks[networkName]
+	for name, override := range file.Networks {
+		if strings.ToLower(name) == networkName {
+			profile = mergeProfile(profile, override)
+			known = true
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown network %q (built-in: %s; custom networks can be defined under \"networks\" in the config file)",
+			networkName, strings.Join(NetworkNames(), ", "))
 	}
 
 	config := &Config{
-		EthereumRPCURL:   getEnv("ETHEREUM_RPC_URL", ""),
-		ChainID:          getEnvAsInt64("CHAIN_ID", 11155111), // Sepolia 默认
-		NetworkName:      getEnv("NETWORK_NAME", "sepolia"),
-		PrivateKey:       getEnv("PRIVATE_KEY", ""),
-		KeystorePath:     getEnv("KEYSTORE_PATH", ""),
-		KeystorePassword: getEnv("KEYSTORE_PASSWORD", ""),
+		EthereumRPCURL:   profile.RPCURL,
+		RPCURLs:          profile.RPCURLs,
+		WSURL:            profile.WSURL,
+		ChainID:          profile.ChainID,
+		NetworkName:      networkName,
+		RPCMaxAttempts:   3,
+		RPCRateLimit:     0,
+		RPCRateBurst:     1,
+		PrivateKey:       file.Account.PrivateKey,
+		KeystorePath:     file.Account.KeystorePath,
+		KeystorePassword: file.Account.KeystorePassword,
+		ToAddress:        file.Account.ToAddress,
+		FeeMode:          firstNonEmpty(file.FeeMode, "auto"),
+		GasMargin:        20,
+		SkipChainIDCheck: file.SkipChainIDCheck,
+		AllowMainnet:     file.AllowMainnet,
+		ConfigFile:       path,
+		networkChainID:   profile.ChainID,
+	}
+
+	// 2. 配置文件中的 Gas 余量和请求策略
+	if file.GasMargin != nil {
+		config.GasMargin = *file.GasMargin
+	}
+	if file.RPC.MaxAttempts != 0 {
+		config.RPCMaxAttempts = file.RPC.MaxAttempts
+	}
+	if file.RPC.RateLimit != 0 {
+		config.RPCRateLimit = file.RPC.RateLimit
+	}
+	if file.RPC.RateBurst != 0 {
+		config.RPCRateBurst = file.RPC.RateBurst
+	}
+
+	// 3. 环境变量 (包括 .env) 覆盖以上所有来源
+	config.EthereumRPCURL = getEnv("ETHEREUM_RPC_URL", config.EthereumRPCURL)
+	if urls := getEnvAsList("ETHEREUM_RPC_URLS"); len(urls) > 0 {
+		config.RPCURLs = urls
+	}
+	config.WSURL = getEnv("ETHEREUM_WS_URL", config.WSURL)
+	config.PrivateKey = getEnv("PRIVATE_KEY", config.PrivateKey)
+	config.KeystorePath = getEnv("KEYSTORE_PATH", config.KeystorePath)
+	config.KeystorePassword = getEnv("KEYSTORE_PASSWORD", config.KeystorePassword)
+	config.ToAddress = getEnv("TO_ADDRESS", config.ToAddress)
+	config.FeeMode = getEnv("FEE_MODE", config.FeeMode)
+
+	var errs []error
+	var err error
+	if config.ChainID, err = getEnvAsInt64("CHAIN_ID", config.ChainID); err != nil {
+		errs = append(errs, err)
+	}
+	if config.GasMargin, err = getEnvAsInt64("GAS_MARGIN_PERCENT", config.GasMargin); err != nil {
+		errs = append(errs, err)
+	}
+	if config.RPCMaxAttempts, err = getEnvAsInt64("RPC_MAX_ATTEMPTS", config.RPCMaxAttempts); err != nil {
+		errs = append(errs, err)
+	}
+	if config.RPCRateLimit, err = getEnvAsFloat64("RPC_RATE_LIMIT", config.RPCRateLimit); err != nil {
+		errs = append(errs, err)
+	}
+	if config.RPCRateBurst, err = getEnvAsInt64("RPC_RATE_BURST", config.RPCRateBurst); err != nil {
+		errs = append(errs, err)
+	}
+	if config.SkipChainIDCheck, err = getEnvAsBool("SKIP_CHAIN_ID_CHECK", config.SkipChainIDCheck); err != nil {
+		errs = append(errs, err)
+	}
+	if config.AllowMainnet, err = getEnvAsBool("ALLOW_MAINNET", config.AllowMainnet); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
 	// 验证必需的配置
-	if err := config.validate(); err != nil {
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 
 	return config, nil
 }
 
-// validate 验证配置的有效性
-func (c *Config) validate() error {
-	// 1. 检查 RPC URL 是否存在
-	if c.EthereumRPCURL == "" {
-		return fmt.Errorf("ETHEREUM_RPC_URL is required")
-	}
+// Validate 验证配置的有效性，返回所有发现的问题
+func (c *Config) Validate() error {
+	var errs []error
 
-	// 2. 验证 URL 格式
-	if !strings.HasPrefix(c.EthereumRPCURL, "http://") &&
-		!strings.HasPrefix(c.EthereumRPCURL, "https://") &&
-		!strings.HasPrefix(c.EthereumRPCURL, "ws://") &&
-		!strings.HasPrefix(c.EthereumRPCURL, "wss://") {
-		return fmt.Errorf("invalid RPC URL format: %s", c.EthereumRPCURL)
+	// 1. 检查 RPC URL 是否存在并验证格式
+	if len(c.Endpoints()) == 0 {
+		errs = append(errs, fmt.Errorf("ETHEREUM_RPC_URL is required"))
+	}
+	for _, url := range c.Endpoints() {
+		if !hasScheme(url, "http://", "https://", "ws://", "wss://") {
+			errs = append(errs, fmt.Errorf("invalid RPC URL format: %s", url))
+		}
+	}
+	if c.WSURL != "" && !hasScheme(c.WSURL, "ws://", "wss://") {
+		errs = append(errs, fmt.Errorf("invalid WebSocket URL format: %s", c.WSURL))
 	}
 
-	// 3. 验证链 ID
+	// 2. 验证链 ID，并检查 CHAIN_ID 和 RPC 地址与 NETWORK_NAME 指向同一网络
 	if c.ChainID <= 0 {
-		return fmt.Errorf("invalid chain ID: %d", c.ChainID)
+		errs = append(errs, fmt.Errorf("invalid chain ID: %d", c.ChainID))
+	}
+	if c.networkChainID != 0 && c.ChainID != c.networkChainID {
+		errs = append(errs, fmt.Errorf("CHAIN_ID %d does not match network %q (chain ID %d): remove CHAIN_ID or set the matching NETWORK_NAME",
+			c.ChainID, c.NetworkName, c.networkChainID))
+	}
+	for _, url := range append(c.Endpoints(), c.WSURL) {
+		if name, chainID, ok := urlNetwork(url); ok && chainID != c.ChainID {
+			errs = append(errs, fmt.Errorf("RPC URL %s points to network %q (chain ID %d) but chain ID is %d",
+				MaskURL(url), name, chainID, c.ChainID))
+		}
 	}
 
-	return nil
+	// 3. 验证账户配置，错误信息中不包含私钥和密码
+	if c.PrivateKey != "" {
+		if _, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(c.PrivateKey), "0x")); err != nil {
+			errs = append(errs, fmt.Errorf("invalid PRIVATE_KEY: must be 64 hex characters"))
+		}
+	}
+	if c.KeystorePath != "" {
+		if _, err := os.Stat(c.KeystorePath); err != nil {
+			errs = append(errs, fmt.Errorf("invalid KEYSTORE_PATH: %w", err))
+		}
+		if c.KeystorePassword == "" {
+			errs = append(errs, fmt.Errorf("KEYSTORE_PASSWORD is required when KEYSTORE_PATH is set"))
+		}
+	}
+	if c.ToAddress != "" && !common.IsHexAddress(c.ToAddress) {
+		errs = append(errs, fmt.Errorf("invalid TO_ADDRESS: %s", c.ToAddress))
+	}
+
+	// 4. 验证交易和请求策略
+	if _, err := ParseFeeMode(c.FeeMode); err != nil {
+		errs = append(errs, fmt.Errorf("invalid FEE_MODE: %w", err))
+	}
+	if c.GasMargin < 0 || c.GasMargin > 1000 {
+		errs = append(errs, fmt.Errorf("invalid GAS_MARGIN_PERCENT: %d (0-1000)", c.GasMargin))
+	}
+	if c.RPCMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid RPC_MAX_ATTEMPTS: %d", c.RPCMaxAttempts))
+	}
+	if c.RPCRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("invalid RPC_RATE_LIMIT: %v", c.RPCRateLimit))
+	}
+	if c.RPCRateBurst < 0 {
+		errs = append(errs, fmt.Errorf("invalid RPC_RATE_BURST: %d", c.RPCRateBurst))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Endpoints 返回去重后的全部 RPC 节点地址，主节点在前
+func (c *Config) Endpoints() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, url := range append([]string{c.EthereumRPCURL}, c.RPCURLs...) {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	return urls
 }
 
 // GetNetworkInfo 返回网络信息摘要
@@ -85,6 +284,31 @@ func (c *Config) HasKeystore() bool {
 	return c.KeystorePath != "" && c.KeystorePassword != ""
 }
 
+// HasSigner 检查是否配置了 KeyStore 或私钥
+func (c *Config) HasSigner() bool {
+	return c.HasKeystore() || c.HasPrivateKey()
+}
+
+// hasScheme 检查 URL 是否以任一前缀开头
+func hasScheme(url string, schemes ...string) bool {
+	for _, scheme := range schemes {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -93,12 +317,52 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// getEnvAsInt64 获取环境变量并转换为 int64
-func getEnvAsInt64(key string, defaultValue int64) int64 {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
-			return intValue
+// getEnvAsInt64 获取环境变量并转换为 int64，未设置时返回默认值
+func getEnvAsInt64(key string, defaultValue int64) (int64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	intValue, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %q is not an integer", key, value)
+	}
+	return intValue, nil
+}
+
+// getEnvAsFloat64 获取环境变量并转换为 float64，未设置时返回默认值
+func getEnvAsFloat64(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %q is not a number", key, value)
+	}
+	return floatValue, nil
+}
+
+// getEnvAsBool 获取环境变量并转换为 bool，未设置时返回默认值
+func getEnvAsBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %q is not a boolean", key, value)
+	}
+	return boolValue, nil
+}
+
+// getEnvAsList 获取逗号分隔的环境变量列表，忽略空项
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
 	}
-	return defaultValue
+	return values
 }
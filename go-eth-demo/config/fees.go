@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeeMode 交易费用模式 (FEE_MODE)
+type FeeMode int
+
+const (
+	// FeeModeAuto 节点支持 EIP-1559 时使用动态费用交易，否则回退到传统交易
+	FeeModeAuto FeeMode = iota
+	// FeeModeLegacy 使用传统 gasPrice 交易
+	FeeModeLegacy
+	// FeeModeDynamic 使用 EIP-1559 动态费用交易 (type 2)
+	FeeModeDynamic
+)
+
+// String 返回费用模式名称
+func (m FeeMode) String() string {
+	switch m {
+	case FeeModeLegacy:
+		return "legacy"
+	case FeeModeDynamic:
+		return "eip1559"
+	default:
+		return "auto"
+	}
+}
+
+// ParseFeeMode 解析费用模式字符串 (auto / legacy / eip1559)，空字符串为 auto，eip1559 也可写作 1559 或 dynamic
+func ParseFeeMode(mode string) (FeeMode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "auto":
+		return FeeModeAuto, nil
+	case "legacy":
+		return FeeModeLegacy, nil
+	case "eip1559", "1559", "dynamic":
+		return FeeModeDynamic, nil
+	default:
+		return FeeModeAuto, fmt.Errorf("unknown fee mode %q (auto/legacy/eip1559)", mode)
+	}
+}
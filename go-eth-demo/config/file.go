@@ -0,0 +1,85 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFiles 未指定 CONFIG_FILE 时在当前目录依次查找的配置文件
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// fileConfig 配置文件结构，YAML 和 TOML 使用相同的字段名
+type fileConfig struct {
+	Network  string                    `yaml:"network" toml:"network"`
+	Networks map[string]NetworkProfile `yaml:"networks" toml:"networks"`
+
+	Account struct {
+		PrivateKey       string `yaml:"private_key" toml:"private_key"`
+		KeystorePath     string `yaml:"keystore_path" toml:"keystore_path"`
+		KeystorePassword string `yaml:"keystore_password" toml:"keystore_password"`
+		ToAddress        string `yaml:"to_address" toml:"to_address"`
+	} `yaml:"account" toml:"account"`
+
+	FeeMode   string `yaml:"fee_mode" toml:"fee_mode"`
+	GasMargin *int64 `yaml:"gas_margin" toml:"gas_margin"` // 未设置时使用默认值，0 表示不加余量
+
+	SkipChainIDCheck bool `yaml:"skip_chain_id_check" toml:"skip_chain_id_check"`
+	AllowMainnet     bool `yaml:"allow_mainnet" toml:"allow_mainnet"`
+
+	RPC struct {
+		MaxAttempts int64   `yaml:"max_attempts" toml:"max_attempts"`
+		RateLimit   float64 `yaml:"rate_limit" toml:"rate_limit"`
+		RateBurst   int64   `yaml:"rate_burst" toml:"rate_burst"`
+	} `yaml:"rpc" toml:"rpc"`
+}
+
+// findConfigFile 返回 CONFIG_FILE 指定的文件或当前目录下的默认配置文件，都不存在时返回空字符串
+func findConfigFile() string {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// readConfigFile 根据扩展名解析 YAML 或 TOML 配置文件，未知字段视为错误
+func readConfigFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := &fileConfig{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("failed to parse %s: unknown field %s", path, undecoded[0])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %s (use .yaml, .yml or .toml)", path)
+	}
+
+	return file, nil
+}
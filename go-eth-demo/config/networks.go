@@ -0,0 +1,109 @@
+package config
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NetworkProfile 命名网络的连接参数
+type NetworkProfile struct {
+	ChainID int64    `yaml:"chain_id" toml:"chain_id"` // 链 ID
+	RPCURL  string   `yaml:"rpc_url" toml:"rpc_url"`   // 主 RPC 节点
+	RPCURLs []string `yaml:"rpc_urls" toml:"rpc_urls"` // 备用 RPC 节点
+	WSURL   string   `yaml:"ws_url" toml:"ws_url"`     // WebSocket 节点，用于订阅
+}
+
+// 内置网络名称
+const (
+	NetworkSepolia = "sepolia"
+	NetworkMainnet = "mainnet"
+	NetworkLocal   = "local"
+)
+
+// builtinNetworks 内置网络配置，RPC 使用无需 API Key 的公共节点
+// 公共节点有较严格的限流，正式使用时请在配置文件或环境变量中替换为自己的节点
+var builtinNetworks = map[string]NetworkProfile{
+	NetworkSepolia: {
+		ChainID: 11155111,
+		RPCURL:  "https://ethereum-sepolia-rpc.publicnode.com",
+		WSURL:   "wss://ethereum-sepolia-rpc.publicnode.com",
+	},
+	NetworkMainnet: {
+		ChainID: 1,
+		RPCURL:  "https://ethereum-rpc.publicnode.com",
+		WSURL:   "wss://ethereum-rpc.publicnode.com",
+	},
+	NetworkLocal: { // anvil / hardhat 本地开发链
+		ChainID: 31337,
+		RPCURL:  "http://127.0.0.1:8545",
+		WSURL:   "ws://127.0.0.1:8545",
+	},
+}
+
+// LookupNetwork 查找内置网络配置，名称不区分大小写
+func LookupNetwork(name string) (NetworkProfile, bool) {
+	profile, ok := builtinNetworks[strings.ToLower(name)]
+	return profile, ok
+}
+
+// NetworkNames 返回内置网络名称列表
+func NetworkNames() []string {
+	return sortedNames(builtinNetworks)
+}
+
+// mergeProfile 用 override 中的非零字段覆盖 base
+func mergeProfile(base, override NetworkProfile) NetworkProfile {
+	if override.ChainID != 0 {
+		base.ChainID = override.ChainID
+	}
+	if override.RPCURL != "" {
+		base.RPCURL = override.RPCURL
+	}
+	if len(override.RPCURLs) > 0 {
+		base.RPCURLs = override.RPCURLs
+	}
+	if override.WSURL != "" {
+		base.WSURL = override.WSURL
+	}
+	return base
+}
+
+// sortedNames 返回网络名称的有序列表
+func sortedNames(networks map[string]NetworkProfile) []string {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// urlNetwork 根据 URL 主机名中的网络名称 (如 eth-mainnet.g.alchemy.com、sepolia.infura.io) 识别内置网络
+// 主机名中不含内置网络名称时返回 false；local 常见于局域网主机名 (如 node.local)，不参与识别
+func urlNetwork(rawURL string) (string, int64, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", 0, false
+	}
+	for _, label := range strings.FieldsFunc(strings.ToLower(u.Hostname()), func(r rune) bool {
+		return r == '.' || r == '-'
+	}) {
+		if profile, ok := builtinNetworks[label]; ok && label != NetworkLocal {
+			return label, profile.ChainID, true
+		}
+	}
+	return "", 0, false
+}
+
+// MaskURL 只保留 URL 的协议和主机名，避免在日志和错误信息中输出路径、查询参数或用户信息中的 API Key
+// 无法解析出主机名时只保留首尾部分
+func MaskURL(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Scheme + "://" + u.Host
+	}
+	if len(rawURL) > 20 {
+		return rawURL[:10] + "***" + rawURL[len(rawURL)-4:]
+	}
+	return rawURL
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/local/go-eth-demo/config"
 	"github.com/local/go-eth-demo/utils"
@@ -47,10 +48,28 @@ func main() {
 	fmt.Println("\n🌐 网络详细信息:")
 	fmt.Println("--------------------------------")
 	for key, value := range info {
+		if key == "endpoints" {
+			continue
+		}
 		fmt.Printf("%-15s: %v\n", key, value)
 	}
 
-	// 6. 获取最新区块号
+	// 6. 显示各 RPC 节点健康状态
+	fmt.Println("\n🩺 RPC 节点健康状态:")
+	fmt.Println("--------------------------------")
+	for _, endpoint := range client.EndpointHealth() {
+		status := "✅"
+		if !endpoint.Healthy {
+			status = "❌"
+		}
+		fmt.Printf("%s %s 延迟: %v 错误率: %.1f%% 请求数: %d\n",
+			status, endpoint.URL, endpoint.Latency.Round(time.Millisecond), endpoint.ErrorRate*100, endpoint.Requests)
+		if endpoint.LastError != "" {
+			fmt.Printf("   最近错误: %s\n", endpoint.LastError)
+		}
+	}
+
+	// 7. 获取最新区块号
 	fmt.Println("\n📦 最新区块信息:")
 	fmt.Println("--------------------------------")
 	blockNumber, err := client.GetLatestBlockNumber()
@@ -294,9 +294,7 @@ func containsMiddle(s, substr string) bool {
 
 // weiToGwei 将 Wei 转换为 Gwei
 func weiToGwei(wei *big.Int) string {
-	gwei := new(big.Float).SetInt(wei)
-	gwei.Quo(gwei, big.NewFloat(1e9))
-	return gwei.Text('f', 2)
+	return utils.WeiToGwei(wei)
 }
 
 // formatNumber 格式化大数字
@@ -62,7 +62,12 @@ func main() {
 
 	// 4. 分析区块中的交易
 	fmt.Println("\n💰 分析区块交易...")
-	analyzeBlockTransactions(latestBlock)
+	// 链 ID 用于确定区块生效的签名规则，获取失败时按交易类型恢复发送方
+	chainID, err := client.GetChainID()
+	if err != nil {
+		log.Printf("⚠️  获取链 ID 失败: %v", err)
+	}
+	analyzeBlockTransactions(chainID, latestBlock)
 
 	// 5. 区块时间分析
 	fmt.Println("\n⏰ 区块时间分析...")
@@ -143,7 +148,7 @@ func displayBlockInfo(title string, block *types.Block) {
 }
 
 // analyzeBlockTransactions 分析区块中的交易
-func analyzeBlockTransactions(block *types.Block) {
+func analyzeBlockTransactions(chainID *big.Int, block *types.Block) {
 	transactions := block.Transactions()
 
 	if len(transactions) == 0 {
@@ -168,7 +173,11 @@ func analyzeBlockTransactions(block *types.Block) {
 		tx := transactions[i]
 		fmt.Printf("\n交易 #%d:\n", i+1)
 		fmt.Printf("  哈希: %s\n", tx.Hash().Hex())
-		fmt.Printf("  发送方: %s\n", "需要签名恢复") // 简化显示
+		if from, err := utils.GetBlockTransactionSender(chainID, block.Header(), tx); err != nil {
+			fmt.Printf("  发送方: 无法获取 (%v)\n", err)
+		} else {
+			fmt.Printf("  发送方: %s\n", from.Hex())
+		}
 		if tx.To() != nil {
 			fmt.Printf("  接收方: %s\n", tx.To().Hex())
 		} else {
@@ -283,16 +292,12 @@ func analyzeGasUsage(block *types.Block) {
 
 // weiToEther 将 Wei 转换为 Ether
 func weiToEther(wei *big.Int) string {
-	ether := new(big.Float).SetInt(wei)
-	ether.Quo(ether, big.NewFloat(1e18))
-	return ether.Text('f', 6)
+	return utils.WeiToEther(wei)
 }
 
 // weiToGwei 将 Wei 转换为 Gwei
 func weiToGwei(wei *big.Int) string {
-	gwei := new(big.Float).SetInt(wei)
-	gwei.Quo(gwei, big.NewFloat(1e9))
-	return gwei.Text('f', 2)
+	return utils.WeiToGwei(wei)
 }
 
 // formatNumber 格式化大数字，添加千位分隔符
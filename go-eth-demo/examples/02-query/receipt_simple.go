@@ -36,7 +36,7 @@ func main() {
 		fmt.Println("🔍 获取最新区块中的交易进行演示...")
 
 		// 获取最新区块
-		latestBlock, err := ethClient.GetClient().BlockByNumber(ctx, nil)
+		latestBlock, err := ethClient.BlockByNumber(ctx, nil)
 		if err != nil {
 			log.Fatalf("获取最新区块失败: %v", err)
 		}
@@ -57,13 +57,13 @@ func main() {
 	fmt.Println("📋 查询交易收据...")
 	fmt.Println("================================")
 
-	receipt, err := ethClient.GetClient().TransactionReceipt(ctx, txHash)
+	receipt, err := ethClient.TransactionReceipt(ctx, txHash)
 	if err != nil {
 		log.Fatalf("查询交易收据失败: %v", err)
 	}
 
 	// 同时获取交易详情用于对比
-	tx, isPending, err := ethClient.GetClient().TransactionByHash(ctx, txHash)
+	tx, isPending, err := ethClient.TransactionByHash(ctx, txHash)
 	if err != nil {
 		log.Fatalf("查询交易详情失败: %v", err)
 	}
@@ -74,7 +74,16 @@ func main() {
 	if err != nil {
 		log.Fatalf("❌ 查询交易失败: %v", err)
 	}
-	displayTransactionInfo("交易详情", tx, isPending)
+	// 已打包的交易使用所在区块生效的签名规则恢复发送方
+	chainID, err := client.GetChainID()
+	if err != nil {
+		log.Printf("⚠️  获取链 ID 失败: %v", err)
+	}
+	var header *types.Header
+	if !isPending {
+		header = latestBlock.Header()
+	}
+	displayTransactionInfo("交易详情", tx, isPending, chainID, header)
 
 	// 3. 查询交易收据
 	fmt.Println("\n🧾 查询交易收据...")
@@ -124,8 +133,8 @@ func queryTransactionReceipt(ctx context.Context, client *ethclient.Client, txHa
 	return receipt, nil
 }
 
-// displayTransactionInfo 显示交易详细信息
-func displayTransactionInfo(title string, tx *types.Transaction, isPending bool) {
+// displayTransactionInfo 显示交易详细信息，header 为交易所在区块，待处理交易为 nil
+func displayTransactionInfo(title string, tx *types.Transaction, isPending bool, chainID *big.Int, header *types.Header) {
 	fmt.Printf("\n📋 %s:\n", title)
 	fmt.Println("--------------------------------")
 
@@ -134,7 +143,7 @@ func displayTransactionInfo(title string, tx *types.Transaction, isPending bool)
 	fmt.Printf("状态: %s\n", getTransactionStatus(isPending))
 
 	// 发送方和接收方
-	from, err := getTransactionSender(tx)
+	from, err := getTransactionSender(chainID, header, tx)
 	if err != nil {
 		fmt.Printf("发送方: 无法获取 (%v)\n", err)
 	} else {
@@ -435,30 +444,22 @@ func getTransactionStatus(isPending bool) string {
 }
 
 // getTransactionSender 获取交易发送方地址
-func getTransactionSender(tx *types.Transaction) (common.Address, error) {
-	// 这里需要链 ID 来正确恢复发送方地址
-	// 在实际应用中，你可能需要使用正确的 Signer
-	chainID := tx.ChainId()
-	if chainID == nil {
-		return common.Address{}, fmt.Errorf("无法获取链 ID")
+// 已打包的交易使用区块生效的签名规则，待处理交易按交易类型 (legacy/EIP-2930/EIP-1559/blob/set-code) 选择
+func getTransactionSender(chainID *big.Int, header *types.Header, tx *types.Transaction) (common.Address, error) {
+	if header == nil {
+		return utils.GetTransactionSender(tx)
 	}
-
-	signer := types.NewEIP155Signer(chainID)
-	return types.Sender(signer, tx)
+	return utils.GetBlockTransactionSender(chainID, header, tx)
 }
 
 // weiToEther 将 Wei 转换为 Ether
 func weiToEther(wei *big.Int) string {
-	ether := new(big.Float).SetInt(wei)
-	ether.Quo(ether, big.NewFloat(1e18))
-	return ether.Text('f', 6)
+	return utils.WeiToEther(wei)
 }
 
 // weiToGwei 将 Wei 转换为 Gwei
 func weiToGwei(wei *big.Int) string {
-	gwei := new(big.Float).SetInt(wei)
-	gwei.Quo(gwei, big.NewFloat(1e9))
-	return gwei.Text('f', 2)
+	return utils.WeiToGwei(wei)
 }
 
 // formatNumber 格式化大数字
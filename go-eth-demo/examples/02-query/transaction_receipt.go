@@ -399,16 +399,12 @@ func identifyCommonEvent(signature string) string {
 
 // weiToEther 将 Wei 转换为 Ether
 func weiToEther(wei *big.Int) string {
-	ether := new(big.Float).SetInt(wei)
-	ether.Quo(ether, big.NewFloat(1e18))
-	return ether.Text('f', 6)
+	return utils.WeiToEther(wei)
 }
 
 // weiToGwei 将 Wei 转换为 Gwei
 func weiToGwei(wei *big.Int) string {
-	gwei := new(big.Float).SetInt(wei)
-	gwei.Quo(gwei, big.NewFloat(1e9))
-	return gwei.Text('f', 2)
+	return utils.WeiToGwei(wei)
 }
 
 // formatNumber 格式化大数字
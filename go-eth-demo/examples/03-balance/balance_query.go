@@ -106,7 +106,7 @@ func main() {
 func queryETHBalance(ctx context.Context, ethClient *utils.EthClient, addressStr string) (*big.Int, error) {
 	address := common.HexToAddress(addressStr)
 
-	balance, err := ethClient.GetClient().BalanceAt(ctx, address, nil)
+	balance, err := ethClient.BalanceAt(ctx, address, nil)
 	if err != nil {
 		return nil, fmt.Errorf("查询余额失败: %w", err)
 	}
@@ -192,13 +192,13 @@ func queryHistoricalBalance(ctx context.Context, ethClient *utils.EthClient, add
 	// 查询前一个区块的余额
 	prevBlock := new(big.Int).Sub(currentBlock, big.NewInt(1))
 
-	prevBalance, err := ethClient.GetClient().BalanceAt(ctx, address, prevBlock)
+	prevBalance, err := ethClient.BalanceAt(ctx, address, prevBlock)
 	if err != nil {
 		return err
 	}
 
 	// 获取当前余额
-	currentBalance, err := ethClient.GetClient().BalanceAt(ctx, address, nil)
+	currentBalance, err := ethClient.BalanceAt(ctx, address, nil)
 	if err != nil {
 		return err
 	}
@@ -240,7 +240,7 @@ func analyzeBalanceHistory(ctx context.Context, ethClient *utils.EthClient, addr
 	// 查询最近 5 个区块的余额
 	for i := 4; i >= 0; i-- {
 		blockNum := new(big.Int).Sub(currentBlock, big.NewInt(int64(i)))
-		balance, err := ethClient.GetClient().BalanceAt(ctx, address, blockNum)
+		balance, err := ethClient.BalanceAt(ctx, address, blockNum)
 		if err != nil {
 			return err
 		}
@@ -264,7 +264,7 @@ func callContractMethod(ctx context.Context, ethClient *utils.EthClient, contrac
 	}
 
 	// 执行调用
-	result, err := ethClient.GetClient().CallContract(ctx, msg, nil)
+	result, err := ethClient.CallContract(ctx, msg, nil)
 	if err != nil {
 		return nil, fmt.Errorf("调用合约失败: %w", err)
 	}
@@ -292,19 +292,7 @@ func displayTokenInfo(token *TokenInfo) {
 
 // formatTokenBalance 格式化代币余额
 func formatTokenBalance(balance *big.Int, decimals uint8) string {
-	if balance.Sign() == 0 {
-		return "0"
-	}
-
-	// 转换为浮点数进行格式化
-	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-	balanceFloat := new(big.Float).SetInt(balance)
-	divisorFloat := new(big.Float).SetInt(divisor)
-
-	result := new(big.Float).Quo(balanceFloat, divisorFloat)
-
-	// 格式化为字符串，保留适当的小数位数
-	return result.Text('f', 6)
+	return utils.FormatUnits(balance, decimals)
 }
 
 // analyzeTokenBalance 分析代币余额等级
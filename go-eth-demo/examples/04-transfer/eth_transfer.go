@@ -2,14 +2,15 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
+	"os"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/local/go-eth-demo/config"
 	"github.com/local/go-eth-demo/utils"
 )
@@ -34,7 +35,7 @@ func main() {
 	fmt.Println("================================")
 
 	// 检查是否配置了私钥
-	if !cfg.HasPrivateKey() {
+	if !cfg.HasPrivateKey() && !cfg.HasKeystore() {
 		fmt.Println("⚠️  未配置私钥，将演示转账流程但不会实际发送交易")
 		fmt.Println("如需实际发送交易，请在 .env 文件中配置 PRIVATE_KEY")
 
@@ -45,19 +46,13 @@ func main() {
 
 	fmt.Println("🔑 检测到私钥配置，准备进行实际转账演示...")
 
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	// 加载签名者 (KeyStore 优先于明文私钥)，只允许为已连接节点的链签名
+	signer, err := utils.NewSignerFromConfig(cfg)
 	if err != nil {
-		log.Fatalf("解析私钥失败: %v", err)
+		log.Fatalf("加载签名者失败: %v", err)
 	}
-
-	// 获取发送方地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatalf("无法获取公钥")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	signer = ethClient.GuardSigner(signer)
+	fromAddress := signer.Address()
 
 	fmt.Printf("发送方地址: %s\n", fromAddress.Hex())
 
@@ -65,7 +60,7 @@ func main() {
 	fmt.Println("\n💰 检查账户余额:")
 	fmt.Println("--------------------------------")
 
-	balance, err := ethClient.GetClient().BalanceAt(ctx, fromAddress, nil)
+	balance, err := ethClient.BalanceAt(ctx, fromAddress, nil)
 	if err != nil {
 		log.Fatalf("查询余额失败: %v", err)
 	}
@@ -93,90 +88,85 @@ func main() {
 	fmt.Printf("接收方地址: %s\n", toAddress.Hex())
 	fmt.Printf("转账金额: %s ETH\n", utils.WeiToEther(transferAmount))
 
-	// 3. 获取 Gas 价格和 Nonce
-	fmt.Println("\n⛽ 获取 Gas 信息:")
+	// 3. 估算 Gas 和费用
+	fmt.Println("\n⛽ 估算 Gas 和费用:")
 	fmt.Println("--------------------------------")
 
-	gasPrice, err := ethClient.GetClient().SuggestGasPrice(ctx)
-	if err != nil {
-		log.Fatalf("获取 Gas 价格失败: %v", err)
-	}
-
-	nonce, err := ethClient.GetClient().PendingNonceAt(ctx, fromAddress)
+	cost, err := ethClient.EstimateTxCost(ctx, ethereum.CallMsg{
+		From:  fromAddress,
+		To:    &toAddress,
+		Value: transferAmount,
+	})
 	if err != nil {
-		log.Fatalf("获取 Nonce 失败: %v", err)
+		log.Fatalf("估算交易费用失败: %v", err)
 	}
 
-	gasLimit := uint64(21000) // ETH 转账的标准 Gas 限制
-
-	fmt.Printf("Gas 价格: %s Gwei\n", utils.WeiToGwei(gasPrice))
-	fmt.Printf("Gas 限制: %s\n", utils.FormatNumber(gasLimit))
-	fmt.Printf("Nonce: %d\n", nonce)
-
-	// 计算交易费用
-	txFee := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
-	fmt.Printf("预估交易费用: %s ETH\n", utils.WeiToEther(txFee))
+	// 4. 发送前预览并确认
+	fmt.Println("\n🚨 交易确认:")
+	cost.Print(os.Stdout)
 
-	// 计算总成本
-	totalCost := new(big.Int).Add(transferAmount, txFee)
-	fmt.Printf("总成本: %s ETH\n", utils.WeiToEther(totalCost))
+	// 检查余额是否足够支付转账金额和最高手续费
+	if err := cost.CheckBalance(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
 
-	// 检查余额是否足够支付总成本
-	if balance.Cmp(totalCost) < 0 {
-		fmt.Printf("❌ 余额不足支付总成本\n")
-		fmt.Printf("需要: %s ETH，当前: %s ETH\n",
-			utils.WeiToEther(totalCost), utils.WeiToEther(balance))
+	fmt.Println("\n⚠️  这是测试网交易，但仍会消耗真实的测试 ETH")
+	fmt.Print("确认发送以上交易? (y/N): ")
+	var answer string
+	fmt.Scanln(&answer)
+	if answer != "y" && answer != "Y" {
+		fmt.Println("已取消发送")
 		return
 	}
 
-	// 4. 创建交易
+	// 5. 创建交易
 	fmt.Println("\n📝 创建交易:")
 	fmt.Println("--------------------------------")
 
-	tx := types.NewTransaction(nonce, toAddress, transferAmount, gasLimit, gasPrice, nil)
+	nonceManager, err := ethClient.NonceManager()
+	if err != nil {
+		log.Fatalf("创建 Nonce 管理器失败: %v", err)
+	}
+
+	nonce, err := nonceManager.Next(ctx, fromAddress)
+	if err != nil {
+		log.Fatalf("获取 Nonce 失败: %v", err)
+	}
 
 	// 获取链 ID
 	chainID, err := ethClient.GetClient().ChainID(ctx)
 	if err != nil {
+		nonceManager.Release(fromAddress, nonce)
 		log.Fatalf("获取链 ID 失败: %v", err)
 	}
 
+	// 使用预览中的 Gas 限制和费用，保证发送的交易与确认的内容一致
+	tx := cost.NewTx(chainID, nonce, nil)
+
+	fmt.Printf("Nonce: %d\n", nonce)
 	fmt.Printf("链 ID: %s\n", chainID.String())
 	fmt.Printf("交易哈希 (未签名): %s\n", tx.Hash().Hex())
 
-	// 5. 签名交易
+	// 6. 签名交易
 	fmt.Println("\n✍️ 签名交易:")
 	fmt.Println("--------------------------------")
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signer.SignTx(tx, chainID)
 	if err != nil {
+		nonceManager.Release(fromAddress, nonce)
 		log.Fatalf("签名交易失败: %v", err)
 	}
 
 	fmt.Printf("签名后交易哈希: %s\n", signedTx.Hash().Hex())
 
-	// 6. 发送交易前的最终确认
-	fmt.Println("\n🚨 交易确认:")
-	fmt.Println("================================")
-	fmt.Printf("发送方: %s\n", fromAddress.Hex())
-	fmt.Printf("接收方: %s\n", toAddress.Hex())
-	fmt.Printf("金额: %s ETH\n", utils.WeiToEther(transferAmount))
-	fmt.Printf("Gas 费用: %s ETH\n", utils.WeiToEther(txFee))
-	fmt.Printf("总成本: %s ETH\n", utils.WeiToEther(totalCost))
-	fmt.Printf("交易哈希: %s\n", signedTx.Hash().Hex())
-
-	fmt.Println("\n⚠️  这是测试网交易，但仍会消耗真实的测试 ETH")
-	fmt.Println("请确认您要继续发送此交易...")
-
-	// 在实际应用中，这里应该有用户确认步骤
-	// 为了演示，我们直接继续
-
 	// 7. 发送交易
 	fmt.Println("\n🚀 发送交易:")
 	fmt.Println("--------------------------------")
 
-	err = ethClient.GetClient().SendTransaction(ctx, signedTx)
+	err = ethClient.SendTransaction(ctx, signedTx)
 	if err != nil {
+		nonceManager.HandleSendError(ctx, fromAddress, nonce, err)
 		log.Fatalf("发送交易失败: %v", err)
 	}
 
@@ -188,7 +178,7 @@ func main() {
 	fmt.Println("\n⏳ 等待交易确认:")
 	fmt.Println("--------------------------------")
 
-	receipt, err := waitForTransactionReceipt(ctx, ethClient, signedTx.Hash())
+	receipt, err := waitForTransactionReceipt(ctx, ethClient, signedTx)
 	if err != nil {
 		fmt.Printf("❌ 等待交易确认失败: %v\n", err)
 		return
@@ -201,7 +191,7 @@ func main() {
 	fmt.Println("\n💰 检查余额变化:")
 	fmt.Println("--------------------------------")
 
-	newBalance, err := ethClient.GetClient().BalanceAt(ctx, fromAddress, nil)
+	newBalance, err := ethClient.BalanceAt(ctx, fromAddress, nil)
 	if err != nil {
 		fmt.Printf("❌ 查询新余额失败: %v\n", err)
 		return
@@ -234,7 +224,7 @@ func demonstrateTransferProcess(ctx context.Context, ethClient *utils.EthClient)
 	fmt.Printf("\n2. 获取网络信息:\n")
 
 	// 获取当前 Gas 价格
-	gasPrice, err := ethClient.GetClient().SuggestGasPrice(ctx)
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
 	if err != nil {
 		fmt.Printf("   ❌ 获取 Gas 价格失败: %v\n", err)
 	} else {
@@ -249,18 +239,20 @@ func demonstrateTransferProcess(ctx context.Context, ethClient *utils.EthClient)
 		fmt.Printf("   链 ID: %s\n", chainID.String())
 	}
 
-	gasLimit := uint64(21000)
-	fmt.Printf("   Gas 限制: %s\n", utils.FormatNumber(gasLimit))
-
-	// 计算费用
-	if gasPrice != nil {
-		txFee := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
-		totalCost := new(big.Int).Add(transferAmount, txFee)
-
-		fmt.Printf("\n3. 费用计算:\n")
-		fmt.Printf("   转账金额: %s ETH\n", utils.WeiToEther(transferAmount))
-		fmt.Printf("   交易费用: %s ETH\n", utils.WeiToEther(txFee))
-		fmt.Printf("   总成本: %s ETH\n", utils.WeiToEther(totalCost))
+	// 估算 Gas 和费用，演示地址没有余额，估算失败时只显示流程
+	cost, err := ethClient.EstimateTxCost(ctx, ethereum.CallMsg{
+		From:  fromAddress,
+		To:    &toAddress,
+		Value: transferAmount,
+	})
+	if err != nil {
+		fmt.Printf("   ❌ 估算交易费用失败: %v\n", err)
+	} else {
+		fmt.Printf("\n3. 费用预览:\n")
+		cost.Print(os.Stdout)
+		if err := cost.CheckBalance(); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+		}
 	}
 
 	fmt.Printf("\n4. 交易流程:\n")
@@ -277,29 +269,31 @@ func demonstrateTransferProcess(ctx context.Context, ethClient *utils.EthClient)
 }
 
 // waitForTransactionReceipt 等待交易确认
-func waitForTransactionReceipt(ctx context.Context, ethClient *utils.EthClient, txHash common.Hash) (*types.Receipt, error) {
-	fmt.Printf("等待交易 %s 确认...\n", txHash.Hex())
-
-	// 在实际应用中，应该设置超时和重试机制
-	for i := 0; i < 60; i++ { // 最多等待 60 次，每次间隔可以根据网络调整
-		receipt, err := ethClient.GetClient().TransactionReceipt(ctx, txHash)
-		if err == nil {
-			fmt.Printf("✅ 交易已确认！(尝试 %d 次)\n", i+1)
-			return receipt, nil
-		}
-
-		// 如果是 "not found" 错误，继续等待
-		if err.Error() == "not found" {
-			fmt.Printf("⏳ 等待确认... (尝试 %d/60)\n", i+1)
-			// 在实际应用中应该使用 time.Sleep 或更好的等待机制
-			continue
-		}
-
-		// 其他错误直接返回
+func waitForTransactionReceipt(ctx context.Context, ethClient *utils.EthClient, tx *types.Transaction) (*types.Receipt, error) {
+	fmt.Printf("等待交易 %s 确认...\n", tx.Hash().Hex())
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	result, err := utils.WaitForTransaction(waitCtx, ethClient, tx, utils.WaitOptions{
+		Confirmations: 1,
+		OnProgress: func(status utils.TxStatus, confirmations uint64) {
+			if status == utils.TxStatusPending && confirmations == 0 {
+				fmt.Printf("⏳ 等待确认...\n")
+			}
+		},
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return nil, fmt.Errorf("交易确认超时")
+	switch result.Status {
+	case utils.TxStatusConfirmed, utils.TxStatusReverted:
+		fmt.Printf("✅ 交易已上链！(确认数 %d)\n", result.Confirmations)
+		return result.Receipt, nil
+	default:
+		return nil, result.Err()
+	}
 }
 
 // displayTransactionResult 显示交易结果
@@ -318,9 +312,12 @@ func displayTransactionResult(receipt *types.Receipt, tx *types.Transaction) {
 	fmt.Printf("交易索引: %d\n", receipt.TransactionIndex)
 	fmt.Printf("Gas 使用: %s\n", utils.FormatNumber(receipt.GasUsed))
 
-	// 计算实际费用
-	gasPrice := tx.GasPrice()
-	actualFee := new(big.Int).Mul(big.NewInt(int64(receipt.GasUsed)), gasPrice)
+	// 计算实际费用，动态费用交易按收据中的实际单价计算
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		gasPrice = tx.GasPrice()
+	}
+	actualFee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice)
 	fmt.Printf("实际费用: %s ETH\n", utils.WeiToEther(actualFee))
 
 	// Gas 使用效率
@@ -2,11 +2,12 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -117,7 +118,7 @@ func main() {
 	fmt.Println("================================")
 
 	// 检查是否配置了私钥
-	if !cfg.HasPrivateKey() {
+	if !cfg.HasPrivateKey() && !cfg.HasKeystore() {
 		fmt.Println("⚠️  未配置私钥，将演示代币转账流程但不会实际发送交易")
 		fmt.Println("如需实际发送交易，请在 .env 文件中配置 PRIVATE_KEY")
 
@@ -128,19 +129,13 @@ func main() {
 
 	fmt.Println("🔑 检测到私钥配置，准备进行实际代币转账演示...")
 
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	// 加载签名者 (KeyStore 优先于明文私钥)，只允许为已连接节点的链签名
+	signer, err := utils.NewSignerFromConfig(cfg)
 	if err != nil {
-		log.Fatalf("解析私钥失败: %v", err)
+		log.Fatalf("加载签名者失败: %v", err)
 	}
-
-	// 获取发送方地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatalf("无法获取公钥")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	signer = ethClient.GuardSigner(signer)
+	fromAddress := signer.Address()
 
 	fmt.Printf("发送方地址: %s\n", fromAddress.Hex())
 
@@ -186,7 +181,7 @@ func main() {
 	fmt.Println("\n⛽ 检查 ETH 余额 (Gas 费用):")
 	fmt.Println("--------------------------------")
 
-	ethBalance, err := ethClient.GetClient().BalanceAt(ctx, fromAddress, nil)
+	ethBalance, err := ethClient.BalanceAt(ctx, fromAddress, nil)
 	if err != nil {
 		log.Fatalf("查询 ETH 余额失败: %v", err)
 	}
@@ -215,106 +210,100 @@ func main() {
 	fmt.Printf("转账金额: %s %s\n",
 		formatTokenBalance(transferAmount, tokenInfo.Decimals), tokenInfo.Symbol)
 
-	// 5. 获取 Gas 信息
-	fmt.Println("\n⛽ 获取 Gas 信息:")
-	fmt.Println("--------------------------------")
-
-	gasPrice, err := ethClient.GetClient().SuggestGasPrice(ctx)
+	// 5. 编码代币转账调用
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
-		log.Fatalf("获取 Gas 价格失败: %v", err)
+		log.Fatalf("解析 ABI 失败: %v", err)
 	}
 
-	nonce, err := ethClient.GetClient().PendingNonceAt(ctx, fromAddress)
+	data, err := parsedABI.Pack("transfer", toAddress, transferAmount)
 	if err != nil {
-		log.Fatalf("获取 Nonce 失败: %v", err)
+		log.Fatalf("编码 transfer 调用失败: %v", err)
 	}
 
-	fmt.Printf("Gas 价格: %s Gwei\n", utils.WeiToGwei(gasPrice))
-	fmt.Printf("Nonce: %d\n", nonce)
-
-	// 6. 估算 Gas 限制
-	fmt.Println("\n📊 估算 Gas 限制:")
+	// 6. 估算 Gas 和费用
+	fmt.Println("\n⛽ 估算 Gas 和费用:")
 	fmt.Println("--------------------------------")
 
-	gasLimit, err := estimateTokenTransferGas(ctx, ethClient, tokenAddress, fromAddress, toAddress, transferAmount)
+	cost, err := estimateTokenTransferCost(ctx, ethClient, &parsedABI, ethereum.CallMsg{
+		From: fromAddress,
+		To:   &tokenAddress,
+		Data: data,
+	})
 	if err != nil {
-		fmt.Printf("⚠️  Gas 估算失败，使用默认值: %v\n", err)
-		gasLimit = 60000 // ERC20 转账的典型 Gas 限制
+		log.Fatalf("估算交易费用失败: %v", err)
 	}
 
-	fmt.Printf("估算 Gas 限制: %s\n", utils.FormatNumber(gasLimit))
+	// 7. 发送前预览并确认
+	fmt.Println("\n🚨 交易确认:")
+	fmt.Printf("代币合约: %s (%s)\n", tokenInfo.Address.Hex(), tokenInfo.Symbol)
+	fmt.Printf("代币接收方: %s\n", toAddress.Hex())
+	fmt.Printf("代币金额: %s %s\n",
+		formatTokenBalance(transferAmount, tokenInfo.Decimals), tokenInfo.Symbol)
+	cost.Print(os.Stdout)
 
-	// 计算交易费用
-	txFee := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
-	fmt.Printf("预估交易费用: %s ETH\n", utils.WeiToEther(txFee))
+	// 检查 ETH 余额是否足够支付最高手续费
+	if err := cost.CheckBalance(); err != nil {
+		fmt.Printf("❌ ETH 余额不足支付交易费用: %v\n", err)
+		return
+	}
 
-	// 检查 ETH 余额是否足够支付费用
-	if ethBalance.Cmp(txFee) < 0 {
-		fmt.Printf("❌ ETH 余额不足支付交易费用\n")
-		fmt.Printf("需要: %s ETH，当前: %s ETH\n",
-			utils.WeiToEther(txFee), utils.WeiToEther(ethBalance))
+	fmt.Println("\n⚠️  这是测试网交易，但仍会消耗真实的测试代币和 ETH")
+	fmt.Print("确认发送以上交易? (y/N): ")
+	var answer string
+	fmt.Scanln(&answer)
+	if answer != "y" && answer != "Y" {
+		fmt.Println("已取消发送")
 		return
 	}
 
-	// 7. 创建代币转账交易
+	// 8. 创建代币转账交易
 	fmt.Println("\n📝 创建代币转账交易:")
 	fmt.Println("--------------------------------")
 
-	// 解析 ABI
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	nonceManager, err := ethClient.NonceManager()
 	if err != nil {
-		log.Fatalf("解析 ABI 失败: %v", err)
+		log.Fatalf("创建 Nonce 管理器失败: %v", err)
 	}
 
-	// 编码 transfer 方法调用
-	data, err := parsedABI.Pack("transfer", toAddress, transferAmount)
+	nonce, err := nonceManager.Next(ctx, fromAddress)
 	if err != nil {
-		log.Fatalf("编码 transfer 调用失败: %v", err)
+		log.Fatalf("获取 Nonce 失败: %v", err)
 	}
 
-	// 创建交易
-	tx := types.NewTransaction(nonce, tokenAddress, big.NewInt(0), gasLimit, gasPrice, data)
-
 	// 获取链 ID
 	chainID, err := ethClient.GetClient().ChainID(ctx)
 	if err != nil {
+		nonceManager.Release(fromAddress, nonce)
 		log.Fatalf("获取链 ID 失败: %v", err)
 	}
 
+	// 使用预览中的 Gas 限制和费用，保证发送的交易与确认的内容一致
+	tx := cost.NewTx(chainID, nonce, data)
+
+	fmt.Printf("Nonce: %d\n", nonce)
 	fmt.Printf("链 ID: %s\n", chainID.String())
 	fmt.Printf("交易哈希 (未签名): %s\n", tx.Hash().Hex())
 
-	// 8. 签名交易
+	// 9. 签名交易
 	fmt.Println("\n✍️ 签名交易:")
 	fmt.Println("--------------------------------")
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signer.SignTx(tx, chainID)
 	if err != nil {
+		nonceManager.Release(fromAddress, nonce)
 		log.Fatalf("签名交易失败: %v", err)
 	}
 
 	fmt.Printf("签名后交易哈希: %s\n", signedTx.Hash().Hex())
 
-	// 9. 交易确认
-	fmt.Println("\n🚨 交易确认:")
-	fmt.Println("================================")
-	fmt.Printf("代币合约: %s (%s)\n", tokenInfo.Address.Hex(), tokenInfo.Symbol)
-	fmt.Printf("发送方: %s\n", fromAddress.Hex())
-	fmt.Printf("接收方: %s\n", toAddress.Hex())
-	fmt.Printf("转账金额: %s %s\n",
-		formatTokenBalance(transferAmount, tokenInfo.Decimals), tokenInfo.Symbol)
-	fmt.Printf("Gas 费用: %s ETH\n", utils.WeiToEther(txFee))
-	fmt.Printf("交易哈希: %s\n", signedTx.Hash().Hex())
-
-	fmt.Println("\n⚠️  这是测试网交易，但仍会消耗真实的测试代币和 ETH")
-	fmt.Println("请确认您要继续发送此交易...")
-
 	// 10. 发送交易
 	fmt.Println("\n🚀 发送交易:")
 	fmt.Println("--------------------------------")
 
-	err = ethClient.GetClient().SendTransaction(ctx, signedTx)
+	err = ethClient.SendTransaction(ctx, signedTx)
 	if err != nil {
+		nonceManager.HandleSendError(ctx, fromAddress, nonce, err)
 		log.Fatalf("发送交易失败: %v", err)
 	}
 
@@ -326,7 +315,7 @@ func main() {
 	fmt.Println("\n⏳ 等待交易确认:")
 	fmt.Println("--------------------------------")
 
-	receipt, err := waitForTransactionReceipt(ctx, ethClient, signedTx.Hash())
+	receipt, err := waitForTransactionReceipt(ctx, ethClient, signedTx)
 	if err != nil {
 		fmt.Printf("❌ 等待交易确认失败: %v\n", err)
 		return
@@ -335,6 +324,16 @@ func main() {
 	// 12. 显示交易结果
 	displayTokenTransferResult(receipt, signedTx, tokenInfo)
 
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		revertErr, err := utils.ReplayRevertReason(ctx, ethClient, signedTx, receipt, &parsedABI)
+		if err != nil {
+			fmt.Printf("⚠️  无法获取回滚原因: %v\n", err)
+		} else {
+			fmt.Printf("❌ 回滚原因: %s\n", revertErr.Reason)
+		}
+		return
+	}
+
 	// 13. 检查余额变化
 	fmt.Println("\n💰 检查余额变化:")
 	fmt.Println("--------------------------------")
@@ -426,7 +425,7 @@ func callContractMethod(ctx context.Context, ethClient *utils.EthClient, contrac
 		Data: data,
 	}
 
-	result, err := ethClient.GetClient().CallContract(ctx, msg, nil)
+	result, err := ethClient.CallContract(ctx, msg, nil)
 	if err != nil {
 		return nil, fmt.Errorf("调用合约失败: %w", err)
 	}
@@ -439,45 +438,25 @@ func callContractMethod(ctx context.Context, ethClient *utils.EthClient, contrac
 	return values, nil
 }
 
-// estimateTokenTransferGas 估算代币转账 Gas
-func estimateTokenTransferGas(ctx context.Context, ethClient *utils.EthClient, tokenAddress, from, to common.Address, amount *big.Int) (uint64, error) {
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
-	if err != nil {
-		return 0, fmt.Errorf("解析 ABI 失败: %w", err)
-	}
-
-	data, err := parsedABI.Pack("transfer", to, amount)
-	if err != nil {
-		return 0, fmt.Errorf("编码 transfer 调用失败: %w", err)
-	}
-
-	msg := ethereum.CallMsg{
-		From: from,
-		To:   &tokenAddress,
-		Data: data,
-	}
+// defaultTokenTransferGas Gas 估算失败时使用的 ERC20 转账典型 Gas 限制
+const defaultTokenTransferGas = 60000
 
-	gasLimit, err := ethClient.GetClient().EstimateGas(ctx, msg)
-	if err != nil {
-		return 0, fmt.Errorf("估算 Gas 失败: %w", err)
+// estimateTokenTransferCost 估算代币转账的 Gas 和费用，Gas 限制在估算值上增加配置的余量
+// 估算失败时显示回滚原因，并改用典型 Gas 限制计算费用
+func estimateTokenTransferCost(ctx context.Context, ethClient *utils.EthClient, parsedABI *abi.ABI, msg ethereum.CallMsg) (*utils.TxCost, error) {
+	cost, err := ethClient.EstimateTxCost(ctx, msg)
+	if err == nil {
+		return cost, nil
 	}
 
-	// 添加一些缓冲
-	return gasLimit + 10000, nil
+	fmt.Printf("⚠️  Gas 估算失败，使用默认值: %v\n", utils.ExplainCallError(err, parsedABI))
+	msg.Gas = defaultTokenTransferGas
+	return ethClient.EstimateTxCost(ctx, msg)
 }
 
 // formatTokenBalance 格式化代币余额
 func formatTokenBalance(balance *big.Int, decimals uint8) string {
-	if balance.Sign() == 0 {
-		return "0"
-	}
-
-	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-	balanceFloat := new(big.Float).SetInt(balance)
-	divisorFloat := new(big.Float).SetInt(divisor)
-
-	result := new(big.Float).Quo(balanceFloat, divisorFloat)
-	return result.Text('f', 6)
+	return utils.FormatUnits(balance, decimals)
 }
 
 // demonstrateTokenTransferProcess 演示代币转账流程
@@ -521,16 +500,15 @@ func demonstrateTokenTransferProcess(ctx context.Context, ethClient *utils.EthCl
 	fmt.Printf("   接收方: %s\n", toAddress.Hex())
 	fmt.Printf("   金额: 0.1 %s\n", tokenInfo.Symbol)
 
-	// 获取当前 Gas 价格
-	gasPrice, err := ethClient.GetClient().SuggestGasPrice(ctx)
+	// 按典型 Gas 限制估算费用，演示地址没有代币，无法通过节点估算
+	cost, err := ethClient.EstimateTxCost(ctx, ethereum.CallMsg{
+		From: fromAddress,
+		To:   &tokenAddress,
+		Gas:  defaultTokenTransferGas,
+	})
 	if err == nil {
-		gasLimit := uint64(60000) // 典型的 ERC20 转账 Gas 限制
-		txFee := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
-
 		fmt.Printf("\n5. Gas 费用估算:\n")
-		fmt.Printf("   Gas 价格: %s Gwei\n", utils.WeiToGwei(gasPrice))
-		fmt.Printf("   Gas 限制: %s\n", utils.FormatNumber(gasLimit))
-		fmt.Printf("   预估费用: %s ETH\n", utils.WeiToEther(txFee))
+		cost.Print(os.Stdout)
 	}
 
 	fmt.Printf("\n💡 要进行实际代币转账，请:\n")
@@ -540,25 +518,24 @@ func demonstrateTokenTransferProcess(ctx context.Context, ethClient *utils.EthCl
 }
 
 // waitForTransactionReceipt 等待交易确认
-func waitForTransactionReceipt(ctx context.Context, ethClient *utils.EthClient, txHash common.Hash) (*types.Receipt, error) {
-	fmt.Printf("等待交易 %s 确认...\n", txHash.Hex())
-
-	for i := 0; i < 60; i++ {
-		receipt, err := ethClient.GetClient().TransactionReceipt(ctx, txHash)
-		if err == nil {
-			fmt.Printf("✅ 交易已确认！(尝试 %d 次)\n", i+1)
-			return receipt, nil
-		}
+func waitForTransactionReceipt(ctx context.Context, ethClient *utils.EthClient, tx *types.Transaction) (*types.Receipt, error) {
+	fmt.Printf("等待交易 %s 确认...\n", tx.Hash().Hex())
 
-		if err.Error() == "not found" {
-			fmt.Printf("⏳ 等待确认... (尝试 %d/60)\n", i+1)
-			continue
-		}
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
 
+	result, err := utils.WaitForTransaction(waitCtx, ethClient, tx, utils.WaitOptions{Confirmations: 1})
+	if err != nil {
 		return nil, err
 	}
 
-	return nil, fmt.Errorf("交易确认超时")
+	switch result.Status {
+	case utils.TxStatusConfirmed, utils.TxStatusReverted:
+		fmt.Printf("✅ 交易已上链！(确认数 %d)\n", result.Confirmations)
+		return result.Receipt, nil
+	default:
+		return nil, result.Err()
+	}
 }
 
 // displayTokenTransferResult 显示代币转账结果
@@ -577,9 +554,12 @@ func displayTokenTransferResult(receipt *types.Receipt, tx *types.Transaction, t
 	fmt.Printf("交易索引: %d\n", receipt.TransactionIndex)
 	fmt.Printf("Gas 使用: %s\n", utils.FormatNumber(receipt.GasUsed))
 
-	// 计算实际费用
-	gasPrice := tx.GasPrice()
-	actualFee := new(big.Int).Mul(big.NewInt(int64(receipt.GasUsed)), gasPrice)
+	// 计算实际费用，动态费用交易按收据中的实际单价计算
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		gasPrice = tx.GasPrice()
+	}
+	actualFee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice)
 	fmt.Printf("实际费用: %s ETH\n", utils.WeiToEther(actualFee))
 
 	// 分析事件日志
@@ -125,7 +125,7 @@ func displayOnChainInfo(ctx context.Context, ethClient *utils.EthClient, address
 	addr := common.HexToAddress(address)
 
 	// 1. 获取 ETH 余额
-	balance, err := ethClient.GetClient().BalanceAt(ctx, addr, nil)
+	balance, err := ethClient.BalanceAt(ctx, addr, nil)
 	if err != nil {
 		return fmt.Errorf("获取余额失败: %w", err)
 	}
@@ -136,7 +136,7 @@ func displayOnChainInfo(ctx context.Context, ethClient *utils.EthClient, address
 	analyzeBalanceLevel(balance)
 
 	// 2. 获取交易计数 (nonce)
-	nonce, err := ethClient.GetClient().NonceAt(ctx, addr, nil)
+	nonce, err := ethClient.NonceAt(ctx, addr, nil)
 	if err != nil {
 		return fmt.Errorf("获取 nonce 失败: %w", err)
 	}
@@ -147,7 +147,7 @@ func displayOnChainInfo(ctx context.Context, ethClient *utils.EthClient, address
 	analyzeAccountActivity(nonce)
 
 	// 3. 检查是否为合约地址
-	code, err := ethClient.GetClient().CodeAt(ctx, addr, nil)
+	code, err := ethClient.CodeAt(ctx, addr, nil)
 	if err != nil {
 		return fmt.Errorf("获取合约代码失败: %w", err)
 	}
@@ -448,13 +448,13 @@ func displayNetworkInfo(ctx context.Context, ethClient *utils.EthClient) error {
 	}
 
 	// 获取最新区块号
-	blockNumber, err := ethClient.GetClient().BlockNumber(ctx)
+	blockNumber, err := ethClient.BlockNumber(ctx)
 	if err != nil {
 		return fmt.Errorf("获取区块号失败: %w", err)
 	}
 
 	// 获取 Gas 价格
-	gasPrice, err := ethClient.GetClient().SuggestGasPrice(ctx)
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
 	if err != nil {
 		return fmt.Errorf("获取 Gas 价格失败: %w", err)
 	}
@@ -8,22 +8,33 @@ import (
 	"os"
 	"os/signal"
 	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/alert"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/follower"
+	"github.com/local/go-eth-demo/metrics"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // BlockMonitor 区块监控器
 type BlockMonitor struct {
-	client       *ethclient.Client
+	client       *utils.EthClient
+	wsURL        string
 	ctx          context.Context
 	cancel       context.CancelFunc
 	stats        *MonitorStats
-	alertRules   []AlertRule
+	alerts       *alert.Engine
+	notifier     alert.Notifier // 告警通知渠道，nil 时只在终端输出
+	rulesFile    string         // 告警规则文件，为空时使用内置规则
 	blockHistory []*BlockInfo
+	chain        *follower.Chain // 最近区块哈希窗口，用于检测链重组
+	reorgDepth   int             // 正在处理的区块所在重组的深度
+	metrics      *metrics.Metrics
 }
 
 // MonitorStats 监控统计
@@ -38,6 +49,10 @@ type MonitorStats struct {
 	MinGasUsage      uint64
 	AverageBlockTime time.Duration
 	LastBlockTime    time.Time
+	ReorgCount       int64 // 链重组次数
+	RevertedBlocks   int64 // 因重组回滚的区块总数
+	LastReorgDepth   int   // 最近一次重组的深度
+	MaxReorgDepth    int   // 最大重组深度
 }
 
 // BlockInfo 区块信息
@@ -53,12 +68,31 @@ type BlockInfo struct {
 	Miner     string
 }
 
-// AlertRule 告警规则
-type AlertRule struct {
-	Name      string
-	Condition func(*BlockInfo, *MonitorStats) bool
-	Message   func(*BlockInfo, *MonitorStats) string
-}
+// defaultAlertRules 未指定 ALERT_RULES_FILE 时使用的告警规则，格式与规则文件相同
+const defaultAlertRules = `
+rules:
+  - name: 高交易量区块
+    field: tx_count
+    op: ">"
+    threshold: 200
+    message: "🔥 高交易量区块 #{number}: {tx_count} 笔交易"
+  - name: 高Gas使用率
+    field: gas_usage_percent
+    op: ">"
+    threshold: 95
+    message: "⚡ 高Gas使用率区块 #{number}: {gas_usage_percent}%"
+  - name: 长区块间隔
+    field: block_time_seconds
+    op: ">"
+    threshold: 20
+    message: "⏰ 长区块间隔 #{number}: {block_time_seconds}s"
+  - name: 空区块
+    field: tx_count
+    op: "=="
+    threshold: 0
+    severity: info
+    message: "📭 空区块 #{number}: 无交易"
+`
 
 func main() {
 	fmt.Println("🔍 高级区块监控器")
@@ -69,21 +103,50 @@ func main() {
 		log.Printf("警告: 无法加载 .env 文件: %v", err)
 	}
 
-	// 获取 RPC URL
-	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
-	if rpcURL == "" {
-		log.Fatal("请在 .env 文件中设置 ETHEREUM_RPC_URL")
+	// 加载配置，ETHEREUM_WS_URL 可选，未配置或断开时通过 RPC 轮询
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("配置加载失败: %v", err)
 	}
 
 	// 创建监控器
-	monitor, err := NewBlockMonitor(rpcURL)
+	monitor, err := NewBlockMonitor(cfg)
 	if err != nil {
 		log.Fatalf("创建监控器失败: %v", err)
 	}
 	defer monitor.Close()
 
-	// 设置告警规则
-	monitor.SetupAlertRules()
+	// Prometheus 指标，设置 METRICS_ADDR (如 :9100) 后通过 http://<地址>/metrics 抓取
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		go func() {
+			if err := monitor.ServeMetrics(addr); err != nil {
+				log.Printf("⚠️  指标服务启动失败: %v", err)
+			}
+		}()
+		fmt.Printf("📈 Prometheus 指标: http://%s/metrics\n", addr)
+	}
+
+	// 加载告警规则，ALERT_RULES_FILE 未设置时使用内置规则
+	rulesFile := os.Getenv("ALERT_RULES_FILE")
+	if err := monitor.SetupAlertRules(rulesFile); err != nil {
+		log.Fatalf("加载告警规则失败: %v", err)
+	}
+	if rulesFile != "" {
+		fmt.Printf("📋 告警规则: %s (修改后自动重新加载)\n", rulesFile)
+	}
+
+	// 告警渠道 (Webhook、Slack、Discord、邮件、日志文件)，未配置时只在终端输出
+	notifier, err := alert.NewDispatcherFromEnv(os.Getenv, func(sink string, a alert.Alert, err error) {
+		log.Printf("⚠️  告警发送失败 (%s): %v", sink, err)
+	})
+	if err != nil {
+		log.Fatalf("配置告警渠道失败: %v", err)
+	}
+	defer notifier.Close()
+	if sinks := notifier.Sinks(); len(sinks) > 0 {
+		fmt.Printf("📣 告警渠道: %s\n", strings.Join(sinks, ", "))
+	}
+	monitor.SetNotifier(notifier)
 
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
@@ -105,19 +168,25 @@ func main() {
 	monitor.Stop()
 }
 
-// NewBlockMonitor 创建新的区块监控器
-func NewBlockMonitor(rpcURL string) (*BlockMonitor, error) {
-	client, err := ethclient.Dial(rpcURL)
+// NewBlockMonitor 创建新的区块监控器，cfg.WSURL 为空时只轮询
+// 节点请求经过 utils.EthClient，自动故障转移并记录 RPC 延迟和错误指标
+func NewBlockMonitor(cfg *config.Config) (*BlockMonitor, error) {
+	client, err := utils.NewEthClient(cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	m := metrics.New()
+	client.SetRPCObserver(m.ObserveRPC)
+
 	return &BlockMonitor{
-		client: client,
-		ctx:    ctx,
-		cancel: cancel,
+		client:  client,
+		wsURL:   cfg.WSURL,
+		ctx:     ctx,
+		cancel:  cancel,
+		metrics: m,
 		stats: &MonitorStats{
 			StartTime:      time.Now(),
 			TotalGasUsed:   big.NewInt(0),
@@ -125,79 +194,142 @@ func NewBlockMonitor(rpcURL string) (*BlockMonitor, error) {
 			MinGasUsage:    ^uint64(0), // 最大值
 		},
 		blockHistory: make([]*BlockInfo, 0, 100), // 保留最近100个区块
+		chain:        follower.NewChain(client, 100),
 	}, nil
 }
 
-// SetupAlertRules 设置告警规则
-func (m *BlockMonitor) SetupAlertRules() {
-	m.alertRules = []AlertRule{
-		{
-			Name: "高交易量区块",
-			Condition: func(block *BlockInfo, stats *MonitorStats) bool {
-				return block.TxCount > 200
-			},
-			Message: func(block *BlockInfo, stats *MonitorStats) string {
-				return fmt.Sprintf("🔥 高交易量区块 #%d: %d 笔交易", block.Number, block.TxCount)
-			},
-		},
-		{
-			Name: "高Gas使用率",
-			Condition: func(block *BlockInfo, stats *MonitorStats) bool {
-				return float64(block.GasUsed)/float64(block.GasLimit) > 0.95
-			},
-			Message: func(block *BlockInfo, stats *MonitorStats) string {
-				usage := float64(block.GasUsed) / float64(block.GasLimit) * 100
-				return fmt.Sprintf("⚡ 高Gas使用率区块 #%d: %.2f%%", block.Number, usage)
-			},
-		},
-		{
-			Name: "长区块间隔",
-			Condition: func(block *BlockInfo, stats *MonitorStats) bool {
-				return block.BlockTime > 20*time.Second
-			},
-			Message: func(block *BlockInfo, stats *MonitorStats) string {
-				return fmt.Sprintf("⏰ 长区块间隔 #%d: %s", block.Number, block.BlockTime)
-			},
-		},
-		{
-			Name: "空区块",
-			Condition: func(block *BlockInfo, stats *MonitorStats) bool {
-				return block.TxCount == 0
-			},
-			Message: func(block *BlockInfo, stats *MonitorStats) string {
-				return fmt.Sprintf("📭 空区块 #%d: 无交易", block.Number)
-			},
-		},
+// ServeMetrics 在 addr 上提供 Prometheus /metrics，直到监控停止
+func (m *BlockMonitor) ServeMetrics(addr string) error {
+	return m.metrics.Serve(m.ctx, addr)
+}
+
+// SetupAlertRules 加载告警规则，path 为空时使用内置规则
+// 指定规则文件时，Start 会在运行期间重新加载修改过的文件
+func (m *BlockMonitor) SetupAlertRules(path string) error {
+	m.alerts = alert.NewEngine(m.alertValues(&BlockInfo{GasPrice: big.NewInt(0)}).Names())
+	m.rulesFile = path
+
+	if path == "" {
+		rules, err := alert.ParseRules([]byte(defaultAlertRules), "yaml")
+		if err != nil {
+			return err
+		}
+		return m.alerts.SetRules(rules)
 	}
+
+	_, err := m.alerts.LoadFile(path)
+	return err
+}
+
+// SetNotifier 设置告警通知渠道，触发的告警在终端输出的同时发送到 notifier
+func (m *BlockMonitor) SetNotifier(notifier alert.Notifier) {
+	m.notifier = notifier
 }
 
-// Start 启动监控
+// watchAlertRules 规则文件变化时重新加载，加载失败时继续使用原有规则
+func (m *BlockMonitor) watchAlertRules() {
+	m.alerts.Watch(m.ctx, m.rulesFile, 5*time.Second, func(rules *alert.RuleSet, err error) {
+		if err != nil {
+			log.Printf("⚠️  重新加载告警规则失败，继续使用原有规则: %v", err)
+			return
+		}
+		fmt.Printf("🔁 已重新加载告警规则: %d 条\n", len(rules.Rules))
+	})
+}
+
+// Start 启动监控，直到 Stop 被调用
+// 订阅断开时自动重新订阅，期间轮询并补齐错过的区块，不会因订阅失败退出
 func (m *BlockMonitor) Start() {
-	headers := make(chan *types.Header)
-	sub, err := m.client.SubscribeNewHead(m.ctx, headers)
-	if err != nil {
-		log.Fatalf("创建订阅失败: %v", err)
+	opts := follower.DefaultOptions()
+	opts.WSURL = m.wsURL
+	opts.OnEvent = func(event follower.Event) {
+		switch event.Kind {
+		case follower.EventSubscribed:
+			fmt.Println("✅ 区块订阅已建立")
+		case follower.EventSubscriptionLost:
+			log.Printf("❌ 订阅中断: %v，%s 后重新订阅", event.Err, event.Delay.Round(time.Millisecond))
+		case follower.EventPolling:
+			fmt.Println("🔄 通过轮询获取新区块")
+		case follower.EventBackfill:
+			fmt.Printf("⏪ 补齐区块 #%d - #%d\n", event.From, event.To)
+		default:
+			log.Printf("⚠️  %s", event)
+		}
+	}
+	heads := follower.NewHeadFollower(m.client, opts)
+	go heads.Run(m.ctx)
+
+	if m.rulesFile != "" {
+		go m.watchAlertRules()
 	}
-	defer sub.Unsubscribe()
 
 	fmt.Println("✅ 区块监控已启动")
 
-	for {
-		select {
-		case err := <-sub.Err():
-			log.Printf("❌ 订阅错误: %v", err)
-			return
+	for header := range heads.Headers() {
+		m.handleHeader(header)
+	}
+	fmt.Println("🔔 区块监控已停止")
+}
 
-		case header := <-headers:
-			m.processBlock(header)
+// handleHeader 检查新区块是否接在上一个区块之后，发生链重组时先回滚孤块再处理新分支
+func (m *BlockMonitor) handleHeader(header *types.Header) {
+	events, depth, err := m.chain.Add(m.ctx, header)
+	if err != nil {
+		log.Printf("⚠️  链重组检测失败: %v", err)
+	}
+	if depth > 0 {
+		m.recordReorg(depth)
+	}
+	m.reorgDepth = depth
+	defer func() { m.reorgDepth = 0 }()
 
-		case <-m.ctx.Done():
-			fmt.Println("🔔 区块监控已停止")
-			return
+	for _, event := range events {
+		switch event.Type {
+		case follower.ChainReverted:
+			m.revertBlock(event.Header)
+		case follower.ChainApplied:
+			m.processBlock(event.Header)
 		}
 	}
 }
 
+// recordReorg 记录链重组并发出告警
+func (m *BlockMonitor) recordReorg(depth int) {
+	m.metrics.ObserveReorg(depth)
+	m.stats.ReorgCount++
+	m.stats.RevertedBlocks += int64(depth)
+	m.stats.LastReorgDepth = depth
+	if depth > m.stats.MaxReorgDepth {
+		m.stats.MaxReorgDepth = depth
+	}
+	fmt.Printf("\n🚨 检测到链重组: 深度 %d 个区块 (累计 %d 次)\n", depth, m.stats.ReorgCount)
+}
+
+// revertBlock 回滚被重组移出当前链的区块，从历史记录和统计中扣除
+func (m *BlockMonitor) revertBlock(header *types.Header) {
+	hash := header.Hash().Hex()
+	fmt.Printf("↩️  区块 #%d 已回滚 (%s)\n", header.Number.Uint64(), hash)
+
+	for i := len(m.blockHistory) - 1; i >= 0; i-- {
+		block := m.blockHistory[i]
+		if block.Hash != hash {
+			continue
+		}
+
+		m.stats.BlockCount--
+		m.stats.TotalTxs -= int64(block.TxCount)
+		m.stats.TotalGasUsed.Sub(m.stats.TotalGasUsed, new(big.Int).SetUint64(block.GasUsed))
+		m.blockHistory = append(m.blockHistory[:i], m.blockHistory[i+1:]...)
+		break
+	}
+
+	// 区块间隔从新的最新区块开始计算
+	m.stats.LastBlockTime = time.Time{}
+	if n := len(m.blockHistory); n > 0 {
+		m.stats.LastBlockTime = m.blockHistory[n-1].Timestamp
+	}
+}
+
 // processBlock 处理新区块
 func (m *BlockMonitor) processBlock(header *types.Header) {
 	// 获取完整区块信息
@@ -232,6 +364,8 @@ func (m *BlockMonitor) processBlock(header *types.Header) {
 
 	// 更新统计信息
 	m.updateStats(blockInfo)
+	m.metrics.ObserveBlock(header, blockInfo.TxCount, blockTime)
+	m.metrics.SetAverageBlockInterval(m.stats.AverageBlockTime)
 
 	// 添加到历史记录
 	m.addToHistory(blockInfo)
@@ -331,15 +465,48 @@ func (m *BlockMonitor) displayBlockInfo(block *BlockInfo) {
 	}
 }
 
-// checkAlerts 检查告警规则
+// checkAlerts 按告警规则检查区块和统计信息
 func (m *BlockMonitor) checkAlerts(block *BlockInfo) {
-	for _, rule := range m.alertRules {
-		if rule.Condition(block, m.stats) {
-			fmt.Printf("🚨 %s\n", rule.Message(block, m.stats))
+	for _, a := range m.alerts.Evaluate(m.alertValues(block)) {
+		fmt.Printf("🚨 [%s] %s\n", a.Severity, a.Message)
+		m.metrics.ObserveAlert(a.Rule, string(a.Severity))
+		if m.notifier != nil {
+			if err := m.notifier.Notify(m.ctx, a); err != nil {
+				log.Printf("⚠️  发送告警失败: %v", err)
+			}
 		}
 	}
 }
 
+// alertValues 告警规则可以使用的字段
+func (m *BlockMonitor) alertValues(block *BlockInfo) alert.Values {
+	gasUsage := 0.0
+	if block.GasLimit > 0 {
+		gasUsage = float64(block.GasUsed) / float64(block.GasLimit) * 100
+	}
+	gasPrice, _ := new(big.Float).Quo(new(big.Float).SetInt(block.GasPrice), big.NewFloat(1e9)).Float64()
+
+	return alert.Values{
+		// 当前区块
+		"number":             float64(block.Number),
+		"tx_count":           float64(block.TxCount),
+		"gas_used":           float64(block.GasUsed),
+		"gas_limit":          float64(block.GasLimit),
+		"gas_usage_percent":  gasUsage,
+		"gas_price_gwei":     gasPrice,
+		"block_time_seconds": block.BlockTime.Seconds(),
+		"reorg_depth":        float64(m.reorgDepth), // 本区块触发的链重组深度，没有重组时为 0
+
+		// 监控统计
+		"block_count":            float64(m.stats.BlockCount),
+		"total_txs":              float64(m.stats.TotalTxs),
+		"avg_block_time_seconds": m.stats.AverageBlockTime.Seconds(),
+		"reorg_count":            float64(m.stats.ReorgCount),
+		"max_reorg_depth":        float64(m.stats.MaxReorgDepth),
+		"uptime_seconds":         time.Since(m.stats.StartTime).Seconds(),
+	}
+}
+
 // PeriodicReport 定期报告
 func (m *BlockMonitor) PeriodicReport() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -378,6 +545,11 @@ func (m *BlockMonitor) generateReport() {
 		fmt.Printf("平均区块时间: %s\n", m.stats.AverageBlockTime.Round(time.Millisecond))
 	}
 
+	if m.stats.ReorgCount > 0 {
+		fmt.Printf("链重组: %d 次，回滚 %d 个区块，最大深度 %d\n",
+			m.stats.ReorgCount, m.stats.RevertedBlocks, m.stats.MaxReorgDepth)
+	}
+
 	// 显示最近区块的统计
 	m.displayRecentBlocksStats()
 
@@ -448,6 +620,8 @@ func (m *BlockMonitor) generateFinalReport() {
 	}
 
 	fmt.Printf("Gas使用统计: %s (总计)\n", formatGas(m.stats.TotalGasUsed.Uint64()))
+	fmt.Printf("链重组: %d 次，回滚 %d 个区块，最大深度 %d\n",
+		m.stats.ReorgCount, m.stats.RevertedBlocks, m.stats.MaxReorgDepth)
 
 	fmt.Println("监控已完成!")
 }
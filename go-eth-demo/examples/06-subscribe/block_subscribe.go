@@ -10,9 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/follower"
 )
 
 func main() {
@@ -81,8 +83,8 @@ func main() {
 		TotalGasUsed: big.NewInt(0),
 	}
 
-	// 启动区块头订阅
-	go subscribeNewHeads(ctx, client, stats)
+	// 启动区块头订阅，配置了 ETHEREUM_WS_URL 时使用 WebSocket，否则轮询
+	go subscribeNewHeads(ctx, client, os.Getenv("ETHEREUM_WS_URL"), stats)
 
 	// 等待退出信号
 	<-sigChan
@@ -96,45 +98,94 @@ func main() {
 
 // BlockStats 区块统计信息
 type BlockStats struct {
-	StartTime    time.Time
-	BlockCount   int64
-	TotalTxs     int64
-	TotalGasUsed *big.Int
-	LastBlock    *types.Header
+	StartTime      time.Time
+	BlockCount     int64
+	TotalTxs       int64
+	TotalGasUsed   *big.Int
+	LastBlock      *types.Header
+	ReorgCount     int64 // 链重组次数
+	RevertedBlocks int64 // 因重组回滚的区块总数
+	MaxReorgDepth  int   // 最大重组深度
+
+	recent []countedBlock // 最近计入统计的区块，回滚时从统计中扣除
 }
 
-// subscribeNewHeads 订阅新区块头
-func subscribeNewHeads(ctx context.Context, client *ethclient.Client, stats *BlockStats) {
-	// 创建区块头订阅
-	headers := make(chan *types.Header)
-	sub, err := client.SubscribeNewHead(ctx, headers)
-	if err != nil {
-		log.Fatalf("创建区块头订阅失败: %v", err)
+// countedBlock 计入统计的区块
+type countedBlock struct {
+	hash    common.Hash
+	txCount int
+	gasUsed uint64
+}
+
+// subscribeNewHeads 订阅新区块头，断开时自动重新订阅，期间通过 client 轮询并补齐错过的区块
+func subscribeNewHeads(ctx context.Context, client *ethclient.Client, wsURL string, stats *BlockStats) {
+	opts := follower.DefaultOptions()
+	opts.WSURL = wsURL
+	opts.OnEvent = func(event follower.Event) {
+		switch event.Kind {
+		case follower.EventSubscribed:
+			fmt.Println("✅ 区块头订阅创建成功!")
+			fmt.Println("等待新区块...")
+			fmt.Println()
+		case follower.EventSubscriptionLost:
+			log.Printf("❌ 订阅错误: %v", event.Err)
+			fmt.Printf("🔄 %s 后尝试重新订阅，期间轮询新区块...\n", event.Delay.Round(time.Millisecond))
+		default:
+			log.Printf("ℹ️  %s", event)
+		}
 	}
-	defer sub.Unsubscribe()
+	heads := follower.NewHeadFollower(client, opts)
+	go heads.Run(ctx)
+
+	// 检查每个新区块是否接在上一个区块之后，发生链重组时先回滚孤块再处理新分支
+	chain := follower.NewChain(client, follower.DefaultChainWindow)
+	for header := range heads.Headers() {
+		events, depth, err := chain.Add(ctx, header)
+		if err != nil {
+			log.Printf("⚠️  链重组检测失败: %v", err)
+		}
+		if depth > 0 {
+			recordReorg(stats, depth)
+		}
 
-	fmt.Println("✅ 区块头订阅创建成功!")
-	fmt.Println("等待新区块...")
-	fmt.Println()
+		for _, event := range events {
+			switch event.Type {
+			case follower.ChainReverted:
+				revertBlock(event.Header, stats)
+			case follower.ChainApplied:
+				// 处理新区块头
+				processNewHeader(ctx, client, event.Header, stats)
+			}
+		}
+	}
+	fmt.Println("🔔 区块头订阅已停止")
+}
+
+// recordReorg 记录链重组
+func recordReorg(stats *BlockStats, depth int) {
+	stats.ReorgCount++
+	stats.RevertedBlocks += int64(depth)
+	if depth > stats.MaxReorgDepth {
+		stats.MaxReorgDepth = depth
+	}
+	fmt.Printf("🚨 检测到链重组: 深度 %d 个区块\n", depth)
+}
+
+// revertBlock 回滚被重组移出当前链的区块，从统计中扣除
+func revertBlock(header *types.Header, stats *BlockStats) {
+	fmt.Printf("↩️  区块 #%d 已回滚 (%s)\n", header.Number.Uint64(), header.Hash().Hex())
 
-	for {
-		select {
-		case err := <-sub.Err():
-			log.Printf("❌ 订阅错误: %v", err)
-			// 尝试重新订阅
-			fmt.Println("🔄 尝试重新订阅...")
-			time.Sleep(5 * time.Second)
-			go subscribeNewHeads(ctx, client, stats)
-			return
-
-		case header := <-headers:
-			// 处理新区块头
-			processNewHeader(ctx, client, header, stats)
-
-		case <-ctx.Done():
-			fmt.Println("🔔 区块头订阅已停止")
-			return
+	hash := header.Hash()
+	for i := len(stats.recent) - 1; i >= 0; i-- {
+		if stats.recent[i].hash != hash {
+			continue
 		}
+		counted := stats.recent[i]
+		stats.BlockCount--
+		stats.TotalTxs -= int64(counted.txCount)
+		stats.TotalGasUsed.Sub(stats.TotalGasUsed, new(big.Int).SetUint64(counted.gasUsed))
+		stats.recent = append(stats.recent[:i], stats.recent[i+1:]...)
+		break
 	}
 }
 
@@ -183,6 +234,10 @@ func analyzeBlock(block *types.Block, stats *BlockStats) {
 	txCount := len(block.Transactions())
 	stats.TotalTxs += int64(txCount)
 	stats.TotalGasUsed.Add(stats.TotalGasUsed, new(big.Int).SetUint64(block.GasUsed()))
+	stats.recent = append(stats.recent, countedBlock{hash: block.Hash(), txCount: txCount, gasUsed: block.GasUsed()})
+	if len(stats.recent) > follower.DefaultChainWindow {
+		stats.recent = stats.recent[1:]
+	}
 
 	fmt.Printf("交易数量: %d\n", txCount)
 
@@ -323,6 +378,9 @@ func displayFinalStats(stats *BlockStats) {
 	if stats.TotalGasUsed.Cmp(big.NewInt(0)) > 0 {
 		fmt.Printf("总 Gas 消耗: %s\n", formatNumber(stats.TotalGasUsed.Uint64()))
 	}
+
+	fmt.Printf("链重组: %d 次，回滚 %d 个区块，最大深度 %d\n",
+		stats.ReorgCount, stats.RevertedBlocks, stats.MaxReorgDepth)
 }
 
 // 格式化函数
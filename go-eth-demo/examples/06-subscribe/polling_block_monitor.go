@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/big"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/follower"
 )
 
 func main() {
@@ -43,12 +43,17 @@ func main() {
 
 	// 获取当前区块号
 	ctx := context.Background()
-	latestBlock, err := client.BlockNumber(ctx)
+	latest, err := client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		log.Fatalf("获取最新区块号失败: %v", err)
+		log.Fatalf("获取最新区块失败: %v", err)
 	}
+	latestBlock := latest.Number.Uint64()
 	fmt.Printf("当前区块号: %d\n", latestBlock)
 
+	// 最近区块哈希窗口，从当前区块开始，之后的区块父哈希不匹配时报告链重组
+	chain := follower.NewChain(client, follower.DefaultChainWindow)
+	chain.Add(ctx, latest)
+
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -60,8 +65,8 @@ func main() {
 
 	// 轮询参数
 	pollInterval := 5 * time.Second
-	lastBlockNumber := latestBlock
 	blockCount := 0
+	reorgCount, maxReorgDepth := 0, 0
 	startTime := time.Now()
 
 	// 创建定时器
@@ -73,20 +78,36 @@ func main() {
 		select {
 		case <-ticker.C:
 			// 检查新区块
-			currentBlock, err := client.BlockNumber(ctx)
+			head, err := client.HeaderByNumber(ctx, nil)
 			if err != nil {
-				log.Printf("❌ 获取区块号失败: %v", err)
+				log.Printf("❌ 获取最新区块失败: %v", err)
 				continue
 			}
+			currentBlock := head.Number.Uint64()
+
+			// 两次轮询之间的区块沿父哈希补齐；同一高度的区块哈希变化或父哈希不匹配时回滚孤块
+			events, depth, err := chain.Add(ctx, head)
+			if err != nil {
+				log.Printf("⚠️  链重组检测失败: %v", err)
+			}
+			if depth > 0 {
+				reorgCount++
+				maxReorgDepth = max(maxReorgDepth, depth)
+				fmt.Printf("\n🚨 检测到链重组: 深度 %d 个区块\n", depth)
+			}
 
 			// 如果有新区块
-			if currentBlock > lastBlockNumber {
+			if len(events) > 0 {
 				// 处理所有新区块
-				for blockNum := lastBlockNumber + 1; blockNum <= currentBlock; blockNum++ {
-					processBlock(ctx, client, blockNum)
+				for _, event := range events {
+					if event.Type == follower.ChainReverted {
+						fmt.Printf("↩️  区块 #%d 已回滚 (%s)\n", event.Header.Number.Uint64(), event.Header.Hash().Hex()[:16]+"...")
+						blockCount--
+						continue
+					}
+					processBlock(ctx, client, event.Header)
 					blockCount++
 				}
-				lastBlockNumber = currentBlock
 
 				// 显示统计信息
 				duration := time.Since(startTime)
@@ -105,6 +126,7 @@ func main() {
 			duration := time.Since(startTime)
 			fmt.Printf("总运行时间: %s\n", formatDuration(duration))
 			fmt.Printf("总共处理了 %d 个区块\n", blockCount)
+			fmt.Printf("链重组: %d 次，最大深度 %d\n", reorgCount, maxReorgDepth)
 			if duration.Minutes() > 0 {
 				avgBlocksPerMinute := float64(blockCount) / duration.Minutes()
 				fmt.Printf("平均区块频率: %.2f 个/分钟\n", avgBlocksPerMinute)
@@ -116,11 +138,11 @@ func main() {
 }
 
 // processBlock 处理单个区块
-func processBlock(ctx context.Context, client *ethclient.Client, blockNumber uint64) {
-	// 获取区块信息
-	block, err := client.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
+func processBlock(ctx context.Context, client *ethclient.Client, header *types.Header) {
+	// 按哈希获取区块，避免重组后按高度取到另一条分支上的区块
+	block, err := client.BlockByHash(ctx, header.Hash())
 	if err != nil {
-		log.Printf("❌ 获取区块 #%d 失败: %v", blockNumber, err)
+		log.Printf("❌ 获取区块 #%d 失败: %v", header.Number.Uint64(), err)
 		return
 	}
 
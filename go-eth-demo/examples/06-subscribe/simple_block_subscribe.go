@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/follower"
 )
 
 func main() {
@@ -68,6 +69,9 @@ func main() {
 
 	blockCount := 0
 
+	// 最近区块哈希窗口，新区块的父哈希不匹配时报告链重组
+	chain := follower.NewChain(client, follower.DefaultChainWindow)
+
 	// 订阅循环
 	for {
 		select {
@@ -76,20 +80,22 @@ func main() {
 			return
 
 		case header := <-headers:
-			blockCount++
-
-			fmt.Printf("\n🆕 区块 #%d\n", header.Number.Uint64())
-			fmt.Printf("时间: %s\n", time.Unix(int64(header.Time), 0).Format("15:04:05"))
-			fmt.Printf("哈希: %s\n", header.Hash().Hex()[:10]+"...")
-			fmt.Printf("Gas 使用: %s/%s (%.1f%%)\n",
-				formatGas(header.GasUsed),
-				formatGas(header.GasLimit),
-				float64(header.GasUsed)/float64(header.GasLimit)*100)
-
-			// 获取交易数量
-			block, err := client.BlockByHash(ctx, header.Hash())
-			if err == nil {
-				fmt.Printf("交易数: %d 笔\n", len(block.Transactions()))
+			events, depth, err := chain.Add(ctx, header)
+			if err != nil {
+				log.Printf("⚠️  链重组检测失败: %v", err)
+			}
+			if depth > 0 {
+				fmt.Printf("\n🚨 检测到链重组: 深度 %d 个区块\n", depth)
+			}
+
+			for _, event := range events {
+				if event.Type == follower.ChainReverted {
+					blockCount--
+					fmt.Printf("↩️  区块 #%d 已回滚 (%s)\n", event.Header.Number.Uint64(), event.Header.Hash().Hex()[:10]+"...")
+					continue
+				}
+				blockCount++
+				displayBlock(ctx, client, event.Header)
 			}
 
 			fmt.Printf("已接收: %d 个区块\n", blockCount)
@@ -104,6 +110,23 @@ func main() {
 	}
 }
 
+// displayBlock 显示新区块的基本信息和交易数量
+func displayBlock(ctx context.Context, client *ethclient.Client, header *types.Header) {
+	fmt.Printf("\n🆕 区块 #%d\n", header.Number.Uint64())
+	fmt.Printf("时间: %s\n", time.Unix(int64(header.Time), 0).Format("15:04:05"))
+	fmt.Printf("哈希: %s\n", header.Hash().Hex()[:10]+"...")
+	fmt.Printf("Gas 使用: %s/%s (%.1f%%)\n",
+		formatGas(header.GasUsed),
+		formatGas(header.GasLimit),
+		float64(header.GasUsed)/float64(header.GasLimit)*100)
+
+	// 获取交易数量
+	block, err := client.BlockByHash(ctx, header.Hash())
+	if err == nil {
+		fmt.Printf("交易数: %d 笔\n", len(block.Transactions()))
+	}
+}
+
 // formatGas 格式化 Gas 数量
 func formatGas(gas uint64) string {
 	if gas >= 1000000 {
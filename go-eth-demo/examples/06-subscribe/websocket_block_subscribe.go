@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/follower"
 )
 
 func main() {
@@ -29,15 +30,19 @@ func main() {
 		log.Fatal("请在 .env 文件中设置 ETHEREUM_WS_URL")
 	}
 
-	// 连接到以太坊节点 (WebSocket)
-	fmt.Printf("连接到 WebSocket: %s\n", wsURL)
-	client, err := ethclient.Dial(wsURL)
+	// 查询和补齐区块使用 HTTP 节点 (未配置时使用 WebSocket)，WebSocket 断开时仍可轮询
+	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
+	if rpcURL == "" {
+		rpcURL = wsURL
+	}
+	fmt.Printf("连接到节点: %s\n", rpcURL)
+	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
-		log.Fatalf("WebSocket 连接失败: %v", err)
+		log.Fatalf("节点连接失败: %v", err)
 	}
 	defer client.Close()
 
-	fmt.Println("✅ WebSocket 连接成功!")
+	fmt.Println("✅ 节点连接成功!")
 
 	// 获取当前区块号
 	latestBlock, err := client.BlockNumber(context.Background())
@@ -58,48 +63,53 @@ func main() {
 	fmt.Println("按 Ctrl+C 停止订阅")
 	fmt.Println("================================")
 
-	// 创建区块头订阅
-	headers := make(chan *types.Header)
-	sub, err := client.SubscribeNewHead(ctx, headers)
-	if err != nil {
-		log.Fatalf("创建订阅失败: %v", err)
+	// 创建区块头订阅，断开时按退避策略重新订阅，期间轮询并补齐错过的区块
+	opts := follower.DefaultOptions()
+	opts.WSURL = wsURL
+	opts.OnEvent = func(event follower.Event) {
+		switch event.Kind {
+		case follower.EventSubscribed:
+			fmt.Println("✅ 区块订阅创建成功!")
+			fmt.Println("等待新区块...")
+		case follower.EventSubscriptionLost:
+			log.Printf("❌ 订阅错误: %v", event.Err)
+			fmt.Printf("🔄 %s 后尝试重新连接...\n", event.Delay.Round(time.Millisecond))
+		default:
+			log.Printf("ℹ️  %s", event)
+		}
 	}
-	defer sub.Unsubscribe()
-
-	fmt.Println("✅ 区块订阅创建成功!")
-	fmt.Println("等待新区块...")
+	heads := follower.NewHeadFollower(client, opts)
+	go heads.Run(ctx)
 
 	blockCount := 0
 	startTime := time.Now()
+	reorgCount, maxReorgDepth := 0, 0
+
+	// 最近区块哈希窗口，新区块的父哈希不匹配时回溯到共同祖先
+	chain := follower.NewChain(client, follower.DefaultChainWindow)
 
 	// 订阅循环
 	for {
 		select {
-		case err := <-sub.Err():
-			log.Printf("❌ 订阅错误: %v", err)
-			fmt.Println("🔄 尝试重新连接...")
-			return
+		case header := <-heads.Headers():
+			events, depth, err := chain.Add(ctx, header)
+			if err != nil {
+				log.Printf("⚠️  链重组检测失败: %v", err)
+			}
+			if depth > 0 {
+				reorgCount++
+				maxReorgDepth = max(maxReorgDepth, depth)
+				fmt.Printf("\n🚨 检测到链重组: 深度 %d 个区块\n", depth)
+			}
 
-		case header := <-headers:
-			blockCount++
-
-			fmt.Printf("\n🆕 区块 #%d\n", header.Number.Uint64())
-			fmt.Printf("时间: %s\n", time.Unix(int64(header.Time), 0).Format("15:04:05"))
-			fmt.Printf("哈希: %s\n", header.Hash().Hex()[:16]+"...")
-			fmt.Printf("Gas 使用: %s/%s (%.1f%%)\n",
-				formatGas(header.GasUsed),
-				formatGas(header.GasLimit),
-				float64(header.GasUsed)/float64(header.GasLimit)*100)
-
-			// 获取完整区块信息
-			block, err := client.BlockByHash(ctx, header.Hash())
-			if err == nil {
-				fmt.Printf("交易数: %d 笔\n", len(block.Transactions()))
-
-				// 分析交易类型
-				if len(block.Transactions()) > 0 {
-					analyzeTransactions(block.Transactions())
+			for _, event := range events {
+				if event.Type == follower.ChainReverted {
+					blockCount--
+					fmt.Printf("↩️  区块 #%d 已回滚 (%s)\n", event.Header.Number.Uint64(), event.Header.Hash().Hex()[:16]+"...")
+					continue
 				}
+				blockCount++
+				displayBlock(ctx, client, event.Header)
 			}
 
 			fmt.Printf("已接收: %d 个区块\n", blockCount)
@@ -118,6 +128,7 @@ func main() {
 			duration := time.Since(startTime)
 			fmt.Printf("总运行时间: %s\n", formatDuration(duration))
 			fmt.Printf("总共接收了 %d 个区块\n", blockCount)
+			fmt.Printf("链重组: %d 次，最大深度 %d\n", reorgCount, maxReorgDepth)
 			if duration.Minutes() > 0 {
 				avgBlocksPerMinute := float64(blockCount) / duration.Minutes()
 				fmt.Printf("平均区块频率: %.2f 个/分钟\n", avgBlocksPerMinute)
@@ -128,6 +139,28 @@ func main() {
 	}
 }
 
+// displayBlock 显示新区块的基本信息和交易概况
+func displayBlock(ctx context.Context, client *ethclient.Client, header *types.Header) {
+	fmt.Printf("\n🆕 区块 #%d\n", header.Number.Uint64())
+	fmt.Printf("时间: %s\n", time.Unix(int64(header.Time), 0).Format("15:04:05"))
+	fmt.Printf("哈希: %s\n", header.Hash().Hex()[:16]+"...")
+	fmt.Printf("Gas 使用: %s/%s (%.1f%%)\n",
+		formatGas(header.GasUsed),
+		formatGas(header.GasLimit),
+		float64(header.GasUsed)/float64(header.GasLimit)*100)
+
+	// 获取完整区块信息
+	block, err := client.BlockByHash(ctx, header.Hash())
+	if err == nil {
+		fmt.Printf("交易数: %d 笔\n", len(block.Transactions()))
+
+		// 分析交易类型
+		if len(block.Transactions()) > 0 {
+			analyzeTransactions(block.Transactions())
+		}
+	}
+}
+
 // analyzeTransactions 简单分析交易
 func analyzeTransactions(txs types.Transactions) {
 	var transferCount, contractCount int
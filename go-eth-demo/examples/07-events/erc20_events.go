@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/alert"
 )
 
 // ERC20 Transfer 事件结构
@@ -66,6 +67,19 @@ func main() {
 	fmt.Println("🎯 ERC-20 代币事件监听")
 	fmt.Println("================================")
 
+	// 告警渠道 (Webhook、Slack、Discord、邮件、日志文件)，未配置时只在终端输出
+	var err error
+	notifier, err = alert.NewDispatcherFromEnv(os.Getenv, func(sink string, a alert.Alert, err error) {
+		log.Printf("⚠️  告警发送失败 (%s): %v", sink, err)
+	})
+	if err != nil {
+		log.Fatalf("配置告警渠道失败: %v", err)
+	}
+	defer notifier.Close()
+	if sinks := notifier.Sinks(); len(sinks) > 0 {
+		fmt.Printf("📣 告警渠道: %s\n", strings.Join(sinks, ", "))
+	}
+
 	// 连接以太坊节点
 	wsURL := os.Getenv("ETHEREUM_WS_URL")
 	if wsURL == "" {
@@ -204,7 +218,7 @@ func handleTransferEvent(vLog types.Log, contractABI abi.ABI, tokens map[common.
 	fmt.Printf("  时间: %s\n", time.Now().Format("15:04:05"))
 
 	// 检查特殊情况
-	checkSpecialTransfer(transferEvent, amount, tokenName)
+	checkSpecialTransfer(vLog, transferEvent, amount, tokenName)
 	fmt.Println()
 }
 
@@ -247,30 +261,58 @@ func handleApprovalEvent(vLog types.Log, contractABI abi.ABI, tokens map[common.
 	fmt.Println()
 }
 
-// 检查特殊转账情况
-func checkSpecialTransfer(transfer Transfer, amount *big.Float, tokenName string) {
+// 检查特殊转账情况，同时发送到配置的告警渠道
+func checkSpecialTransfer(vLog types.Log, transfer Transfer, amount *big.Float, tokenName string) {
 	// 零地址检查 (铸造/销毁)
 	zeroAddress := common.HexToAddress("0x0000000000000000000000000000000000000000")
 
 	if transfer.From == zeroAddress {
 		fmt.Printf("  🎯 特殊事件: 代币铸造 (Mint)\n")
+		notifyTransfer(vLog, "token_mint", alert.SeverityInfo,
+			fmt.Sprintf("🎯 代币铸造: %s %s", amount.Text('f', 6), tokenName), amount)
 	} else if transfer.To == zeroAddress {
 		fmt.Printf("  🔥 特殊事件: 代币销毁 (Burn)\n")
+		notifyTransfer(vLog, "token_burn", alert.SeverityInfo,
+			fmt.Sprintf("🔥 代币销毁: %s %s", amount.Text('f', 6), tokenName), amount)
 	}
 
 	// 大额转账检查
 	threshold := big.NewFloat(1000000) // 100万代币
 	if amount.Cmp(threshold) > 0 {
 		fmt.Printf("  🐋 大额转账: 超过 100万 %s\n", tokenName)
+		notifyTransfer(vLog, "large_transfer", alert.SeverityWarning,
+			fmt.Sprintf("🐋 大额转账: %s %s 从 %s 到 %s", amount.Text('f', 6), tokenName, transfer.From.Hex(), transfer.To.Hex()), amount)
 	}
 
 	// 小额转账检查
 	smallThreshold := big.NewFloat(0.001)
 	if amount.Cmp(smallThreshold) < 0 {
 		fmt.Printf("  🔍 微小转账: 少于 0.001 %s\n", tokenName)
+		notifyTransfer(vLog, "tiny_transfer", alert.SeverityInfo,
+			fmt.Sprintf("🔍 微小转账: %s %s", amount.Text('f', 6), tokenName), amount)
 	}
 }
 
+// notifier 特殊转账的告警渠道，由 main 按环境变量创建
+var notifier *alert.Dispatcher
+
+// notifyTransfer 把特殊转账作为告警发送到配置的渠道
+func notifyTransfer(vLog types.Log, rule string, severity alert.Severity, message string, amount *big.Float) {
+	value, _ := amount.Float64()
+	notifier.Notify(context.Background(), alert.Alert{
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+		Block:    vLog.BlockNumber,
+		Time:     time.Now(),
+		Values:   alert.Values{"amount": value},
+		Labels: map[string]string{
+			"token": vLog.Address.Hex(),
+			"tx":    vLog.TxHash.Hex(),
+		},
+	})
+}
+
 // 检查特殊授权情况
 func checkSpecialApproval(approval Approval, amount *big.Float, tokenName string) {
 	// 无限授权检查
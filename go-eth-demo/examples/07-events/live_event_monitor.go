@@ -17,6 +17,10 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/alert"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/metrics"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // ERC20 Transfer 事件结构
@@ -66,6 +70,19 @@ func main() {
 	fmt.Println("🎯 实时事件监听器")
 	fmt.Println("================================")
 
+	// 告警渠道 (Webhook、Slack、Discord、邮件、日志文件)，未配置时只在终端输出
+	var err error
+	notifier, err = alert.NewDispatcherFromEnv(os.Getenv, func(sink string, a alert.Alert, err error) {
+		log.Printf("⚠️  告警发送失败 (%s): %v", sink, err)
+	})
+	if err != nil {
+		log.Fatalf("配置告警渠道失败: %v", err)
+	}
+	defer notifier.Close()
+	if sinks := notifier.Sinks(); len(sinks) > 0 {
+		fmt.Printf("📣 告警渠道: %s\n", strings.Join(sinks, ", "))
+	}
+
 	// 解析 ABI
 	contractABI, err := abi.JSON(strings.NewReader(erc20EventABI))
 	if err != nil {
@@ -87,6 +104,16 @@ func main() {
 		},
 	}
 
+	// Prometheus 指标，设置 METRICS_ADDR (如 :9101) 后通过 http://<地址>/metrics 抓取
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		go func() {
+			if err := eventMetrics.Serve(context.Background(), addr); err != nil {
+				log.Printf("⚠️  指标服务启动失败: %v", err)
+			}
+		}()
+		fmt.Printf("📈 Prometheus 指标: http://%s/metrics\n", addr)
+	}
+
 	// 尝试WebSocket连接
 	wsURL := os.Getenv("ETHEREUM_WS_URL")
 	if wsURL != "" {
@@ -96,18 +123,19 @@ func main() {
 		}
 	}
 
-	// 回退到HTTP轮询模式
-	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
-	if rpcURL == "" {
-		log.Fatal("请在 .env 文件中设置 ETHEREUM_RPC_URL")
+	// 回退到HTTP轮询模式，请求经过 utils.EthClient 以记录 RPC 延迟和错误指标
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("配置加载失败: %v", err)
 	}
 
-	fmt.Printf("使用HTTP轮询模式: %s\n", rpcURL)
-	client, err := ethclient.Dial(rpcURL)
+	fmt.Println("使用HTTP轮询模式")
+	client, err := utils.NewEthClient(cfg)
 	if err != nil {
 		log.Fatalf("连接以太坊节点失败: %v", err)
 	}
 	defer client.Close()
+	client.SetRPCObserver(eventMetrics.ObserveRPC)
 
 	fmt.Println("✅ HTTP 连接成功!")
 	runPollingMode(client, contractABI, monitoredTokens)
@@ -184,7 +212,7 @@ func tryWebSocketMode(wsURL string, contractABI abi.ABI, monitoredTokens map[com
 }
 
 // 轮询模式
-func runPollingMode(client *ethclient.Client, contractABI abi.ABI, monitoredTokens map[common.Address]TokenInfo) {
+func runPollingMode(client *utils.EthClient, contractABI abi.ABI, monitoredTokens map[common.Address]TokenInfo) {
 	fmt.Println("\n🔄 开始轮询模式监听事件...")
 	fmt.Println("轮询间隔: 15秒")
 	fmt.Println("每次查询最近5个区块")
@@ -331,6 +359,7 @@ func handleTransferEventWS(vLog types.Log, contractABI abi.ABI, tokens map[commo
 	// 更新统计
 	stats.TransferCount++
 	stats.UniqueTokens[vLog.Address] = true
+	eventMetrics.ObserveEvent(vLog.Address, "Transfer")
 	stats.TotalVolume.Add(stats.TotalVolume, transfer.Amount)
 
 	// 检查是否为大额转账
@@ -347,7 +376,7 @@ func handleTransferEventWS(vLog types.Log, contractABI abi.ABI, tokens map[commo
 	fmt.Printf("   区块: #%d | 时间: %s\n", vLog.BlockNumber, time.Now().Format("15:04:05"))
 
 	// 检查特殊情况
-	checkSpecialTransferEvent(transfer, amount, tokenInfo.Symbol)
+	checkSpecialTransferEvent(vLog, transfer, amount, tokenInfo.Symbol)
 	fmt.Println()
 }
 
@@ -383,6 +412,7 @@ func handleTransferEventPolling(vLog types.Log, contractABI abi.ABI, tokens map[
 	// 更新统计
 	stats.TransferCount++
 	stats.UniqueTokens[vLog.Address] = true
+	eventMetrics.ObserveEvent(vLog.Address, "Transfer")
 
 	// 显示事件
 	fmt.Printf("  💸 %s | 从 %s 到 %s | %s %s | 区块 #%d\n",
@@ -392,32 +422,67 @@ func handleTransferEventPolling(vLog types.Log, contractABI abi.ABI, tokens map[
 		amount.Text('f', 4),
 		tokenInfo.Symbol,
 		vLog.BlockNumber)
+
+	// 检查特殊情况
+	checkSpecialTransferEvent(vLog, transfer, amount, tokenInfo.Symbol)
 }
 
-// 检查特殊转账情况
-func checkSpecialTransferEvent(transfer TransferEvent, amount *big.Float, tokenSymbol string) {
+// 检查特殊转账情况，同时发送到配置的告警渠道
+func checkSpecialTransferEvent(vLog types.Log, transfer TransferEvent, amount *big.Float, tokenSymbol string) {
 	// 零地址检查
 	zeroAddress := common.HexToAddress("0x0000000000000000000000000000000000000000")
 
 	if transfer.From == zeroAddress {
 		fmt.Printf("   🎯 代币铸造 (Mint)\n")
+		notifyTransfer(vLog, "token_mint", alert.SeverityInfo,
+			fmt.Sprintf("🎯 代币铸造: %s %s", amount.Text('f', 6), tokenSymbol), amount)
 	} else if transfer.To == zeroAddress {
 		fmt.Printf("   🔥 代币销毁 (Burn)\n")
+		notifyTransfer(vLog, "token_burn", alert.SeverityInfo,
+			fmt.Sprintf("🔥 代币销毁: %s %s", amount.Text('f', 6), tokenSymbol), amount)
 	}
 
 	// 大额转账检查
 	threshold := big.NewFloat(1000000)
 	if amount.Cmp(threshold) > 0 {
 		fmt.Printf("   🐋 大额转账: 超过100万 %s\n", tokenSymbol)
+		notifyTransfer(vLog, "large_transfer", alert.SeverityWarning,
+			fmt.Sprintf("🐋 大额转账: %s %s 从 %s 到 %s", amount.Text('f', 6), tokenSymbol, transfer.From.Hex(), transfer.To.Hex()), amount)
 	}
 
 	// 小额转账检查
 	smallThreshold := big.NewFloat(0.001)
 	if amount.Cmp(smallThreshold) < 0 {
 		fmt.Printf("   🔍 微小转账: 少于0.001 %s\n", tokenSymbol)
+		notifyTransfer(vLog, "tiny_transfer", alert.SeverityInfo,
+			fmt.Sprintf("🔍 微小转账: %s %s", amount.Text('f', 6), tokenSymbol), amount)
 	}
 }
 
+// notifier 特殊转账的告警渠道，由 main 按环境变量创建
+var notifier *alert.Dispatcher
+
+// eventMetrics 事件和 RPC 指标，设置 METRICS_ADDR 时通过 /metrics 导出
+var eventMetrics = metrics.New()
+
+// notifyTransfer 把特殊转账作为告警发送到配置的渠道
+func notifyTransfer(vLog types.Log, rule string, severity alert.Severity, message string, amount *big.Float) {
+	value, _ := amount.Float64()
+	eventMetrics.ObserveAlert(rule, string(severity))
+	notifier.Notify(context.Background(), alert.Alert{
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+		Block:    vLog.BlockNumber,
+		Time:     time.Now(),
+		Values:   alert.Values{"amount": value},
+		Labels: map[string]string{
+			"token": vLog.Address.Hex(),
+			"tx":    vLog.TxHash.Hex(),
+		},
+	})
+}
+
 // 显示统计信息
 func showStatistics(stats *EventStats) {
 	duration := time.Since(stats.StartTime)
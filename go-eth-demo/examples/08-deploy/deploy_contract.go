@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -16,9 +15,9 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // 合约编译输出结构
@@ -46,24 +45,12 @@ func main() {
 	}
 	defer client.Close()
 
-	// 获取私钥
-	privateKeyHex := os.Getenv("PRIVATE_KEY")
-	if strings.HasPrefix(privateKeyHex, "0x") {
-		privateKeyHex = privateKeyHex[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	// 加载签名者 (KeyStore 优先于明文私钥)
+	signer, err := utils.NewSignerFromEnv()
 	if err != nil {
-		log.Fatalf("解析私钥失败: %v", err)
+		log.Fatalf("加载签名者失败: %v", err)
 	}
-
-	// 获取部署地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("获取公钥失败")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddress := signer.Address()
 
 	fmt.Printf("📍 部署地址: %s\n", fromAddress.Hex())
 
@@ -92,11 +79,12 @@ func main() {
 
 	fmt.Println("✅ 合约数据加载成功")
 
-	// 获取网络ID
-	chainID, err := client.NetworkID(context.Background())
+	// 获取链ID，签名者只允许为该链签名，主网需设置 ALLOW_MAINNET=true
+	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		log.Fatalf("获取网络ID失败: %v", err)
+		log.Fatalf("获取链ID失败: %v", err)
 	}
+	signer = utils.NewChainGuard(signer, chainID, utils.AllowMainnetFromEnv())
 
 	// 获取nonce
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
@@ -148,7 +136,7 @@ func main() {
 	tx := types.NewContractCreation(nonce, big.NewInt(0), gasLimit, gasPrice, deployData)
 
 	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signer.SignTx(tx, chainID)
 	if err != nil {
 		log.Fatalf("签名交易失败: %v", err)
 	}
@@ -168,7 +156,7 @@ func main() {
 
 	// 等待交易确认
 	fmt.Println("⏳ 等待交易确认...")
-	receipt, err := waitForTransaction(client, signedTx.Hash())
+	receipt, err := waitForTransaction(client, signedTx)
 	if err != nil {
 		log.Fatalf("等待交易确认失败: %v", err)
 	}
@@ -219,19 +207,23 @@ func loadContractData() (*ContractData, *abi.ABI, error) {
 }
 
 // 等待交易确认
-func waitForTransaction(client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
-	for i := 0; i < 60; i++ { // 最多等待5分钟
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			return receipt, nil
-		}
-
-		if i%10 == 0 {
-			fmt.Printf("⏳ 等待确认中... (%d/60)\n", i+1)
-		}
-		time.Sleep(5 * time.Second)
-	}
-	return nil, fmt.Errorf("交易确认超时")
+func waitForTransaction(client *ethclient.Client, tx *types.Transaction) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute) // 最多等待5分钟
+	defer cancel()
+
+	result, err := utils.WaitForTransaction(ctx, client, tx, utils.WaitOptions{
+		Confirmations: 1,
+		PollInterval:  5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行失败的部署交易同样返回收据，由调用方根据状态处理
+	if result.Status != utils.TxStatusConfirmed && result.Status != utils.TxStatusReverted {
+		return nil, result.Err()
+	}
+	return result.Receipt, nil
 }
 
 // 保存合约地址
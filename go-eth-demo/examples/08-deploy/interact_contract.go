@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -16,9 +15,9 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/joho/godotenv"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // 合约编译输出结构
@@ -69,13 +68,24 @@ func main() {
 	}
 
 	// 获取私钥和地址
-	privateKey, fromAddress, err := getAccountInfo()
+	signer, err := utils.NewSignerFromEnv()
 	if err != nil {
 		log.Fatalf("获取账户信息失败: %v", err)
 	}
+	fromAddress := signer.Address()
 
 	fmt.Printf("👤 操作地址: %s\n", fromAddress.Hex())
 
+	// 创建 nonce 管理器，保证连续发送的交易使用顺序的 nonce
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		log.Fatalf("获取链ID失败: %v", err)
+	}
+	nonces := utils.NewNonceManager(client, chainID)
+
+	// 签名者只允许为已连接节点的链签名，主网需设置 ALLOW_MAINNET=true
+	signer = utils.NewChainGuard(signer, chainID, utils.AllowMainnetFromEnv())
+
 	// 演示合约交互
 	fmt.Println("\n🔍 1. 读取当前存储的值")
 	currentValue, err := readStoredValue(client, contractAddress, contractABI)
@@ -86,7 +96,7 @@ func main() {
 	}
 
 	fmt.Println("\n📝 2. 存储新值 (100)")
-	txHash, err := storeValue(client, contractAddress, contractABI, privateKey, fromAddress, big.NewInt(100))
+	txHash, err := storeValue(client, nonces, contractAddress, contractABI, signer, big.NewInt(100))
 	if err != nil {
 		log.Printf("存储失败: %v", err)
 	} else {
@@ -104,7 +114,7 @@ func main() {
 	}
 
 	fmt.Println("\n➕ 4. 增加值 (+50)")
-	txHash2, err := incrementValue(client, contractAddress, contractABI, privateKey, fromAddress, big.NewInt(50))
+	txHash2, err := incrementValue(client, nonces, contractAddress, contractABI, signer, big.NewInt(50))
 	if err != nil {
 		log.Printf("增加失败: %v", err)
 	} else {
@@ -152,8 +162,8 @@ func readStoredValue(client *ethclient.Client, contractAddress common.Address, c
 }
 
 // 存储值
-func storeValue(client *ethclient.Client, contractAddress common.Address, contractABI *abi.ABI,
-	privateKey *ecdsa.PrivateKey, fromAddress common.Address, value *big.Int) (string, error) {
+func storeValue(client *ethclient.Client, nonces *utils.NonceManager, contractAddress common.Address, contractABI *abi.ABI,
+	signer utils.Signer, value *big.Int) (string, error) {
 
 	// 打包函数调用
 	data, err := contractABI.Pack("store", value)
@@ -161,12 +171,12 @@ func storeValue(client *ethclient.Client, contractAddress common.Address, contra
 		return "", fmt.Errorf("打包函数调用失败: %v", err)
 	}
 
-	return sendTransaction(client, contractAddress, data, privateKey, fromAddress)
+	return sendTransaction(client, nonces, contractAddress, data, signer)
 }
 
 // 增加值
-func incrementValue(client *ethclient.Client, contractAddress common.Address, contractABI *abi.ABI,
-	privateKey *ecdsa.PrivateKey, fromAddress common.Address, increment *big.Int) (string, error) {
+func incrementValue(client *ethclient.Client, nonces *utils.NonceManager, contractAddress common.Address, contractABI *abi.ABI,
+	signer utils.Signer, increment *big.Int) (string, error) {
 
 	// 打包函数调用
 	data, err := contractABI.Pack("increment", increment)
@@ -174,18 +184,14 @@ func incrementValue(client *ethclient.Client, contractAddress common.Address, co
 		return "", fmt.Errorf("打包函数调用失败: %v", err)
 	}
 
-	return sendTransaction(client, contractAddress, data, privateKey, fromAddress)
+	return sendTransaction(client, nonces, contractAddress, data, signer)
 }
 
 // 发送交易
-func sendTransaction(client *ethclient.Client, contractAddress common.Address, data []byte,
-	privateKey *ecdsa.PrivateKey, fromAddress common.Address) (string, error) {
+func sendTransaction(client *ethclient.Client, nonces *utils.NonceManager, contractAddress common.Address, data []byte,
+	signer utils.Signer) (string, error) {
 
-	// 获取nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		return "", fmt.Errorf("获取nonce失败: %v", err)
-	}
+	fromAddress := signer.Address()
 
 	// 获取gas价格
 	gasPrice, err := client.SuggestGasPrice(context.Background())
@@ -203,24 +209,26 @@ func sendTransaction(client *ethclient.Client, contractAddress common.Address, d
 		gasLimit = 100000 // 使用默认值
 	}
 
-	// 获取网络ID
-	chainID, err := client.NetworkID(context.Background())
+	// 分配nonce
+	nonce, err := nonces.Next(context.Background(), fromAddress)
 	if err != nil {
-		return "", fmt.Errorf("获取网络ID失败: %v", err)
+		return "", fmt.Errorf("获取nonce失败: %v", err)
 	}
 
 	// 创建交易
 	tx := types.NewTransaction(nonce, contractAddress, big.NewInt(0), gasLimit, gasPrice, data)
 
 	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signer.SignTx(tx, nonces.ChainID())
 	if err != nil {
+		nonces.Release(fromAddress, nonce)
 		return "", fmt.Errorf("签名交易失败: %v", err)
 	}
 
 	// 发送交易
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
+		nonces.HandleSendError(context.Background(), fromAddress, nonce, err)
 		return "", fmt.Errorf("发送交易失败: %v", err)
 	}
 
@@ -252,29 +260,3 @@ func loadContractABI() (*abi.ABI, error) {
 
 	return &contractABI, nil
 }
-
-// 获取账户信息
-func getAccountInfo() (*ecdsa.PrivateKey, common.Address, error) {
-	privateKeyHex := os.Getenv("PRIVATE_KEY")
-	if privateKeyHex == "" {
-		return nil, common.Address{}, fmt.Errorf("请设置PRIVATE_KEY环境变量")
-	}
-
-	if strings.HasPrefix(privateKeyHex, "0x") {
-		privateKeyHex = privateKeyHex[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return nil, common.Address{}, fmt.Errorf("解析私钥失败: %v", err)
-	}
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, common.Address{}, fmt.Errorf("获取公钥失败")
-	}
-
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	return privateKey, fromAddress, nil
-}
@@ -0,0 +1,138 @@
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultChainWindow Chain 默认保留的最近区块数量，即可检测的最大重组深度
+const DefaultChainWindow = 128
+
+// ErrReorgTooDeep 新区块与窗口内的区块没有共同祖先，重组深度超过窗口大小
+var ErrReorgTooDeep = errors.New("reorg deeper than tracked window")
+
+// HashReader 回溯新分支所需的节点接口，utils.EthClient 和 ethclient.Client 都满足
+type HashReader interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// ChainEventType 区块在当前链上的变化
+type ChainEventType int
+
+const (
+	ChainApplied  ChainEventType = iota // 区块加入当前链
+	ChainReverted                       // 区块因链重组被移出当前链 (孤块)
+)
+
+func (t ChainEventType) String() string {
+	if t == ChainReverted {
+		return "reverted"
+	}
+	return "applied"
+}
+
+// ChainEvent Chain.Add 返回的区块变化
+type ChainEvent struct {
+	Type   ChainEventType
+	Header *types.Header
+}
+
+// Chain 维护最近区块头的窗口并检测链重组
+// 新区块的 ParentHash 与窗口中上一高度的哈希不一致时，沿 ParentHash 回溯到共同祖先，
+// 先按高度从高到低报告被回滚的孤块，再按高度从低到高报告新分支上的区块
+type Chain struct {
+	backend HashReader
+	size    int
+	headers []*types.Header // 按高度连续递增
+}
+
+// NewChain 创建 Chain，size 为保留的区块数量，小于 1 时使用 DefaultChainWindow
+func NewChain(backend HashReader, size int) *Chain {
+	if size < 1 {
+		size = DefaultChainWindow
+	}
+	return &Chain{backend: backend, size: size}
+}
+
+// Head 返回当前链的最新区块头，窗口为空时返回 nil
+func (c *Chain) Head() *types.Header {
+	if len(c.headers) == 0 {
+		return nil
+	}
+	return c.headers[len(c.headers)-1]
+}
+
+// Add 将新区块头加入窗口，返回当前链的变化和重组深度 (被回滚的区块数，没有重组时为 0)
+// 已在窗口中的区块和早于窗口的区块返回空列表；与上一个区块之间缺少的高度会沿 ParentHash 补齐
+// 重组深度超过窗口时返回 ErrReorgTooDeep，此时窗口重置为 header，events 只包含 header 的 ChainApplied
+func (c *Chain) Add(ctx context.Context, header *types.Header) (events []ChainEvent, depth int, err error) {
+	if len(c.headers) == 0 {
+		c.push(header)
+		return []ChainEvent{{Type: ChainApplied, Header: header}}, 0, nil
+	}
+
+	number := header.Number.Uint64()
+	first := c.headers[0].Number.Uint64()
+	if number < first {
+		return nil, 0, nil
+	}
+	if known := c.at(number); known != nil && known.Hash() == header.Hash() {
+		return nil, 0, nil
+	}
+
+	// 从新区块沿 ParentHash 回溯，直到父区块在窗口中，途经的区块组成新分支
+	branch := []*types.Header{header}
+	for cur := header; ; {
+		n := cur.Number.Uint64()
+		if n == 0 || n-1 < first {
+			c.headers = []*types.Header{header}
+			return []ChainEvent{{Type: ChainApplied, Header: header}}, 0,
+				fmt.Errorf("%w: no common ancestor for block %d since block %d", ErrReorgTooDeep, number, first)
+		}
+		if parent := c.at(n - 1); parent != nil && parent.Hash() == cur.ParentHash {
+			break
+		}
+
+		parent, err := c.backend.HeaderByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get parent header %s of block %d: %w", cur.ParentHash.Hex(), n, err)
+		}
+		branch = append(branch, parent)
+		cur = parent
+	}
+
+	// 共同祖先之后的区块是孤块，从高到低回滚
+	ancestor := branch[len(branch)-1].Number.Uint64() - 1
+	keep := int(ancestor-first) + 1
+	for i := len(c.headers) - 1; i >= keep; i-- {
+		events = append(events, ChainEvent{Type: ChainReverted, Header: c.headers[i]})
+	}
+	depth = len(c.headers) - keep
+	c.headers = c.headers[:keep]
+
+	for i := len(branch) - 1; i >= 0; i-- {
+		c.push(branch[i])
+		events = append(events, ChainEvent{Type: ChainApplied, Header: branch[i]})
+	}
+	return events, depth, nil
+}
+
+// at 返回窗口中指定高度的区块头，不在窗口中时返回 nil
+func (c *Chain) at(number uint64) *types.Header {
+	first := c.headers[0].Number.Uint64()
+	if number < first || number-first >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number-first]
+}
+
+func (c *Chain) push(header *types.Header) {
+	c.headers = append(c.headers, header)
+	if len(c.headers) > c.size {
+		c.headers = c.headers[len(c.headers)-c.size:]
+	}
+}
@@ -0,0 +1,193 @@
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeReader 按哈希查找区块头的内存节点
+type fakeReader map[common.Hash]*types.Header
+
+func (f fakeReader) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	header, ok := f[hash]
+	if !ok {
+		return nil, fmt.Errorf("header %s not found", hash.Hex())
+	}
+	return header, nil
+}
+
+// extend 在 parent 之后生成 n 个区块，branch 写入 Extra 以区分不同分支上同一高度的区块
+func (f fakeReader) extend(parent *types.Header, n int, branch string) []*types.Header {
+	headers := make([]*types.Header, 0, n)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			ParentHash: parent.Hash(),
+			Difficulty: big.NewInt(0),
+			Extra:      []byte(branch),
+		}
+		f[header.Hash()] = header
+		headers = append(headers, header)
+		parent = header
+	}
+	return headers
+}
+
+// label 区块的分支名和高度，如 a3
+func label(header *types.Header) string {
+	return fmt.Sprintf("%s%d", header.Extra, header.Number.Uint64())
+}
+
+// describe 将事件转换为 "+a3" (applied) 和 "-a3" (reverted) 形式
+func describe(events []ChainEvent) []string {
+	out := []string{}
+	for _, e := range events {
+		sign := "+"
+		if e.Type == ChainReverted {
+			sign = "-"
+		}
+		out = append(out, sign+label(e.Header))
+	}
+	return out
+}
+
+func TestChainAdd(t *testing.T) {
+	reader := fakeReader{}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte("g")}
+	reader[genesis.Hash()] = genesis
+
+	a := reader.extend(genesis, 6, "a")  // a1..a6
+	b := reader.extend(a[1], 3, "b")     // b3..b5，从 a2 分叉
+	c := reader.extend(a[0], 4, "c")     // c2..c5，从 a1 分叉
+	d := reader.extend(a[3], 1, "d")     // d5，从 a4 分叉
+	gap := reader.extend(a[1], 3, "gap") // gap3..gap5，从 a2 延伸
+
+	tests := []struct {
+		name      string
+		window    int
+		initial   []*types.Header
+		add       *types.Header
+		want      []string
+		wantDepth int
+		wantErr   error
+		wantHead  string
+	}{
+		{
+			name:     "next block",
+			initial:  a[:3],
+			add:      a[3],
+			want:     []string{"+a4"},
+			wantHead: "a4",
+		},
+		{
+			name:     "known block",
+			initial:  a[:3],
+			add:      a[1],
+			want:     []string{},
+			wantHead: "a3",
+		},
+		{
+			name:      "same height replacement",
+			initial:   a[:5],
+			add:       d[0],
+			want:      []string{"-a5", "+d5"},
+			wantDepth: 1,
+			wantHead:  "d5",
+		},
+		{
+			name:      "multi-block reorg",
+			initial:   a[:5],
+			add:       b[2],
+			want:      []string{"-a5", "-a4", "-a3", "+b3", "+b4", "+b5"},
+			wantDepth: 3,
+			wantHead:  "b5",
+		},
+		{
+			name:      "shorter branch replaces longer head",
+			initial:   a[:6],
+			add:       b[0],
+			want:      []string{"-a6", "-a5", "-a4", "-a3", "+b3"},
+			wantDepth: 4,
+			wantHead:  "b3",
+		},
+		{
+			name:     "gap fill without reorg",
+			initial:  a[:2],
+			add:      gap[2],
+			want:     []string{"+gap3", "+gap4", "+gap5"},
+			wantHead: "gap5",
+		},
+		{
+			name:     "block older than window",
+			window:   3,
+			initial:  a[:5],
+			add:      c[0],
+			want:     []string{},
+			wantHead: "a5",
+		},
+		{
+			name:     "reorg deeper than window",
+			window:   3,
+			initial:  a[:5],
+			add:      c[3],
+			want:     []string{"+c5"},
+			wantErr:  ErrReorgTooDeep,
+			wantHead: "c5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain(reader, tt.window)
+			for _, header := range tt.initial {
+				if _, _, err := chain.Add(context.Background(), header); err != nil {
+					t.Fatalf("Add(%s) error = %v", label(header), err)
+				}
+			}
+
+			events, depth, err := chain.Add(context.Background(), tt.add)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add(%s) error = %v, want %v", label(tt.add), err, tt.wantErr)
+			}
+			if got := describe(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Add(%s) events = %v, want %v", label(tt.add), got, tt.want)
+			}
+			if depth != tt.wantDepth {
+				t.Errorf("Add(%s) depth = %d, want %d", label(tt.add), depth, tt.wantDepth)
+			}
+			if head := label(chain.Head()); head != tt.wantHead {
+				t.Errorf("Head() = %s, want %s", head, tt.wantHead)
+			}
+		})
+	}
+}
+
+func TestChainAddMissingParent(t *testing.T) {
+	reader := fakeReader{}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte("g")}
+	a := reader.extend(genesis, 3, "a")
+	b := reader.extend(a[0], 2, "b")
+	delete(reader, b[0].Hash())
+
+	chain := NewChain(reader, 0)
+	for _, header := range a {
+		if _, _, err := chain.Add(context.Background(), header); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, _, err := chain.Add(context.Background(), b[1])
+	if err == nil || errors.Is(err, ErrReorgTooDeep) || events != nil {
+		t.Fatalf("Add() = %v, %v; want lookup error without events", describe(events), err)
+	}
+	if head := label(chain.Head()); head != "a3" {
+		t.Errorf("Head() = %s after failed lookup, want a3", head)
+	}
+}
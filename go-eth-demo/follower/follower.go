@@ -0,0 +1,328 @@
+// Package follower 跟随链头，按高度顺序输出新区块头
+//
+// HeadFollower 优先通过 WebSocket 订阅新区块头，订阅断开或长时间没有新区块时按退避策略重新订阅；
+// 没有配置 WebSocket 或重新订阅前的等待期间改用 HeaderByNumber 轮询。两次输出之间缺少的高度
+// (订阅中断、轮询间隔内出了多个区块) 会通过 HeaderByNumber 逐个补齐。已输出过的高度只在区块哈希不同
+// (链重组替换了该高度的区块) 时再次输出，之后从该高度继续，交给 Chain 检测重组。
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/utils"
+)
+
+// HeaderReader 轮询和补齐所需的节点接口，utils.EthClient 和 ethclient.Client 都满足
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// EventKind 跟随过程中的状态变化类型
+type EventKind int
+
+const (
+	EventSubscribed       EventKind = iota // WebSocket 订阅已建立
+	EventSubscriptionLost                  // 订阅失败、断开或停滞，Delay 后重新订阅
+	EventPolling                           // 开始轮询
+	EventPollFailed                        // 轮询失败，下次轮询时重试
+	EventBackfill                          // 正在补齐 From..To 之间的区块
+	EventBackfillFailed                    // 补齐失败，收到下一个区块时重试
+	EventGapSkipped                        // 缺口超过 MaxBackfill，From..To 之间的区块被跳过
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSubscribed:
+		return "subscribed"
+	case EventSubscriptionLost:
+		return "subscription lost"
+	case EventPolling:
+		return "polling"
+	case EventPollFailed:
+		return "poll failed"
+	case EventBackfill:
+		return "backfill"
+	case EventBackfillFailed:
+		return "backfill failed"
+	case EventGapSkipped:
+		return "gap skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 通过 Options.OnEvent 报告的状态变化
+type Event struct {
+	Kind     EventKind
+	Err      error         // 失败原因
+	Delay    time.Duration // EventSubscriptionLost 时距离下次重新订阅的等待时间
+	From, To uint64        // EventBackfill 和 EventGapSkipped 的区块范围 (含两端)
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventSubscriptionLost:
+		return fmt.Sprintf("%s: %v (resubscribing in %s)", e.Kind, e.Err, e.Delay.Round(time.Millisecond))
+	case EventPollFailed, EventBackfillFailed:
+		return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+	case EventBackfill, EventGapSkipped:
+		return fmt.Sprintf("%s: blocks %d-%d", e.Kind, e.From, e.To)
+	default:
+		return e.Kind.String()
+	}
+}
+
+// Options HeadFollower 的配置
+type Options struct {
+	WSURL        string            // WebSocket 节点地址，为空时只轮询
+	PollInterval time.Duration     // 轮询间隔
+	StallTimeout time.Duration     // 订阅超过该时间没有新区块时视为断开，0 表示不检测
+	Backoff      utils.RetryPolicy // 重新订阅的退避策略，MaxAttempts 不生效 (一直重试)
+	StartBlock   *big.Int          // 从该高度开始输出并补齐到最新，nil 时从收到的第一个区块开始
+	MaxBackfill  uint64            // 单个缺口最多补齐的区块数，0 表示不限制
+	Buffer       int               // Headers 通道的缓冲大小
+	OnEvent      func(Event)       // 状态变化回调，在跟随协程中同步调用
+}
+
+// DefaultOptions 返回默认配置: 12 秒轮询，2 分钟无新区块视为订阅停滞，1 秒起步最长 1 分钟的退避
+func DefaultOptions() Options {
+	return Options{
+		PollInterval: 12 * time.Second,
+		StallTimeout: 2 * time.Minute,
+		Backoff: utils.RetryPolicy{
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Minute,
+			Multiplier:     2,
+			Jitter:         0.2,
+		},
+		MaxBackfill: 256,
+		Buffer:      16,
+	}
+}
+
+// HeadFollower 跟随链头并按高度顺序输出区块头
+type HeadFollower struct {
+	backend HeaderReader
+	opts    Options
+	headers chan *types.Header
+
+	next    uint64                 // 下一个要输出的高度
+	started bool                   // 是否已确定起始高度
+	recent  map[uint64]common.Hash // 最近 DefaultChainWindow 个已输出高度的区块哈希
+}
+
+// NewHeadFollower 创建 HeadFollower，backend 用于轮询和补齐 (通常是 HTTP 节点)
+// opts 中未设置的 PollInterval 和 Backoff 使用 DefaultOptions 的值
+func NewHeadFollower(backend HeaderReader, opts Options) *HeadFollower {
+	defaults := DefaultOptions()
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	if opts.Backoff.InitialBackoff <= 0 {
+		opts.Backoff = defaults.Backoff
+	}
+	if opts.Buffer < 0 {
+		opts.Buffer = 0
+	}
+	return &HeadFollower{
+		backend: backend,
+		opts:    opts,
+		headers: make(chan *types.Header, opts.Buffer),
+		recent:  make(map[uint64]common.Hash),
+	}
+}
+
+// Headers 返回按高度递增输出区块头的通道，Run 返回后关闭
+func (f *HeadFollower) Headers() <-chan *types.Header {
+	return f.headers
+}
+
+// Run 跟随链头直到 ctx 结束，返回 ctx.Err()
+// 订阅和轮询的失败都会重试并通过 OnEvent 报告，不会使 Run 提前返回
+func (f *HeadFollower) Run(ctx context.Context) error {
+	defer close(f.headers)
+
+	if f.opts.StartBlock != nil {
+		f.next = f.opts.StartBlock.Uint64()
+		f.started = true
+	}
+
+	if f.opts.WSURL == "" {
+		return f.poll(ctx, nil)
+	}
+
+	attempt := 0
+	for {
+		delivered, err := f.subscribe(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// 订阅正常工作过一段时间后断开，退避从头开始
+		if delivered {
+			attempt = 0
+		}
+		attempt++
+		delay := f.opts.Backoff.Backoff(attempt)
+		f.emit(Event{Kind: EventSubscriptionLost, Err: err, Delay: delay})
+
+		// 等待重新订阅期间轮询，避免错过区块
+		if err := f.poll(ctx, time.After(delay)); err != nil {
+			return err
+		}
+	}
+}
+
+// subscribe 建立 WebSocket 订阅并输出收到的区块头，直到订阅失败、停滞或 ctx 结束
+// delivered 表示本次订阅是否输出过区块
+func (f *HeadFollower) subscribe(ctx context.Context) (delivered bool, err error) {
+	client, err := ethclient.DialContext(ctx, f.opts.WSURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to %s: %w", config.MaskURL(f.opts.WSURL), err)
+	}
+	defer client.Close()
+
+	heads := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return false, fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+	f.emit(Event{Kind: EventSubscribed})
+
+	var stall <-chan time.Time
+	var stallTimer *time.Timer
+	if f.opts.StallTimeout > 0 {
+		stallTimer = time.NewTimer(f.opts.StallTimeout)
+		defer stallTimer.Stop()
+		stall = stallTimer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return delivered, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return delivered, err
+		case <-stall:
+			return delivered, fmt.Errorf("no new heads for %s", f.opts.StallTimeout)
+		case header := <-heads:
+			if stallTimer != nil {
+				stallTimer.Reset(f.opts.StallTimeout)
+			}
+			if err := f.deliver(ctx, header); err != nil {
+				if ctx.Err() != nil {
+					return delivered, ctx.Err()
+				}
+				f.emit(Event{Kind: EventBackfillFailed, Err: err})
+				continue
+			}
+			delivered = true
+		}
+	}
+}
+
+// poll 立即查询一次最新区块头，之后按 PollInterval 查询，直到 until 触发或 ctx 结束
+// until 为 nil 时一直轮询
+func (f *HeadFollower) poll(ctx context.Context, until <-chan time.Time) error {
+	f.emit(Event{Kind: EventPolling})
+
+	ticker := time.NewTicker(f.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		header, err := f.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			err = fmt.Errorf("failed to get latest header: %w", err)
+		} else {
+			err = f.deliver(ctx, header)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			f.emit(Event{Kind: EventPollFailed, Err: err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-until:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver 输出 header，之前先补齐上次输出之后缺少的高度
+// 已输出过的高度在哈希相同或早于记录的窗口时忽略，哈希不同时输出并从该高度继续
+func (f *HeadFollower) deliver(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+	if !f.started {
+		f.next = number
+		f.started = true
+	}
+	if number < f.next {
+		hash, ok := f.recent[number]
+		if !ok || hash == header.Hash() {
+			return nil
+		}
+		return f.send(ctx, header)
+	}
+
+	if gap := number - f.next; gap > 0 {
+		if f.opts.MaxBackfill > 0 && gap > f.opts.MaxBackfill {
+			skipTo := number - f.opts.MaxBackfill
+			f.emit(Event{Kind: EventGapSkipped, From: f.next, To: skipTo - 1})
+			f.next = skipTo
+		}
+		f.emit(Event{Kind: EventBackfill, From: f.next, To: number - 1})
+	}
+	for f.next < number {
+		h, err := f.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(f.next))
+		if err != nil {
+			return fmt.Errorf("failed to backfill header %d: %w", f.next, err)
+		}
+		if err := f.send(ctx, h); err != nil {
+			return err
+		}
+	}
+	return f.send(ctx, header)
+}
+
+// send 把区块头写入通道，记录其哈希并把下一个高度设为 header 之后
+// 替换已输出高度时，更高高度的记录属于旧分支，一并删除
+func (f *HeadFollower) send(ctx context.Context, header *types.Header) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case f.headers <- header:
+	}
+
+	number := header.Number.Uint64()
+	for n := range f.recent {
+		if n > number || n+DefaultChainWindow <= number {
+			delete(f.recent, n)
+		}
+	}
+	f.recent[number] = header.Hash()
+	f.next = number + 1
+	return nil
+}
+
+func (f *HeadFollower) emit(event Event) {
+	if f.opts.OnEvent != nil {
+		f.opts.OnEvent(event)
+	}
+}
@@ -0,0 +1,262 @@
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// canonicalReader 按高度返回当前链上区块头的节点，fail 中的高度返回错误
+type canonicalReader struct {
+	headers map[uint64]*types.Header
+	fail    map[uint64]error
+	calls   []uint64 // 按顺序记录查询过的高度
+}
+
+func newCanonicalReader(headers ...*types.Header) *canonicalReader {
+	r := &canonicalReader{headers: map[uint64]*types.Header{}, fail: map[uint64]error{}}
+	r.set(headers...)
+	return r
+}
+
+// set 将 headers 设为各自高度的当前区块
+func (r *canonicalReader) set(headers ...*types.Header) {
+	for _, header := range headers {
+		r.headers[header.Number.Uint64()] = header
+	}
+}
+
+func (r *canonicalReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	n := number.Uint64()
+	r.calls = append(r.calls, n)
+	if err := r.fail[n]; err != nil {
+		return nil, err
+	}
+	header, ok := r.headers[n]
+	if !ok {
+		return nil, fmt.Errorf("header %d not found", n)
+	}
+	return header, nil
+}
+
+// newTestFollower 创建通道缓冲足够大的 HeadFollower，测试直接调用 deliver 输出区块
+// events 非 nil 时记录 OnEvent 报告的事件
+func newTestFollower(reader HeaderReader, maxBackfill uint64, events *[]Event) *HeadFollower {
+	opts := Options{MaxBackfill: maxBackfill, Buffer: 256}
+	if events != nil {
+		opts.OnEvent = func(e Event) { *events = append(*events, e) }
+	}
+	return NewHeadFollower(reader, opts)
+}
+
+// drain 取出通道中已输出的区块头
+func drain(f *HeadFollower) []*types.Header {
+	var out []*types.Header
+	for {
+		select {
+		case header := <-f.headers:
+			out = append(out, header)
+		default:
+			return out
+		}
+	}
+}
+
+// labels 区块头的分支名和高度列表
+func labels(headers []*types.Header) []string {
+	out := []string{}
+	for _, header := range headers {
+		out = append(out, label(header))
+	}
+	return out
+}
+
+func TestDeliverReplacedHeight(t *testing.T) {
+	blocks := fakeReader{}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte("g")}
+	blocks[genesis.Hash()] = genesis
+	a := blocks.extend(genesis, 5, "a") // a1..a5
+	b := blocks.extend(a[1], 3, "b")    // b3..b5，从 a2 分叉
+	d := blocks.extend(a[3], 1, "d")    // d5，从 a4 分叉
+
+	reader := newCanonicalReader(a...)
+	f := newTestFollower(reader, 0, nil)
+	chain := NewChain(blocks, 0)
+
+	// deliver 输出的区块交给 Chain，返回 Chain 报告的变化
+	add := func(header *types.Header) []string {
+		t.Helper()
+		if err := f.deliver(context.Background(), header); err != nil {
+			t.Fatalf("deliver(%s) error = %v", label(header), err)
+		}
+		var changes []string
+		for _, delivered := range drain(f) {
+			events, _, err := chain.Add(context.Background(), delivered)
+			if err != nil {
+				t.Fatalf("Chain.Add(%s) error = %v", label(delivered), err)
+			}
+			changes = append(changes, describe(events)...)
+		}
+		return changes
+	}
+
+	for _, header := range a {
+		add(header)
+	}
+
+	steps := []struct {
+		name      string
+		canonical []*types.Header // 节点在这一步之前切换到的分支
+		header    *types.Header
+		want      []string
+	}{
+		{name: "same block again", header: a[4]},
+		{name: "same height replaced", header: d[0], want: []string{"-a5", "+d5"}},
+		{name: "shorter branch replaces head", header: b[0], want: []string{"-d5", "-a4", "-a3", "+b3"}},
+		{name: "branch continues after gap", canonical: b, header: b[2], want: []string{"+b4", "+b5"}},
+		{name: "orphaned block again", header: a[3], want: []string{"-b5", "-b4", "-b3", "+a3", "+a4"}},
+	}
+	for _, step := range steps {
+		reader.set(step.canonical...)
+		if got := add(step.header); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: changes = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestDeliverIgnoresHeightBeforeWindow(t *testing.T) {
+	blocks := fakeReader{}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte("g")}
+	a := blocks.extend(genesis, DefaultChainWindow+2, "a")
+	old := blocks.extend(genesis, 1, "x") // x1，与 a1 同一高度
+
+	f := newTestFollower(newCanonicalReader(a...), 0, nil)
+	for _, header := range a {
+		if err := f.deliver(context.Background(), header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	drain(f)
+
+	if err := f.deliver(context.Background(), old[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := drain(f); len(got) != 0 {
+		t.Errorf("delivered %v for a height older than the window", labels(got))
+	}
+}
+
+// testChain 在创世区块之后生成 n 个区块
+func testChain(n int) []*types.Header {
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte("g")}
+	return fakeReader{}.extend(genesis, n, "a")
+}
+
+func TestDeliverBackfillsGapInOrder(t *testing.T) {
+	a := testChain(10)
+	reader := newCanonicalReader(a...)
+	var events []Event
+	f := newTestFollower(reader, 0, &events)
+
+	for _, header := range []*types.Header{a[1], a[2], a[6], a[5], a[9]} { // a2, a3, a7, a6, a10
+		if err := f.deliver(context.Background(), header); err != nil {
+			t.Fatalf("deliver(%s) error = %v", label(header), err)
+		}
+	}
+
+	want := []string{"a2", "a3", "a4", "a5", "a6", "a7", "a8", "a9", "a10"}
+	if got := labels(drain(f)); !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if want := []uint64{4, 5, 6, 8, 9}; !reflect.DeepEqual(reader.calls, want) {
+		t.Errorf("backfilled heights %v, want %v", reader.calls, want)
+	}
+	wantEvents := []Event{{Kind: EventBackfill, From: 4, To: 6}, {Kind: EventBackfill, From: 8, To: 9}}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("events = %v, want %v", events, wantEvents)
+	}
+}
+
+func TestDeliverStartBlock(t *testing.T) {
+	a := testChain(6)
+	reader := newCanonicalReader(a...)
+	f := newTestFollower(reader, 0, nil)
+	f.next, f.started = 3, true // Run 按 Options.StartBlock 设置
+
+	if err := f.deliver(context.Background(), a[5]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := labels(drain(f)), []string{"a3", "a4", "a5", "a6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+}
+
+func TestDeliverSkipsGapBeyondMaxBackfill(t *testing.T) {
+	a := testChain(20)
+	reader := newCanonicalReader(a...)
+	var events []Event
+	f := newTestFollower(reader, 3, &events)
+
+	if err := f.deliver(context.Background(), a[1]); err != nil { // a2
+		t.Fatal(err)
+	}
+	if err := f.deliver(context.Background(), a[19]); err != nil { // a20，缺少 a3..a19
+		t.Fatal(err)
+	}
+
+	want := []string{"a2", "a17", "a18", "a19", "a20"}
+	if got := labels(drain(f)); !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	wantEvents := []Event{{Kind: EventGapSkipped, From: 3, To: 16}, {Kind: EventBackfill, From: 17, To: 19}}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("events = %v, want %v", events, wantEvents)
+	}
+
+	// 缺口不超过 MaxBackfill 时全部补齐
+	events = nil
+	reader.set(testChain(24)[20:]...)
+	if err := f.deliver(context.Background(), reader.headers[24]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := labels(drain(f)), []string{"a21", "a22", "a23", "a24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if wantEvents := []Event{{Kind: EventBackfill, From: 21, To: 23}}; !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("events = %v, want %v", events, wantEvents)
+	}
+}
+
+func TestDeliverRetriesFailedBackfill(t *testing.T) {
+	a := testChain(8)
+	reader := newCanonicalReader(a...)
+	f := newTestFollower(reader, 0, nil)
+
+	if err := f.deliver(context.Background(), a[1]); err != nil { // a2
+		t.Fatal(err)
+	}
+
+	// 补齐到 a5 时失败：已补齐的 a3、a4 照常输出，a6 本身不输出
+	reader.fail[5] = errors.New("node unavailable")
+	if err := f.deliver(context.Background(), a[5]); err == nil || !strings.Contains(err.Error(), "backfill header 5") {
+		t.Fatalf("deliver(a6) error = %v, want backfill failure at 5", err)
+	}
+	if got, want := labels(drain(f)), []string{"a2", "a3", "a4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v before the failure, want %v", got, want)
+	}
+
+	// 下一个区块到达时从失败的高度继续补齐
+	delete(reader.fail, 5)
+	if err := f.deliver(context.Background(), a[7]); err != nil { // a8
+		t.Fatalf("deliver(a8) error = %v", err)
+	}
+	if got, want := labels(drain(f)), []string{"a5", "a6", "a7", "a8"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v after recovery, want %v", got, want)
+	}
+}
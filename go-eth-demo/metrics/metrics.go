@@ -0,0 +1,221 @@
+// Package metrics 以 Prometheus 格式导出区块和事件监控的指标
+//
+// Metrics 记录链头高度、链头落后于当前时间的秒数、区块 Gas 使用率分布、链重组、
+// 各合约的事件数和 utils.EthClient 的 RPC 延迟与错误，通过 HTTP /metrics 供 Prometheus 抓取。
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 所有指标名称的前缀
+const namespace = "ethdemo"
+
+// Metrics 监控程序的 Prometheus 指标，方法可以并发调用
+type Metrics struct {
+	registry *prometheus.Registry
+
+	mu         sync.Mutex
+	headNumber uint64    // 已记录的最高区块号
+	headTime   time.Time // 最新区块的时间戳，用于计算落后时间
+
+	headHeight       prometheus.Gauge
+	blocks           prometheus.Counter
+	transactions     prometheus.Counter
+	gasUsed          prometheus.Counter
+	gasUtilization   prometheus.Histogram
+	blockInterval    prometheus.Histogram
+	avgBlockInterval prometheus.Gauge
+	reorgs           prometheus.Counter
+	revertedBlocks   prometheus.Counter
+	events           *prometheus.CounterVec
+	alerts           *prometheus.CounterVec
+	rpcDuration      *prometheus.HistogramVec
+	rpcErrors        *prometheus.CounterVec
+}
+
+// New 创建指标并注册到独立的 Registry，同时包含 Go 运行时和进程指标
+func New() *Metrics {
+	m := &Metrics{registry: prometheus.NewRegistry()}
+
+	m.headHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Name: "head_block_number",
+		Help: "Number of the latest processed block.",
+	})
+	m.blocks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "blocks_processed_total",
+		Help: "Blocks processed, including blocks later reverted by a reorg.",
+	})
+	m.transactions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "transactions_total",
+		Help: "Transactions in processed blocks.",
+	})
+	m.gasUsed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "gas_used_total",
+		Help: "Gas used by processed blocks.",
+	})
+	m.gasUtilization = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "block_gas_utilization_ratio",
+		Help:    "Block gas used divided by gas limit.",
+		Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1},
+	})
+	m.blockInterval = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "block_interval_seconds",
+		Help:    "Time between consecutive block timestamps.",
+		Buckets: []float64{1, 2, 4, 8, 12, 15, 20, 30, 60, 120},
+	})
+	m.avgBlockInterval = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Name: "block_interval_average_seconds",
+		Help: "Average block interval over the monitor's recent block history.",
+	})
+	m.reorgs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "reorgs_total",
+		Help: "Chain reorganizations detected.",
+	})
+	m.revertedBlocks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "reverted_blocks_total",
+		Help: "Blocks removed from the canonical chain by reorgs.",
+	})
+	m.events = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "contract_events_total",
+		Help: "Contract events received, by contract address and event name.",
+	}, []string{"contract", "event"})
+	m.alerts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "alerts_total",
+		Help: "Alerts raised, by rule and severity.",
+	}, []string{"rule", "severity"})
+	m.rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "rpc_request_duration_seconds",
+		Help:    "Latency of RPC requests per endpoint, including failed requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint"})
+	m.rpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "rpc_errors_total",
+		Help: "Failed RPC requests per endpoint; kind is retryable (transport, rate limit) or permanent.",
+	}, []string{"endpoint", "kind"})
+
+	headLag := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace, Name: "head_block_lag_seconds",
+		Help: "Wall clock time minus the latest block timestamp.",
+	}, m.headLag)
+
+	m.registry.MustRegister(
+		m.headHeight, headLag, m.blocks, m.transactions, m.gasUsed,
+		m.gasUtilization, m.blockInterval, m.avgBlockInterval,
+		m.reorgs, m.revertedBlocks, m.events, m.alerts,
+		m.rpcDuration, m.rpcErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Registry 返回指标所在的 Registry，可用于注册自定义指标
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveBlock 记录一个新处理的区块，interval 为与上一个区块的时间间隔，0 表示未知
+// 链头高度和时间只会前进，补齐的旧区块和重组到较短分支都不会使其回退
+func (m *Metrics) ObserveBlock(header *types.Header, txCount int, interval time.Duration) {
+	m.mu.Lock()
+	if number := header.Number.Uint64(); number >= m.headNumber {
+		m.headNumber = number
+		m.headHeight.Set(float64(number))
+	}
+	blockTime := time.Unix(int64(header.Time), 0)
+	if blockTime.After(m.headTime) {
+		m.headTime = blockTime
+	}
+	m.mu.Unlock()
+
+	m.blocks.Inc()
+	m.transactions.Add(float64(txCount))
+	m.gasUsed.Add(float64(header.GasUsed))
+	if header.GasLimit > 0 {
+		m.gasUtilization.Observe(float64(header.GasUsed) / float64(header.GasLimit))
+	}
+	if interval > 0 {
+		m.blockInterval.Observe(interval.Seconds())
+	}
+}
+
+// SetAverageBlockInterval 设置监控程序统计的平均出块间隔
+func (m *Metrics) SetAverageBlockInterval(d time.Duration) {
+	m.avgBlockInterval.Set(d.Seconds())
+}
+
+// ObserveReorg 记录一次深度为 depth 的链重组
+func (m *Metrics) ObserveReorg(depth int) {
+	m.reorgs.Inc()
+	m.revertedBlocks.Add(float64(depth))
+}
+
+// ObserveEvent 记录收到的合约事件
+func (m *Metrics) ObserveEvent(contract common.Address, event string) {
+	m.events.WithLabelValues(strings.ToLower(contract.Hex()), event).Inc()
+}
+
+// ObserveAlert 记录触发的告警
+func (m *Metrics) ObserveAlert(rule, severity string) {
+	m.alerts.WithLabelValues(rule, severity).Inc()
+}
+
+// ObserveRPC 记录一次 RPC 请求，签名与 utils.RPCObserver 一致，可直接传给 EthClient.SetRPCObserver
+func (m *Metrics) ObserveRPC(endpoint string, duration time.Duration, err error) {
+	m.rpcDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+	if err == nil {
+		return
+	}
+	kind := "permanent"
+	if utils.IsRetryableError(err) {
+		kind = "retryable"
+	}
+	m.rpcErrors.WithLabelValues(endpoint, kind).Inc()
+}
+
+// headLag 链头落后于当前时间的秒数，尚未收到区块时为 0
+func (m *Metrics) headLag() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.headTime.IsZero() {
+		return 0
+	}
+	return time.Since(m.headTime).Seconds()
+}
+
+// Handler 返回以 Prometheus 文本格式输出指标的 HTTP 处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve 在 addr 上提供 /metrics，直到 ctx 结束后关闭服务并返回 nil；监听失败时返回错误
+func (m *Metrics) Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Package output 将查询结果渲染为文本、JSON、NDJSON 或 CSV
+//
+// 每种结果实现 Record 接口，按固定顺序返回字段。字段的 Key 是稳定的机器可读名称
+// (snake_case)，在 JSON、NDJSON 和 CSV 中保持一致；Label 和 Text 只用于文本格式。
+// 大整数 (*big.Int) 在 JSON 和 CSV 中输出为十进制字符串，避免精度丢失。
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Format 输出格式
+type Format string
+
+const (
+	FormatText   Format = "text"   // 对齐的 "名称: 值" 文本，供人阅读
+	FormatJSON   Format = "json"   // 单条结果为对象，列表为数组
+	FormatNDJSON Format = "ndjson" // 每行一个 JSON 对象
+	FormatCSV    Format = "csv"    // 首行为字段名
+)
+
+// Formats 返回支持的格式名称
+func Formats() []string {
+	return []string{string(FormatText), string(FormatJSON), string(FormatNDJSON), string(FormatCSV)}
+}
+
+// ParseFormat 解析格式名称，空字符串表示文本格式
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatJSON, FormatNDJSON, FormatCSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (supported: %s)", name, strings.Join(Formats(), ", "))
+}
+
+// Field 结果中的一个字段
+type Field struct {
+	Key   string      // 稳定的机器可读名称，为空时只在文本格式中输出
+	Label string      // 文本格式中显示的名称，为空时不在文本格式中输出
+	Value interface{} // 字段值，nil 或空指针在 JSON 中输出为 null，在文本中省略
+	Text  string      // 文本格式中显示的值，为空时使用 Value
+}
+
+// Record 可以输出的查询结果
+type Record interface {
+	Fields() []Field
+}
+
+// Render 输出单条结果，JSON 格式输出为对象
+func Render(w io.Writer, format Format, record Record) error {
+	if format == FormatJSON {
+		return writeJSON(w, dataFields(record))
+	}
+	return RenderList(w, format, []Record{record})
+}
+
+// RenderList 输出多条结果，JSON 格式输出为数组 (没有结果时为空数组)
+func RenderList(w io.Writer, format Format, records []Record) error {
+	switch format {
+	case FormatJSON:
+		list := make([]orderedRecord, len(records))
+		for i, r := range records {
+			list[i] = dataFields(r)
+		}
+		return writeJSON(w, list)
+
+	case FormatText, FormatNDJSON, FormatCSV:
+		s := NewStream(w, format)
+		for _, r := range records {
+			if err := s.Write(r); err != nil {
+				return err
+			}
+		}
+		return s.Flush()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// Stream 逐条输出结果，用于订阅等持续产生结果的场景
+// JSON 格式下每条结果输出为单独一行的对象，与 NDJSON 相同
+type Stream struct {
+	w       io.Writer
+	format  Format
+	count   int
+	csv     *csv.Writer
+	columns []string
+}
+
+// NewStream 创建输出流
+func NewStream(w io.Writer, format Format) *Stream {
+	s := &Stream{w: w, format: format}
+	if format == FormatCSV {
+		s.csv = csv.NewWriter(w)
+	}
+	return s
+}
+
+// Write 输出一条结果，文本格式下结果之间空一行
+// CSV 格式以第一条结果的字段作为表头，之后结果中缺少的字段留空
+func (s *Stream) Write(record Record) error {
+	defer func() { s.count++ }()
+
+	if s.format == FormatText {
+		if s.count > 0 {
+			if _, err := fmt.Fprintln(s.w); err != nil {
+				return err
+			}
+		}
+		return writeText(s.w, record.Fields())
+	}
+
+	fields := dataFields(record)
+	switch s.format {
+	case FormatJSON, FormatNDJSON:
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+		return err
+
+	case FormatCSV:
+		if s.columns == nil {
+			for _, f := range fields {
+				s.columns = append(s.columns, f.Key)
+			}
+			if err := s.csv.Write(s.columns); err != nil {
+				return err
+			}
+		}
+		values := make(map[string]string, len(fields))
+		for _, f := range fields {
+			values[f.Key] = csvValue(f.Value)
+		}
+		row := make([]string, len(s.columns))
+		for i, key := range s.columns {
+			row[i] = values[key]
+		}
+		if err := s.csv.Write(row); err != nil {
+			return err
+		}
+		// 订阅等场景需要每条结果立即可见
+		s.csv.Flush()
+		return s.csv.Error()
+	}
+	return fmt.Errorf("unknown output format %q", s.format)
+}
+
+// Flush 刷新缓冲的输出
+func (s *Stream) Flush() error {
+	if s.csv != nil {
+		s.csv.Flush()
+		return s.csv.Error()
+	}
+	return nil
+}
+
+// writeText 以 "名称: 值" 的对齐格式输出字段，省略空值
+func writeText(w io.Writer, fields []Field) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		if f.Label == "" {
+			continue
+		}
+		text := f.Text
+		if text == "" {
+			value := normalize(f.Value)
+			if value == nil {
+				continue
+			}
+			text = fmt.Sprint(value)
+		}
+		fmt.Fprintf(tw, "%s:\t%s\n", f.Label, text)
+	}
+	return tw.Flush()
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// orderedRecord 按字段顺序序列化为 JSON 对象
+type orderedRecord []Field
+
+// dataFields 返回结果中有 Key 的字段，即 JSON 和 CSV 中输出的字段
+func dataFields(record Record) orderedRecord {
+	var fields orderedRecord
+	for _, f := range record.Fields() {
+		if f.Key != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(normalize(f.Value))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Key, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// normalize 将字段值转换为稳定的输出形式
+// 大整数转为十进制字符串，时间转为 RFC3339，字节、地址和哈希转为 0x 十六进制，空指针和空 map 转为 nil
+func normalize(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		if value == nil {
+			return nil
+		}
+		return value.String()
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case []byte:
+		return hexutil.Encode(value)
+	case common.Address:
+		return value.Hex()
+	case common.Hash:
+		return value.Hex()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+	return v
+}
+
+// csvValue 将字段值转换为 CSV 单元格，嵌套的值编码为 JSON
+func csvValue(v interface{}) string {
+	switch value := normalize(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,327 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/utils"
+)
+
+// Block 区块摘要，来自区块头时 Transactions 和 Size 为 nil
+type Block struct {
+	Number       uint64
+	Hash         common.Hash
+	ParentHash   common.Hash
+	Timestamp    uint64
+	Miner        common.Address
+	Transactions *int
+	GasUsed      uint64
+	GasLimit     uint64
+	BaseFee      *big.Int
+	Size         *uint64
+}
+
+// NewBlock 从区块创建摘要
+func NewBlock(block *types.Block) *Block {
+	b := NewHeader(block.Header())
+	txCount := len(block.Transactions())
+	size := block.Size()
+	b.Transactions = &txCount
+	b.Size = &size
+	return b
+}
+
+// NewHeader 从区块头创建摘要
+func NewHeader(header *types.Header) *Block {
+	return &Block{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Timestamp:  header.Time,
+		Miner:      header.Coinbase,
+		GasUsed:    header.GasUsed,
+		GasLimit:   header.GasLimit,
+		BaseFee:    header.BaseFee,
+	}
+}
+
+// Fields 实现 Record
+func (b *Block) Fields() []Field {
+	return []Field{
+		{Key: "number", Label: "区块号", Value: b.Number},
+		{Key: "hash", Label: "哈希", Value: b.Hash},
+		{Key: "parent_hash", Label: "父哈希", Value: b.ParentHash},
+		{Key: "timestamp", Label: "时间", Value: b.Timestamp, Text: formatTimestamp(b.Timestamp)},
+		{Key: "miner", Label: "矿工", Value: b.Miner},
+		{Key: "transactions", Label: "交易数", Value: b.Transactions},
+		{Key: "gas_used", Label: "Gas 使用", Value: b.GasUsed,
+			Text: fmt.Sprintf("%s / %s", utils.FormatNumber(b.GasUsed), utils.FormatNumber(b.GasLimit))},
+		{Key: "gas_limit", Value: b.GasLimit},
+		{Key: "base_fee_per_gas", Label: "基础费用", Value: b.BaseFee, Text: gweiText(b.BaseFee)},
+		{Key: "size", Label: "区块大小", Value: b.Size, Text: bytesText(b.Size)},
+	}
+}
+
+// Transaction 交易详情
+type Transaction struct {
+	Hash      common.Hash
+	Type      uint8
+	ChainID   *big.Int
+	From      *common.Address // 无法恢复签名者时为 nil
+	To        *common.Address // 合约创建时为 nil
+	Value     *big.Int
+	Nonce     uint64
+	Gas       uint64
+	GasPrice  *big.Int // 传统交易和 EIP-2930 交易
+	GasFeeCap *big.Int // EIP-1559 及之后的交易
+	GasTipCap *big.Int
+	DataSize  int
+	Pending   bool
+}
+
+// NewTransaction 从交易创建详情，from 为 nil 时从签名中恢复发送方
+func NewTransaction(tx *types.Transaction, from *common.Address, pending bool) *Transaction {
+	t := &Transaction{
+		Hash:     tx.Hash(),
+		Type:     tx.Type(),
+		ChainID:  tx.ChainId(),
+		From:     from,
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Nonce:    tx.Nonce(),
+		Gas:      tx.Gas(),
+		DataSize: len(tx.Data()),
+		Pending:  pending,
+	}
+	if t.From == nil {
+		if sender, err := utils.GetTransactionSender(tx); err == nil {
+			t.From = &sender
+		}
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		t.GasPrice = tx.GasPrice()
+	} else {
+		t.GasFeeCap = tx.GasFeeCap()
+		t.GasTipCap = tx.GasTipCap()
+	}
+	return t
+}
+
+// Fields 实现 Record
+func (t *Transaction) Fields() []Field {
+	to := ""
+	if t.To == nil {
+		to = "(合约创建)"
+	}
+	return []Field{
+		{Key: "hash", Label: "交易哈希", Value: t.Hash},
+		{Key: "type", Label: "类型", Value: t.Type},
+		{Key: "chain_id", Label: "链 ID", Value: t.ChainID},
+		{Key: "from", Label: "发送方", Value: t.From},
+		{Key: "to", Label: "接收方", Value: t.To, Text: to},
+		{Key: "value", Label: "金额", Value: t.Value, Text: etherText(t.Value)},
+		{Key: "nonce", Label: "Nonce", Value: t.Nonce},
+		{Key: "gas", Label: "Gas 限制", Value: t.Gas, Text: utils.FormatNumber(t.Gas)},
+		{Key: "gas_price", Label: "Gas 价格", Value: t.GasPrice, Text: gweiText(t.GasPrice)},
+		{Key: "max_fee_per_gas", Label: "最高费用", Value: t.GasFeeCap, Text: gweiText(t.GasFeeCap)},
+		{Key: "max_priority_fee_per_gas", Label: "小费", Value: t.GasTipCap, Text: gweiText(t.GasTipCap)},
+		{Key: "data_size", Label: "数据长度", Value: t.DataSize, Text: fmt.Sprintf("%d bytes", t.DataSize)},
+		{Key: "pending", Label: "待处理", Value: t.Pending},
+	}
+}
+
+// Receipt 交易收据
+type Receipt struct {
+	TxHash            common.Hash
+	Status            uint64
+	BlockNumber       uint64
+	BlockHash         common.Hash
+	TransactionIndex  uint
+	GasUsed           uint64
+	CumulativeGasUsed uint64
+	EffectiveGasPrice *big.Int
+	ContractAddress   *common.Address // 非合约创建交易为 nil
+	Logs              int
+}
+
+// NewReceipt 从收据创建记录
+func NewReceipt(receipt *types.Receipt) *Receipt {
+	r := &Receipt{
+		TxHash:            receipt.TxHash,
+		Status:            receipt.Status,
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		BlockHash:         receipt.BlockHash,
+		TransactionIndex:  receipt.TransactionIndex,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+		Logs:              len(receipt.Logs),
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		address := receipt.ContractAddress
+		r.ContractAddress = &address
+	}
+	return r
+}
+
+// Fields 实现 Record，status 为 "success" 或 "failed"
+func (r *Receipt) Fields() []Field {
+	status := "success"
+	if r.Status != types.ReceiptStatusSuccessful {
+		status = "failed"
+	}
+	return []Field{
+		{Key: "transaction_hash", Label: "交易哈希", Value: r.TxHash},
+		{Key: "status", Label: "状态", Value: status},
+		{Key: "block_number", Label: "区块号", Value: r.BlockNumber},
+		{Key: "block_hash", Label: "区块哈希", Value: r.BlockHash},
+		{Key: "transaction_index", Label: "交易索引", Value: r.TransactionIndex},
+		{Key: "gas_used", Label: "Gas 使用", Value: r.GasUsed, Text: utils.FormatNumber(r.GasUsed)},
+		{Key: "cumulative_gas_used", Label: "累计 Gas 使用", Value: r.CumulativeGasUsed, Text: utils.FormatNumber(r.CumulativeGasUsed)},
+		{Key: "effective_gas_price", Label: "实际 Gas 价格", Value: r.EffectiveGasPrice, Text: gweiText(r.EffectiveGasPrice)},
+		{Key: "contract_address", Label: "合约地址", Value: r.ContractAddress},
+		{Key: "logs", Label: "日志数", Value: r.Logs},
+	}
+}
+
+// Balance ETH 余额
+type Balance struct {
+	Address common.Address
+	Block   *big.Int // 最新区块时为 nil
+	Wei     *big.Int
+}
+
+// Fields 实现 Record，block 为 nil 表示最新区块
+func (b *Balance) Fields() []Field {
+	block := ""
+	if b.Block == nil {
+		block = "latest"
+	}
+	return []Field{
+		{Key: "address", Label: "地址", Value: b.Address},
+		{Key: "block", Label: "区块", Value: b.Block, Text: block},
+		{Key: "balance", Label: "余额", Value: b.Wei, Text: etherText(b.Wei)},
+	}
+}
+
+// TokenBalance ERC20 代币余额
+type TokenBalance struct {
+	Token    common.Address
+	Symbol   string
+	Decimals uint8
+	Address  common.Address
+	Raw      *big.Int
+}
+
+// Fields 实现 Record，balance 为最小单位的整数
+func (b *TokenBalance) Fields() []Field {
+	return []Field{
+		{Key: "token", Label: "代币", Value: b.Token, Text: fmt.Sprintf("%s (%s)", b.Symbol, b.Token.Hex())},
+		{Key: "symbol", Value: b.Symbol},
+		{Key: "decimals", Label: "小数位", Value: b.Decimals},
+		{Key: "address", Label: "地址", Value: b.Address},
+		{Key: "balance", Label: "余额", Value: b.Raw, Text: utils.FormatUnits(b.Raw, b.Decimals) + " " + b.Symbol},
+	}
+}
+
+// Log 事件日志，Event 和 Args 在提供 ABI 且解码成功时才有值
+type Log struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	LogIndex    uint
+	Address     common.Address
+	Topics      []common.Hash
+	Data        []byte
+	Removed     bool
+	Event       string
+	Args        map[string]string
+}
+
+// NewLog 从日志创建记录
+func NewLog(log *types.Log) *Log {
+	return &Log{
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		Address:     log.Address,
+		Topics:      log.Topics,
+		Data:        log.Data,
+		Removed:     log.Removed,
+	}
+}
+
+// Fields 实现 Record，topics 为十六进制字符串数组，args 为参数名到值的映射
+func (l *Log) Fields() []Field {
+	topics := make([]string, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = topic.Hex()
+	}
+
+	fields := []Field{
+		{Key: "block_number", Label: "区块号", Value: l.BlockNumber},
+		{Key: "block_hash", Value: l.BlockHash},
+		{Key: "transaction_hash", Label: "交易哈希", Value: l.TxHash},
+		{Key: "log_index", Label: "日志索引", Value: l.LogIndex},
+		{Key: "address", Label: "合约", Value: l.Address},
+		{Key: "event", Label: "事件", Value: nilIfEmpty(l.Event)},
+		{Key: "topics", Value: topics},
+		{Key: "data", Value: l.Data},
+		{Key: "args", Value: l.Args},
+		{Key: "removed", Value: l.Removed},
+	}
+
+	// 文本格式下展开解码后的参数，无法解码时展开 topics
+	if l.Args != nil {
+		for _, name := range slices.Sorted(maps.Keys(l.Args)) {
+			fields = append(fields, Field{Label: "  " + name, Text: l.Args[name]})
+		}
+	} else {
+		for i, topic := range topics {
+			fields = append(fields, Field{Label: fmt.Sprintf("Topic %d", i), Text: topic})
+		}
+	}
+	if l.Removed {
+		fields = append(fields, Field{Label: "已移除", Text: "是 (链重组)"})
+	}
+	return fields
+}
+
+// formatTimestamp 将 Unix 时间戳格式化为 UTC 时间
+func formatTimestamp(ts uint64) string {
+	return time.Unix(int64(ts), 0).UTC().Format(time.RFC3339)
+}
+
+func etherText(wei *big.Int) string {
+	if wei == nil {
+		return ""
+	}
+	return utils.WeiToEther(wei) + " ETH"
+}
+
+func gweiText(wei *big.Int) string {
+	if wei == nil {
+		return ""
+	}
+	return utils.WeiToGwei(wei) + " Gwei"
+}
+
+func bytesText(size *uint64) string {
+	if size == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d bytes", *size)
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
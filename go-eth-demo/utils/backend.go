@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 以下方法与 ethclient.Client 的同名方法签名一致，但会在多个节点之间自动故障转移
+// EthClient 因此同时满足 NonceSource、TxWaitBackend 和 CallBackend 接口
+
+var (
+	_ NonceSource   = (*EthClient)(nil)
+	_ TxWaitBackend = (*EthClient)(nil)
+	_ CallBackend   = (*EthClient)(nil)
+)
+
+// BlockNumber 获取最新区块号
+func (ec *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+// BlockByNumber 按区块号获取区块，number 为 nil 时返回最新区块
+func (ec *EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*types.Block, error) {
+		return client.BlockByNumber(ctx, number)
+	})
+}
+
+// BlockByHash 按哈希获取区块
+func (ec *EthClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*types.Block, error) {
+		return client.BlockByHash(ctx, hash)
+	})
+}
+
+// HeaderByNumber 按区块号获取区块头，number 为 nil 时返回最新区块头
+func (ec *EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	})
+}
+
+// HeaderByHash 按哈希获取区块头
+func (ec *EthClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByHash(ctx, hash)
+	})
+}
+
+// BalanceAt 获取账户在指定区块的余额
+func (ec *EthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+// NonceAt 获取账户在指定区块的 nonce
+func (ec *EthClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+// PendingNonceAt 获取账户包含待处理交易的 nonce
+func (ec *EthClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+// CodeAt 获取地址在指定区块的合约代码
+func (ec *EthClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+// CallContract 执行只读合约调用
+func (ec *EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+// EstimateGas 估算交易所需的 Gas
+func (ec *EthClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, msg)
+	})
+}
+
+// FilterLogs 按过滤条件查询日志
+func (ec *EthClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+// TransactionByHash 按哈希获取交易
+func (ec *EthClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	r, err := callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (result, error) {
+		tx, isPending, err := client.TransactionByHash(ctx, hash)
+		return result{tx, isPending}, err
+	})
+	return r.tx, r.isPending, err
+}
+
+// TransactionReceipt 获取交易收据
+func (ec *EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+// SuggestGasPrice 获取建议的 gas 价格
+func (ec *EthClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+// SuggestGasTipCap 获取建议的 EIP-1559 小费
+func (ec *EthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+// SendTransaction 广播已签名交易
+// 前一次尝试超时或连接断开时交易可能已进入交易池，故障转移或重试时节点会返回 "already known"，
+// 这种情况视为发送成功。首次尝试就返回 "already known" 时仍作为错误返回
+func (ec *EthClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	resend := false
+	return ec.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		err := client.SendTransaction(ctx, tx)
+		if resend && isAlreadyKnown(err) {
+			return nil
+		}
+		resend = true
+		return err
+	})
+}
+
+// isAlreadyKnown 判断节点是否因交易池中已有同一笔交易而拒绝
+func isAlreadyKnown(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// rpcRequest 假节点收到的 JSON-RPC 请求
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// rpcHandler 根据请求方法返回结果或 JSON-RPC 错误信息
+type rpcHandler func(w http.ResponseWriter, method string) (result any, errMsg string)
+
+// newRPCServer 启动一个假 JSON-RPC 节点，handler 已写入响应时返回值被忽略
+func newRPCServer(t *testing.T, handler rpcHandler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid JSON-RPC request: %v", err)
+			return
+		}
+
+		rw := &trackingWriter{ResponseWriter: w}
+		result, errMsg := handler(rw, req.Method)
+		if rw.written {
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if errMsg != "" {
+			resp["error"] = map[string]any{"code": -32000, "message": errMsg}
+		} else {
+			resp["result"] = result
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// trackingWriter 记录 handler 是否已自行写入响应
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap 供 http.ResponseController 接管连接，接管后不再写入响应
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	w.written = true
+	return w.ResponseWriter
+}
+
+// newTestClient 直接连接给定节点创建 EthClient，按顺序路由且不重试，节点视为已通过链 ID 校验
+func newTestClient(t *testing.T, urls ...string) *EthClient {
+	t.Helper()
+	chainID := big.NewInt(1337)
+	ec := &EthClient{retry: RetryPolicy{MaxAttempts: 1}, chainID: chainID}
+	for _, url := range urls {
+		ep, err := dialEndpoint(context.Background(), url)
+		if err != nil {
+			t.Fatalf("dialEndpoint(%s) error = %v", url, err)
+		}
+		if err := ep.setChainID(chainID, chainID); err != nil {
+			t.Fatal(err)
+		}
+		ec.endpoints = append(ec.endpoints, ep)
+	}
+	t.Cleanup(ec.Close)
+	return ec
+}
+
+// testTransaction 测试使用的已签名交易
+func testTransaction(t *testing.T) *types.Transaction {
+	t.Helper()
+	signer, err := NewPrivateKeySigner("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x742d35Cc6634C0532925a3b8D0C9e3e0C8b0e4c2")
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e9)})
+	signed, err := signer.SignTx(tx, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestSendTransactionResendAlreadyKnown(t *testing.T) {
+	tests := []struct {
+		name  string
+		first rpcHandler
+	}{
+		{
+			name: "gateway timeout",
+			first: func(w http.ResponseWriter, method string) (any, string) {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return nil, ""
+			},
+		},
+		{
+			name: "connection dropped",
+			first: func(w http.ResponseWriter, method string) (any, string) {
+				conn, _, err := http.NewResponseController(w).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+				return nil, ""
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firstCalls, secondCalls atomic.Int32
+			first := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+				firstCalls.Add(1)
+				return tt.first(w, method)
+			})
+			second := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+				secondCalls.Add(1)
+				return nil, "already known"
+			})
+
+			ec := newTestClient(t, first.URL, second.URL)
+			if err := ec.SendTransaction(context.Background(), testTransaction(t)); err != nil {
+				t.Fatalf("SendTransaction() error = %v, want nil for already known resend", err)
+			}
+			if firstCalls.Load() != 1 || secondCalls.Load() != 1 {
+				t.Errorf("calls = %d, %d; want 1 on each endpoint", firstCalls.Load(), secondCalls.Load())
+			}
+		})
+	}
+}
+
+func TestSendTransactionFirstAttemptAlreadyKnown(t *testing.T) {
+	server := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		return nil, "known transaction: 0xabc"
+	})
+
+	ec := newTestClient(t, server.URL)
+	err := ec.SendTransaction(context.Background(), testTransaction(t))
+	if err == nil || !strings.Contains(err.Error(), "known transaction") {
+		t.Errorf("SendTransaction() error = %v, want known transaction error", err)
+	}
+}
+
+func TestSendTransactionResendOtherError(t *testing.T) {
+	first := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+		return nil, ""
+	})
+	second := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		return nil, "insufficient funds for gas * price + value"
+	})
+
+	ec := newTestClient(t, first.URL, second.URL)
+	err := ec.SendTransaction(context.Background(), testTransaction(t))
+	if err == nil || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Errorf("SendTransaction() error = %v, want insufficient funds", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -11,49 +13,242 @@ import (
 )
 
 // EthClient 封装以太坊客户端
+// 支持配置多个 RPC 节点：请求路由到最健康的节点，遇到传输层错误时自动切换到下一个节点
+// 所有经过 EthClient 的请求都受令牌桶限流，可重试的错误按 RetryPolicy 退避重试
 type EthClient struct {
-	client  *ethclient.Client // 底层 go-ethereum 客户端
-	config  *config.Config    // 配置信息
-	timeout time.Duration     // 操作超时时间
+	endpoints []*endpoint    // 全部 RPC 节点，主节点在前
+	config    *config.Config // 配置信息
+	timeout   time.Duration  // 操作超时时间
+	chainID   *big.Int       // 连接时节点返回的链 ID
+	observer  RPCObserver    // 每次节点请求的回调，nil 表示不记录
+
+	policyMu sync.RWMutex
+	retry    RetryPolicy  // 重试策略
+	limiter  *RateLimiter // 客户端限流器，nil 表示不限流
+
+	noncesMu sync.Mutex
+	nonces   *NonceManager // 按需创建的 nonce 管理器
 }
 
+// RPCObserver 接收每次节点请求的端点主机名、耗时和错误，用于导出监控指标
+// 端点只包含主机名和端口，不含路径和查询参数中的 API Key
+// 故障转移和重试时每个节点上的每次尝试都会单独回调
+type RPCObserver func(endpoint string, duration time.Duration, err error)
+
 // NewEthClient 创建新的以太坊客户端
 func NewEthClient(cfg *config.Config) (*EthClient, error) {
-	// 1. 建立底层连接
-	client, err := ethclient.Dial(cfg.EthereumRPCURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	urls := cfg.Endpoints()
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no RPC URL configured")
 	}
 
-	// 2. 创建封装实例
+	// 1. 建立底层连接，单个节点连接失败不影响其他节点
+	retry := DefaultRetryPolicy()
+	if cfg.RPCMaxAttempts > 0 {
+		retry.MaxAttempts = int(cfg.RPCMaxAttempts)
+	}
 	ethClient := &EthClient{
-		client:  client,
 		config:  cfg,
 		timeout: 30 * time.Second, // 默认超时时间
+		retry:   retry,
+		limiter: NewRateLimiter(cfg.RPCRateLimit, int(cfg.RPCRateBurst)),
+	}
+	var dialErr error
+	for _, url := range urls {
+		ep, err := dialEndpoint(context.Background(), url)
+		if err != nil {
+			dialErr = fmt.Errorf("%s: %w", config.MaskURL(url), err)
+			continue
+		}
+		ethClient.endpoints = append(ethClient.endpoints, ep)
+	}
+	if len(ethClient.endpoints) == 0 {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", dialErr)
 	}
 
-	// 3. 验证连接有效性
-	if err := ethClient.ping(); err != nil {
-		client.Close()
+	// 2. 在每个节点上校验链 ID 与配置一致，防止用错误的配置或混入其他网络的节点
+	// 跳过配置校验时以第一个响应的节点为准，所有节点的链 ID 仍需相同
+	var expected *big.Int
+	if !cfg.SkipChainIDCheck {
+		expected = big.NewInt(cfg.ChainID)
+	}
+	chainID, err := ethClient.ping(expected)
+	if err != nil {
+		ethClient.Close()
 		return nil, fmt.Errorf("failed to ping Ethereum client: %w", err)
 	}
+	ethClient.chainID = chainID
 
 	return ethClient, nil
 }
 
-// ping 验证客户端连接
-func (ec *EthClient) ping() error {
-	ctx, cancel := context.WithTimeout(context.Background(), ec.timeout)
-	defer cancel()
+// ping 依次在每个节点上调用 eth_chainId，验证连接、初始化健康评分并校验链 ID
+// expected 为 nil 时以第一个响应节点的链 ID 为准。链 ID 不一致的节点导致启动失败；
+// 暂时无法连接的节点在 callEndpoints 首次使用前再校验，至少需要一个节点通过校验
+func (ec *EthClient) ping(expected *big.Int) (*big.Int, error) {
+	var lastErr error
+	verified := 0
+	for _, ep := range ec.endpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), ec.timeout)
+		err := ec.rateLimiter().Wait(ctx)
+		start := time.Now()
+		var chainID *big.Int
+		if err == nil {
+			chainID, err = ep.client.ChainID(ctx)
+		}
+		cancel()
+
+		if err != nil {
+			ep.recordFailure(err)
+			lastErr = fmt.Errorf("%s: %w", config.MaskURL(ep.url), err)
+			continue
+		}
+		ep.recordSuccess(time.Since(start))
+
+		if expected == nil {
+			expected = chainID
+		}
+		if err := ep.setChainID(expected, chainID); err != nil {
+			return nil, err
+		}
+		verified++
+	}
 
-	// 尝试获取最新区块号来验证连接
-	_, err := ec.client.BlockNumber(ctx)
-	return err
+	if verified == 0 {
+		return nil, lastErr
+	}
+	return expected, nil
 }
 
-// GetClient 返回底层的 ethclient.Client
+// verifyEndpoint 校验启动时未能连接的节点的链 ID，已校验的节点直接返回
+func (ec *EthClient) verifyEndpoint(ctx context.Context, ep *endpoint) error {
+	if ep.verified() {
+		return nil
+	}
+
+	start := time.Now()
+	chainID, err := ep.client.ChainID(ctx)
+	if ec.observer != nil {
+		ec.observer(endpointHost(ep.url), time.Since(start), err)
+	}
+	if err != nil {
+		return err
+	}
+	return ep.setChainID(ec.chainID, chainID)
+}
+
+// Call 在最健康的节点上执行 fn
+// 遇到可重试错误 (传输层错误、HTTP 5xx/429、限流等) 时先切换到下一个节点，所有节点都失败后按重试策略退避重试，
+// 退避时间不少于节点 Retry-After 要求的时间。执行回滚、参数错误等永久错误直接返回
+func (ec *EthClient) Call(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	retry := ec.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		err := ec.callEndpoints(ctx, fn)
+		if err == nil || !IsRetryableError(err) || attempt >= retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay := retry.Backoff(attempt)
+		if wait := ec.retryAfterDelay(); wait > delay {
+			delay = wait
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// callEndpoints 按健康排序依次在各节点上执行 fn，直到成功或遇到永久错误
+func (ec *EthClient) callEndpoints(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	var lastErr error
+	limiter := ec.rateLimiter()
+	for _, ep := range rankEndpoints(ec.endpoints) {
+		if ep.excluded() {
+			continue
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
+
+		// 节点通过链 ID 校验前不发送请求，链 ID 不一致的节点被排除
+		if err := ec.verifyEndpoint(ctx, ep); err != nil {
+			ep.recordFailure(err)
+			if IsRetryableError(err) || lastErr == nil {
+				lastErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		start := time.Now()
+		err := fn(ctx, ep.client)
+		if ec.observer != nil {
+			ec.observer(endpointHost(ep.url), time.Since(start), err)
+		}
+		if !IsRetryableError(err) {
+			// 成功或节点正常返回的永久错误都说明节点可用
+			ep.recordSuccess(time.Since(start))
+			return err
+		}
+
+		ep.recordFailure(err)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+// retryAfterDelay 返回最早可用节点的 Retry-After 剩余等待时间，有节点无需等待时返回 0
+func (ec *EthClient) retryAfterDelay() time.Duration {
+	now := time.Now()
+	var minDelay time.Duration
+	for i, ep := range ec.endpoints {
+		delay := ep.retryAfterDelay(now)
+		if i == 0 || delay < minDelay {
+			minDelay = delay
+		}
+	}
+	return minDelay
+}
+
+// callResult 带返回值的 Call
+func callResult[T any](ec *EthClient, ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) (T, error)) (T, error) {
+	var result T
+	err := ec.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		result, err = fn(ctx, client)
+		return err
+	})
+	return result, err
+}
+
+// GetClient 返回当前最健康且已通过链 ID 校验的节点的底层 ethclient.Client
+// 直接使用返回的客户端不会自动切换节点，需要故障转移时请使用 Call 或 EthClient 上的同名方法
 func (ec *EthClient) GetClient() *ethclient.Client {
-	return ec.client
+	ranked := rankEndpoints(ec.endpoints)
+	for _, ep := range ranked {
+		if ep.verified() {
+			return ep.client
+		}
+	}
+	return ranked[0].client
+}
+
+// EndpointHealth 返回每个 RPC 节点的健康状态，顺序与配置一致
+func (ec *EthClient) EndpointHealth() []EndpointHealth {
+	now := time.Now()
+	health := make([]EndpointHealth, len(ec.endpoints))
+	for i, ep := range ec.endpoints {
+		health[i] = ep.health(now)
+	}
+	return health
 }
 
 // GetConfig 返回配置
@@ -61,10 +256,25 @@ func (ec *EthClient) GetConfig() *config.Config {
 	return ec.config
 }
 
-// Close 关闭客户端连接
+// NonceManager 返回该连接共享的 nonce 管理器，首次调用时根据链 ID 创建
+func (ec *EthClient) NonceManager() (*NonceManager, error) {
+	ec.noncesMu.Lock()
+	defer ec.noncesMu.Unlock()
+
+	if ec.nonces == nil {
+		chainID, err := ec.GetChainID()
+		if err != nil {
+			return nil, err
+		}
+		ec.nonces = NewNonceManager(ec, chainID)
+	}
+	return ec.nonces, nil
+}
+
+// Close 关闭所有节点连接
 func (ec *EthClient) Close() {
-	if ec.client != nil {
-		ec.client.Close()
+	for _, ep := range ec.endpoints {
+		ep.client.Close()
 	}
 }
 
@@ -73,12 +283,54 @@ func (ec *EthClient) SetTimeout(timeout time.Duration) {
 	ec.timeout = timeout
 }
 
+// SetRetryPolicy 设置重试策略，可以在其他协程发起请求时调用，已开始的请求继续使用原策略
+func (ec *EthClient) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	ec.policyMu.Lock()
+	defer ec.policyMu.Unlock()
+	ec.retry = policy
+}
+
+// SetRateLimit 设置客户端限流：每秒最多 rate 个请求，允许 burst 个突发请求，rate 小于等于 0 时不限流
+// 可以在其他协程发起请求时调用，已开始的请求继续使用原限流器
+func (ec *EthClient) SetRateLimit(rate float64, burst int) {
+	limiter := NewRateLimiter(rate, burst)
+
+	ec.policyMu.Lock()
+	defer ec.policyMu.Unlock()
+	ec.limiter = limiter
+}
+
+// retryPolicy 返回当前的重试策略
+func (ec *EthClient) retryPolicy() RetryPolicy {
+	ec.policyMu.RLock()
+	defer ec.policyMu.RUnlock()
+	return ec.retry
+}
+
+// rateLimiter 返回当前的限流器，nil 表示不限流
+func (ec *EthClient) rateLimiter() *RateLimiter {
+	ec.policyMu.RLock()
+	defer ec.policyMu.RUnlock()
+	return ec.limiter
+}
+
+// SetRPCObserver 设置节点请求的回调，需在发起请求前调用，nil 表示不记录
+func (ec *EthClient) SetRPCObserver(observer RPCObserver) {
+	ec.observer = observer
+}
+
 // GetLatestBlockNumber 获取最新区块号
 func (ec *EthClient) GetLatestBlockNumber() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), ec.timeout)
 	defer cancel()
 
-	blockNumber, err := ec.client.BlockNumber(ctx)
+	blockNumber, err := callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get latest block number: %w", err)
 	}
@@ -91,7 +343,9 @@ func (ec *EthClient) GetChainID() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), ec.timeout)
 	defer cancel()
 
-	chainID, err := ec.client.ChainID(ctx)
+	chainID, err := callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.ChainID(ctx)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
@@ -104,7 +358,9 @@ func (ec *EthClient) GetNetworkID() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), ec.timeout)
 	defer cancel()
 
-	networkID, err := ec.client.NetworkID(ctx)
+	networkID, err := callResult(ec, ctx, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.NetworkID(ctx)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network ID: %w", err)
 	}
@@ -113,19 +369,19 @@ func (ec *EthClient) GetNetworkID() (*big.Int, error) {
 }
 
 // VerifyNetwork 验证连接的网络是否与配置匹配
+// NewEthClient 已在连接时校验，该方法用于长时间运行的程序重新确认节点未切换网络
 func (ec *EthClient) VerifyNetwork() error {
 	chainID, err := ec.GetChainID()
 	if err != nil {
 		return err
 	}
 
-	expectedChainID := big.NewInt(ec.config.ChainID)
-	if chainID.Cmp(expectedChainID) != 0 {
-		return fmt.Errorf("chain ID mismatch: expected %s, got %s",
-			expectedChainID.String(), chainID.String())
-	}
+	return CheckChainID(big.NewInt(ec.config.ChainID), chainID)
+}
 
-	return nil
+// GuardSigner 包装 signer，使其只能为已连接节点的链签名交易，主网需配置 ALLOW_MAINNET
+func (ec *EthClient) GuardSigner(signer Signer) Signer {
+	return NewChainGuard(signer, ec.chainID, ec.config.AllowMainnet)
 }
 
 // GetConnectionInfo 获取连接信息摘要
@@ -155,15 +411,17 @@ func (ec *EthClient) GetConnectionInfo() (map[string]interface{}, error) {
 
 	// 添加配置信息
 	info["network_name"] = ec.config.NetworkName
-	info["rpc_url"] = maskRPCURL(ec.config.EthereumRPCURL)
+	info["rpc_url"] = config.MaskURL(rankEndpoints(ec.endpoints)[0].url)
+	info["endpoints"] = ec.EndpointHealth()
 
 	return info, nil
 }
 
-// maskRPCURL 隐藏 RPC URL 中的敏感信息
-func maskRPCURL(url string) string {
-	if len(url) > 50 {
-		return url[:30] + "***" + url[len(url)-10:]
+// endpointHost 返回节点地址的主机名和端口，用于指标标签
+// 与 config.MaskURL 保留的部分相同，只是去掉了协议；无法解析时返回隐藏后的地址
+func endpointHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
 	}
-	return url
+	return config.MaskURL(rawURL)
 }
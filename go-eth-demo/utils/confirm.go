@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxStatus 交易最终状态
+type TxStatus int
+
+const (
+	TxStatusPending   TxStatus = iota // 仍在等待
+	TxStatusConfirmed                 // 已上链且执行成功
+	TxStatusReverted                  // 已上链但执行失败 (status = 0)
+	TxStatusReplaced                  // 同一 nonce 被另一笔交易占用
+	TxStatusDropped                   // 交易从交易池消失且未上链
+)
+
+// String 返回状态名称
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusConfirmed:
+		return "confirmed"
+	case TxStatusReverted:
+		return "reverted"
+	case TxStatusReplaced:
+		return "replaced"
+	case TxStatusDropped:
+		return "dropped"
+	default:
+		return "pending"
+	}
+}
+
+// ErrTxReverted 交易上链但执行失败
+var ErrTxReverted = errors.New("transaction reverted")
+
+// ErrTxReplaced 交易 nonce 被另一笔交易占用
+var ErrTxReplaced = errors.New("transaction replaced")
+
+// ErrTxDropped 交易被节点丢弃
+var ErrTxDropped = errors.New("transaction dropped")
+
+// TxWaitBackend 等待交易确认所需的节点接口，*ethclient.Client 满足该接口
+type TxWaitBackend interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// WaitOptions 等待交易确认的选项
+type WaitOptions struct {
+	Confirmations uint64         // 需要的确认数，0 和 1 都表示上链即可
+	PollInterval  time.Duration  // 轮询间隔，默认 3 秒
+	DroppedAfter  time.Duration  // 节点持续不认识该交易多久后判定为丢弃，默认 2 分钟
+	From          common.Address // 发送方地址，为空时从签名中恢复
+	OnProgress    func(status TxStatus, confirmations uint64)
+}
+
+// TxResult 交易等待结果
+type TxResult struct {
+	TxHash        common.Hash
+	Status        TxStatus
+	Receipt       *types.Receipt // 已上链时的收据 (包括执行失败)
+	Confirmations uint64
+	ReplacedBy    common.Hash // 被替换时占用同一 nonce 的交易哈希，未找到时为空
+}
+
+// Err 将非成功状态转换为错误，成功时返回 nil
+func (r *TxResult) Err() error {
+	switch r.Status {
+	case TxStatusConfirmed:
+		return nil
+	case TxStatusReverted:
+		return fmt.Errorf("%w: %s (block %d)", ErrTxReverted, r.TxHash.Hex(), r.Receipt.BlockNumber.Uint64())
+	case TxStatusReplaced:
+		if r.ReplacedBy != (common.Hash{}) {
+			return fmt.Errorf("%w: %s replaced by %s", ErrTxReplaced, r.TxHash.Hex(), r.ReplacedBy.Hex())
+		}
+		return fmt.Errorf("%w: %s", ErrTxReplaced, r.TxHash.Hex())
+	case TxStatusDropped:
+		return fmt.Errorf("%w: %s", ErrTxDropped, r.TxHash.Hex())
+	default:
+		return fmt.Errorf("transaction %s still pending", r.TxHash.Hex())
+	}
+}
+
+// WaitForTransaction 等待交易上链并达到指定确认数
+// 超时由 ctx 控制；交易被替换、丢弃或执行失败时返回对应状态的结果而不是错误
+func WaitForTransaction(ctx context.Context, backend TxWaitBackend, tx *types.Transaction, opts WaitOptions) (*TxResult, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 3 * time.Second
+	}
+	if opts.DroppedAfter <= 0 {
+		opts.DroppedAfter = 2 * time.Minute
+	}
+	if opts.Confirmations == 0 {
+		opts.Confirmations = 1
+	}
+
+	from := opts.From
+	if from == (common.Address{}) {
+		sender, err := GetTransactionSender(tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover transaction sender: %w", err)
+		}
+		from = sender
+	}
+
+	// 记录开始等待时的区块高度，用于查找替换交易
+	startBlock, err := backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	result := &TxResult{TxHash: tx.Hash(), Status: TxStatusPending}
+	var unknownSince time.Time
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := pollTransaction(ctx, backend, tx, from, startBlock, opts, result, &unknownSince)
+		if err != nil {
+			return nil, err
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(result.Status, result.Confirmations)
+		}
+		if done {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("timed out waiting for transaction %s: %w", tx.Hash().Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollTransaction 执行一次状态检查，返回是否已得到最终结果
+func pollTransaction(ctx context.Context, backend TxWaitBackend, tx *types.Transaction, from common.Address,
+	startBlock uint64, opts WaitOptions, result *TxResult, unknownSince *time.Time) (bool, error) {
+
+	receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+
+	if receipt != nil {
+		*unknownSince = time.Time{}
+
+		head, err := backend.BlockNumber(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get block number: %w", err)
+		}
+
+		// 收据所在区块可能因重组变化，每次都使用最新收据计算确认数
+		result.Receipt = receipt
+		result.Confirmations = 0
+		if mined := receipt.BlockNumber.Uint64(); head >= mined {
+			result.Confirmations = head - mined + 1
+		}
+		if result.Confirmations < opts.Confirmations {
+			return false, nil
+		}
+
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			result.Status = TxStatusConfirmed
+		} else {
+			result.Status = TxStatusReverted
+		}
+		return true, nil
+	}
+
+	// 未找到收据：之前看到的收据可能因重组失效
+	result.Receipt = nil
+	result.Confirmations = 0
+
+	// 已确认的 nonce 超过本交易的 nonce，说明该 nonce 被其他交易占用
+	confirmedNonce, err := backend.NonceAt(ctx, from, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get account nonce: %w", err)
+	}
+	if confirmedNonce > tx.Nonce() {
+		// 再查一次收据，避免 nonce 与收据之间的竞态
+		if receipt, err := backend.TransactionReceipt(ctx, tx.Hash()); err == nil && receipt != nil {
+			return false, nil
+		}
+
+		replacement, err := findReplacement(ctx, backend, from, tx.Nonce(), startBlock)
+		if err != nil {
+			return false, err
+		}
+		result.ReplacedBy = replacement
+		result.Status = TxStatusReplaced
+		return true, nil
+	}
+
+	// 节点不再认识该交易，持续一段时间后判定为丢弃
+	_, _, err = backend.TransactionByHash(ctx, tx.Hash())
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		if unknownSince.IsZero() {
+			*unknownSince = time.Now()
+		} else if time.Since(*unknownSince) >= opts.DroppedAfter {
+			result.Status = TxStatusDropped
+			return true, nil
+		}
+	case err != nil:
+		return false, fmt.Errorf("failed to get transaction: %w", err)
+	default:
+		*unknownSince = time.Time{}
+	}
+
+	return false, nil
+}
+
+// findReplacement 在 [startBlock, 最新区块] 中查找来自同一发送方、使用相同 nonce 的交易
+func findReplacement(ctx context.Context, backend TxWaitBackend, from common.Address, nonce uint64, startBlock uint64) (common.Hash, error) {
+	head, err := backend.BlockNumber(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	for number := startBlock; number <= head; number++ {
+		block, err := backend.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get block %d: %w", number, err)
+		}
+
+		for _, candidate := range block.Transactions() {
+			if candidate.Nonce() != nonce {
+				continue
+			}
+			sender, err := GetTransactionSender(candidate)
+			if err == nil && sender == from {
+				return candidate.Hash(), nil
+			}
+		}
+	}
+
+	return common.Hash{}, nil
+}
@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/local/go-eth-demo/config"
+)
+
+const (
+	// endpointFailureThreshold 连续失败多少次后暂时将端点标记为不健康
+	endpointFailureThreshold = 3
+	// endpointCooldown 不健康端点在多久后重新参与路由
+	endpointCooldown = 30 * time.Second
+	// latencySmoothing 延迟指数移动平均的权重
+	latencySmoothing = 0.3
+)
+
+// EndpointHealth 单个 RPC 端点的健康状态
+type EndpointHealth struct {
+	URL                 string        `json:"url"`
+	Healthy             bool          `json:"healthy"`
+	Latency             time.Duration `json:"latency"`
+	ErrorRate           float64       `json:"error_rate"`
+	Requests            uint64        `json:"requests"`
+	Failures            uint64        `json:"failures"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	LastError           string        `json:"last_error,omitempty"`
+}
+
+// endpoint 一个 RPC 端点及其统计信息
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+
+	mu                  sync.Mutex
+	latency             time.Duration // 成功请求延迟的指数移动平均
+	requests            uint64
+	failures            uint64
+	consecutiveFailures int
+	lastError           string
+	lastFailure         time.Time
+	retryAfter          time.Time // 节点通过 Retry-After 要求的最早重试时间
+	chainID             *big.Int  // 已通过校验的节点链 ID，nil 表示尚未校验
+	mismatch            bool      // 节点链 ID 与客户端不一致，不再参与路由
+}
+
+// dialEndpoint 连接单个 RPC 节点，HTTP 节点会记录响应中的 Retry-After
+func dialEndpoint(ctx context.Context, rawURL string) (*endpoint, error) {
+	ep := &endpoint{url: rawURL}
+	httpClient := &http.Client{Transport: &retryAfterTransport{base: http.DefaultTransport, endpoint: ep}}
+
+	rpcClient, err := rpc.DialOptions(ctx, rawURL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	ep.client = ethclient.NewClient(rpcClient)
+	return ep, nil
+}
+
+// healthy 判断端点当前是否可用：未处于 Retry-After 等待期，且连续失败未超过阈值或已过冷却期
+func (e *endpoint) healthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.mismatch || now.Before(e.retryAfter) {
+		return false
+	}
+	return e.consecutiveFailures < endpointFailureThreshold || now.Sub(e.lastFailure) >= endpointCooldown
+}
+
+// setRetryAfter 记录节点要求的最早重试时间
+func (e *endpoint) setRetryAfter(at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if at.After(e.retryAfter) {
+		e.retryAfter = at
+	}
+}
+
+// verified 判断节点的链 ID 是否已通过校验
+func (e *endpoint) verified() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.chainID != nil
+}
+
+// excluded 判断节点是否因链 ID 不一致被排除
+func (e *endpoint) excluded() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.mismatch
+}
+
+// setChainID 记录节点链 ID 的校验结果，不一致的节点被永久排除
+func (e *endpoint) setChainID(expected, actual *big.Int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := CheckChainID(expected, actual); err != nil {
+		e.mismatch = true
+		return fmt.Errorf("%s: %w", config.MaskURL(e.url), err)
+	}
+	e.chainID = new(big.Int).Set(actual)
+	return nil
+}
+
+// retryAfterDelay 返回距离节点允许重试还需等待的时间
+func (e *endpoint) retryAfterDelay(now time.Time) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if now.Before(e.retryAfter) {
+		return e.retryAfter.Sub(now)
+	}
+	return 0
+}
+
+// score 端点评分，越低越好：平均延迟按错误率放大
+func (e *endpoint) score() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	latency := float64(e.latency)
+	if latency == 0 {
+		latency = float64(time.Second) // 尚无数据的端点按 1 秒估计
+	}
+
+	errorRate := 0.0
+	if e.requests > 0 {
+		errorRate = float64(e.failures) / float64(e.requests)
+	}
+	return latency * (1 + 10*errorRate)
+}
+
+// recordSuccess 记录一次成功请求
+func (e *endpoint) recordSuccess(elapsed time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests++
+	e.consecutiveFailures = 0
+	if e.latency == 0 {
+		e.latency = elapsed
+	} else {
+		e.latency = time.Duration(latencySmoothing*float64(elapsed) + (1-latencySmoothing)*float64(e.latency))
+	}
+}
+
+// recordFailure 记录一次失败 (传输层错误或限流等可重试错误)
+func (e *endpoint) recordFailure(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests++
+	e.failures++
+	e.consecutiveFailures++
+	e.lastError = err.Error()
+	e.lastFailure = time.Now()
+}
+
+// health 返回端点健康状态快照
+func (e *endpoint) health(now time.Time) EndpointHealth {
+	healthy := e.healthy(now)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	errorRate := 0.0
+	if e.requests > 0 {
+		errorRate = float64(e.failures) / float64(e.requests)
+	}
+
+	return EndpointHealth{
+		URL:                 config.MaskURL(e.url),
+		Healthy:             healthy,
+		Latency:             e.latency,
+		ErrorRate:           errorRate,
+		Requests:            e.requests,
+		Failures:            e.failures,
+		ConsecutiveFailures: e.consecutiveFailures,
+		LastError:           e.lastError,
+	}
+}
+
+// rankEndpoints 按健康状态和评分排序端点：健康的在前，评分低的在前
+func rankEndpoints(endpoints []*endpoint) []*endpoint {
+	now := time.Now()
+
+	ranked := make([]*endpoint, len(endpoints))
+	copy(ranked, endpoints)
+
+	healthy := make(map[*endpoint]bool, len(ranked))
+	scores := make(map[*endpoint]float64, len(ranked))
+	for _, e := range ranked {
+		healthy[e] = e.healthy(now)
+		scores[e] = e.score()
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if healthy[ranked[i]] != healthy[ranked[j]] {
+			return healthy[ranked[i]]
+		}
+		return scores[ranked[i]] < scores[ranked[j]]
+	})
+	return ranked
+}
+
+// isTransportError 判断错误是否来自传输层 (连接失败、超时、HTTP 5xx/429)，这类错误应切换端点重试
+// 节点正常返回的 JSON-RPC 错误 (如 execution reverted) 不属于传输错误
+func isTransportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, rpc.ErrClientQuit)
+}
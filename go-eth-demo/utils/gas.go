@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/local/go-eth-demo/config"
+)
+
+// DefaultGasMargin 估算 Gas 时默认增加的余量 (百分比)
+const DefaultGasMargin = 20
+
+// ErrInsufficientFunds 发送方余额不足以支付转账金额和最高手续费
+var ErrInsufficientFunds = errors.New("insufficient funds for value + max fee")
+
+// GasEstimator 估算 Gas 所需的节点接口，ethclient.Client 和 EthClient 都满足
+type GasEstimator interface {
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// EstimateGasLimit 使用 eth_estimateGas 估算 Gas，并在估算值基础上增加 marginPercent 的余量
+// 返回节点的估算值和加上余量后的 Gas 限制
+func EstimateGasLimit(ctx context.Context, backend GasEstimator, msg ethereum.CallMsg, marginPercent uint64) (estimate, limit uint64, err error) {
+	estimate, err = backend.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	return estimate, ApplyGasMargin(estimate, marginPercent), nil
+}
+
+// ApplyGasMargin 在 Gas 估算值基础上增加 marginPercent 的余量，向上取整
+func ApplyGasMargin(gas, marginPercent uint64) uint64 {
+	return gas + (gas*marginPercent+99)/100
+}
+
+// GasMargin 返回配置的 Gas 余量百分比 (GAS_MARGIN_PERCENT)
+func (ec *EthClient) GasMargin() uint64 {
+	return uint64(ec.config.GasMargin)
+}
+
+// EstimateTxCost 估算 msg 的 Gas 限制和费用，并查询发送方余额，用于签名前预览和余额检查
+// msg.Gas 非 0 时直接作为 Gas 限制，否则按估算值加配置的余量；普通转账 (21000) 不加余量
+// 费用按配置的 FEE_MODE 由 SuggestFees 计算
+func (ec *EthClient) EstimateTxCost(ctx context.Context, msg ethereum.CallMsg) (*TxCost, error) {
+	mode, err := config.ParseFeeMode(ec.config.FeeMode)
+	if err != nil {
+		return nil, err
+	}
+	cost, err := SuggestFees(ctx, ec, mode)
+	if err != nil {
+		return nil, err
+	}
+	cost.From, cost.To, cost.Value, cost.GasLimit = msg.From, msg.To, msg.Value, msg.Gas
+
+	if cost.GasLimit == 0 {
+		if cost.GasEstimate, cost.GasLimit, err = EstimateGasLimit(ctx, ec, msg, ec.GasMargin()); err != nil {
+			return nil, err
+		}
+		if cost.GasEstimate == params.TxGas && len(msg.Data) == 0 {
+			cost.GasLimit = cost.GasEstimate
+		}
+	}
+
+	if cost.Balance, err = ec.BalanceAt(ctx, msg.From, nil); err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return cost, nil
+}
+
+// FeeSuggester 获取建议费用所需的节点接口，ethclient.Client 和 EthClient 都满足
+type FeeSuggester interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// SuggestFees 按费用模式从节点获取建议的费用，返回只设置了费用字段的 TxCost
+// auto 在节点支持 EIP-1559 时使用动态费用，否则使用传统 Gas 价格；legacy 始终使用传统 Gas 价格；
+// eip1559 在节点不支持时返回错误。动态费用的最高费用 = 2 × 基础费用 + 小费，可容忍连续几个区块的基础费用上涨
+func SuggestFees(ctx context.Context, backend FeeSuggester, mode config.FeeMode) (*TxCost, error) {
+	if mode != config.FeeModeLegacy {
+		header, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest header: %w", err)
+		}
+
+		if header.BaseFee != nil {
+			tip, err := backend.SuggestGasTipCap(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to suggest tip: %w", err)
+			}
+			feeCap := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+			feeCap.Add(feeCap, tip)
+			return &TxCost{BaseFee: header.BaseFee, GasTipCap: tip, GasFeeCap: feeCap}, nil
+		}
+
+		if mode == config.FeeModeDynamic {
+			return nil, fmt.Errorf("fee mode %s requires EIP-1559 but the latest header has no base fee", mode)
+		}
+	}
+
+	gasPrice, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+	return &TxCost{GasPrice: gasPrice}, nil
+}
+
+// TxCost 发送前的交易费用预览
+// 传统交易只设置 GasPrice；动态费用交易设置 GasFeeCap、GasTipCap 和最新区块的 BaseFee
+type TxCost struct {
+	From        common.Address
+	To          *common.Address // 合约创建时为 nil
+	Value       *big.Int
+	GasEstimate uint64 // 节点估算值，未估算时为 0
+	GasLimit    uint64 // 实际使用的 Gas 限制
+	BaseFee     *big.Int
+	GasPrice    *big.Int
+	GasFeeCap   *big.Int
+	GasTipCap   *big.Int
+	Balance     *big.Int // 发送方当前余额，未查询时为 nil
+}
+
+// Dynamic 是否为 EIP-1559 动态费用交易
+func (c *TxCost) Dynamic() bool {
+	return c.GasFeeCap != nil
+}
+
+// MaxGasPrice 每单位 Gas 最多支付的价格
+func (c *TxCost) MaxGasPrice() *big.Int {
+	if c.Dynamic() {
+		return c.GasFeeCap
+	}
+	return c.GasPrice
+}
+
+// ExpectedGasPrice 按当前基础费用预计的每单位 Gas 价格: min(基础费用 + 小费, 最高费用)
+func (c *TxCost) ExpectedGasPrice() *big.Int {
+	if !c.Dynamic() || c.BaseFee == nil {
+		return c.MaxGasPrice()
+	}
+	price := new(big.Int).Add(c.BaseFee, c.GasTipCap)
+	if price.Cmp(c.GasFeeCap) > 0 {
+		return c.GasFeeCap
+	}
+	return price
+}
+
+// MaxFee 最高手续费 = Gas 限制 × 最高单价，节点按此金额检查余额
+func (c *TxCost) MaxFee() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.GasLimit), c.MaxGasPrice())
+}
+
+// ExpectedFee 预计手续费 = 估算 Gas × 预计单价，未估算时按 Gas 限制计算
+func (c *TxCost) ExpectedFee() *big.Int {
+	gas := c.GasEstimate
+	if gas == 0 {
+		gas = c.GasLimit
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), c.ExpectedGasPrice())
+}
+
+// MaxCost 转账金额加最高手续费
+func (c *TxCost) MaxCost() *big.Int {
+	return new(big.Int).Add(c.value(), c.MaxFee())
+}
+
+// ExpectedCost 转账金额加预计手续费
+func (c *TxCost) ExpectedCost() *big.Int {
+	return new(big.Int).Add(c.value(), c.ExpectedFee())
+}
+
+// CheckBalance 检查余额是否足以支付转账金额和最高手续费，未查询余额时不检查
+func (c *TxCost) CheckBalance() error {
+	if c.Balance == nil {
+		return nil
+	}
+	if c.Balance.Cmp(c.MaxCost()) < 0 {
+		shortfall := new(big.Int).Sub(c.MaxCost(), c.Balance)
+		return fmt.Errorf("%w: balance %s ETH, need %s ETH (short %s ETH)",
+			ErrInsufficientFunds, WeiToEther(c.Balance), WeiToEther(c.MaxCost()), WeiToEther(shortfall))
+	}
+	return nil
+}
+
+// Print 输出签名前供确认的费用预览
+func (c *TxCost) Print(w io.Writer) {
+	fmt.Fprintln(w, "==================== 交易预览 ====================")
+	fmt.Fprintf(w, "发送方: %s\n", c.From.Hex())
+	if c.To != nil {
+		fmt.Fprintf(w, "接收方: %s\n", c.To.Hex())
+	} else {
+		fmt.Fprintln(w, "接收方: (合约创建)")
+	}
+	fmt.Fprintf(w, "转账金额: %s ETH\n", WeiToEther(c.value()))
+	if c.GasEstimate > 0 {
+		fmt.Fprintf(w, "Gas 限制: %s (估算 %s)\n", FormatNumber(c.GasLimit), FormatNumber(c.GasEstimate))
+	} else {
+		fmt.Fprintf(w, "Gas 限制: %s\n", FormatNumber(c.GasLimit))
+	}
+	if c.Dynamic() {
+		if c.BaseFee != nil {
+			fmt.Fprintf(w, "基础费用: %s Gwei\n", WeiToGwei(c.BaseFee))
+		}
+		fmt.Fprintf(w, "最高费用: %s Gwei, 小费: %s Gwei\n", WeiToGwei(c.GasFeeCap), WeiToGwei(c.GasTipCap))
+	} else {
+		fmt.Fprintf(w, "Gas 价格: %s Gwei\n", WeiToGwei(c.GasPrice))
+	}
+	fmt.Fprintf(w, "预计手续费: %s ETH\n", WeiToEther(c.ExpectedFee()))
+	fmt.Fprintf(w, "最高手续费: %s ETH\n", WeiToEther(c.MaxFee()))
+	fmt.Fprintf(w, "最高总花费: %s ETH\n", WeiToEther(c.MaxCost()))
+	if c.Balance != nil {
+		fmt.Fprintf(w, "当前余额: %s ETH\n", WeiToEther(c.Balance))
+	}
+	fmt.Fprintln(w, "================================================")
+}
+
+// NewTx 按预览中的接收方、金额、Gas 限制和费用构建未签名交易
+func (c *TxCost) NewTx(chainID *big.Int, nonce uint64, data []byte) *types.Transaction {
+	if c.Dynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: c.GasTipCap,
+			GasFeeCap: c.GasFeeCap,
+			Gas:       c.GasLimit,
+			To:        c.To,
+			Value:     c.value(),
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: c.GasPrice,
+		Gas:      c.GasLimit,
+		To:       c.To,
+		Value:    c.value(),
+		Data:     data,
+	})
+}
+
+func (c *TxCost) value() *big.Int {
+	if c.Value == nil {
+		return new(big.Int)
+	}
+	return c.Value
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// MainnetChainID 以太坊主网链 ID
+const MainnetChainID = 1
+
+var (
+	// ErrChainIDMismatch 节点的链 ID 与配置或交易的链 ID 不一致
+	ErrChainIDMismatch = errors.New("chain ID mismatch")
+	// ErrMainnetNotAllowed 未显式允许时拒绝在主网签名交易
+	ErrMainnetNotAllowed = errors.New("refusing to sign mainnet transaction: set ALLOW_MAINNET=true to confirm")
+)
+
+// CheckChainID 检查节点返回的链 ID 是否与期望值一致
+func CheckChainID(expected, actual *big.Int) error {
+	if expected.Cmp(actual) != 0 {
+		return fmt.Errorf("%w: expected %s, node reports %s", ErrChainIDMismatch, expected.String(), actual.String())
+	}
+	return nil
+}
+
+// IsMainnet 判断链 ID 是否为以太坊主网
+func IsMainnet(chainID *big.Int) bool {
+	return chainID != nil && chainID.Cmp(big.NewInt(MainnetChainID)) == 0
+}
+
+// AllowMainnetFromEnv 读取 ALLOW_MAINNET 环境变量，用于未使用 config 包的程序
+func AllowMainnetFromEnv() bool {
+	allow, err := strconv.ParseBool(os.Getenv("ALLOW_MAINNET"))
+	return err == nil && allow
+}
+
+// chainGuard 只为已连接节点所在链签名的 Signer 包装
+type chainGuard struct {
+	Signer
+	chainID      *big.Int
+	allowMainnet bool
+}
+
+// NewChainGuard 包装 signer，使其拒绝为 chainID 以外的链签名交易
+// chainID 应为已连接节点返回的链 ID；chainID 为主网且 allowMainnet 为 false 时拒绝所有签名
+// signer 为 nil 时返回 nil
+func NewChainGuard(signer Signer, chainID *big.Int, allowMainnet bool) Signer {
+	if signer == nil {
+		return nil
+	}
+	if guard, ok := signer.(*chainGuard); ok {
+		signer = guard.Signer
+	}
+	return &chainGuard{Signer: signer, chainID: new(big.Int).Set(chainID), allowMainnet: allowMainnet}
+}
+
+// SignTx 检查链 ID 后签名
+func (g *chainGuard) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := CheckChainID(g.chainID, chainID); err != nil {
+		return nil, fmt.Errorf("refusing to sign: %w", err)
+	}
+	// 传统交易签名前不携带链 ID，其余类型的交易链 ID 写在交易体中
+	if tx.Type() != types.LegacyTxType {
+		if err := CheckChainID(g.chainID, tx.ChainId()); err != nil {
+			return nil, fmt.Errorf("refusing to sign: %w", err)
+		}
+	}
+	if IsMainnet(g.chainID) && !g.allowMainnet {
+		return nil, ErrMainnetNotAllowed
+	}
+	return g.Signer.SignTx(tx, chainID)
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceSource 获取账户待处理 nonce 的节点接口，*ethclient.Client 满足该接口
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// nonceKey 以链 ID 和地址区分账户
+type nonceKey struct {
+	chainID string
+	address common.Address
+}
+
+// accountNonces 单个账户的 nonce 分配状态
+type accountNonces struct {
+	next     uint64   // 下一个未分配的 nonce
+	released []uint64 // 已释放、可重新分配的 nonce (升序)
+}
+
+// NonceManager 线程安全的 nonce 分配器
+// 同一账户并发发送交易时按顺序分配 nonce，避免重复 nonce 导致 "nonce too low" 等错误
+type NonceManager struct {
+	mu       sync.Mutex
+	source   NonceSource
+	chainID  *big.Int
+	accounts map[nonceKey]*accountNonces
+}
+
+// NewNonceManager 创建 nonce 管理器，source 必须连接到 chainID 对应的网络
+func NewNonceManager(source NonceSource, chainID *big.Int) *NonceManager {
+	return &NonceManager{
+		source:   source,
+		chainID:  new(big.Int).Set(chainID),
+		accounts: make(map[nonceKey]*accountNonces),
+	}
+}
+
+// ChainID 返回管理器对应的链 ID
+func (m *NonceManager) ChainID() *big.Int {
+	return new(big.Int).Set(m.chainID)
+}
+
+func (m *NonceManager) key(address common.Address) nonceKey {
+	return nonceKey{chainID: m.chainID.String(), address: address}
+}
+
+// Next 为账户分配下一个 nonce，首次使用时从节点同步
+func (m *NonceManager) Next(ctx context.Context, address common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.accounts[m.key(address)]
+	if !ok {
+		pending, err := m.source.PendingNonceAt(ctx, address)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		state = &accountNonces{next: pending}
+		m.accounts[m.key(address)] = state
+	}
+
+	// 优先复用已释放的 nonce，避免出现 nonce 空洞
+	if len(state.released) > 0 {
+		nonce := state.released[0]
+		state.released = state.released[1:]
+		return nonce, nil
+	}
+
+	nonce := state.next
+	state.next++
+	return nonce, nil
+}
+
+// Release 归还未能发送的 nonce (如签名失败或节点拒绝交易)
+func (m *NonceManager) Release(address common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.accounts[m.key(address)]
+	if !ok || nonce >= state.next {
+		return
+	}
+
+	for _, released := range state.released {
+		if released == nonce {
+			return
+		}
+	}
+	state.released = append(state.released, nonce)
+	sort.Slice(state.released, func(i, j int) bool { return state.released[i] < state.released[j] })
+
+	// 释放的是末尾的 nonce 时直接回退计数器
+	for n := len(state.released); n > 0 && state.released[n-1] == state.next-1; n-- {
+		state.next--
+		state.released = state.released[:n-1]
+	}
+}
+
+// Resync 从节点重新同步账户的 nonce，丢弃本地分配状态
+// 与首次 Next 相同，在持有锁时查询节点，同步期间的 Next 等待同步完成后再分配，不会分配出重复的 nonce
+func (m *NonceManager) Resync(ctx context.Context, address common.Address) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := m.source.PendingNonceAt(ctx, address)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	m.accounts[m.key(address)] = &accountNonces{next: pending}
+	return nil
+}
+
+// Reset 清除账户的本地状态，下次分配时重新从节点获取
+func (m *NonceManager) Reset(address common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.accounts, m.key(address))
+}
+
+// HandleSendError 根据发送失败的原因处理已分配的 nonce
+// 只有节点以 JSON-RPC 错误明确拒绝交易时才归还该 nonce；nonce 相关错误，以及超时、
+// 连接断开等无法确定交易是否已进入交易池的传输错误，都从节点重新同步
+// 签名失败时交易没有发出，调用方应直接使用 Release
+func (m *NonceManager) HandleSendError(ctx context.Context, address common.Address, nonce uint64, sendErr error) {
+	var rpcErr rpc.Error
+	if errors.As(sendErr, &rpcErr) && !IsNonceError(sendErr) {
+		m.Release(address, nonce)
+		return
+	}
+	if err := m.Resync(ctx, address); err != nil {
+		m.Reset(address)
+	}
+}
+
+// IsNonceError 判断节点返回的错误是否由 nonce 冲突引起
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"nonce too low",
+		"nonce too high",
+		"replacement transaction underpriced",
+		"already known",
+		"known transaction",
+		"invalid nonce",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var nonceTestAddress = common.HexToAddress("0x742d35Cc6634C0532925a3b8D0C9e3e0C8b0e4c2")
+
+// fakeNonceSource 返回可修改的待处理 nonce，block 非 nil 时每次查询先通知 entered 再等待 block
+type fakeNonceSource struct {
+	mu      sync.Mutex
+	pending uint64
+	err     error
+	calls   int
+
+	entered chan struct{}
+	block   chan struct{}
+}
+
+func (s *fakeNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	s.mu.Lock()
+	s.calls++
+	entered, block := s.entered, s.block
+	s.mu.Unlock()
+
+	if block != nil {
+		entered <- struct{}{}
+		<-block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending, s.err
+}
+
+func (s *fakeNonceSource) set(pending uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending, s.err = pending, err
+}
+
+// rpcTestError 节点返回的 JSON-RPC 错误
+type rpcTestError struct {
+	code int
+	msg  string
+}
+
+func (e rpcTestError) Error() string  { return e.msg }
+func (e rpcTestError) ErrorCode() int { return e.code }
+
+// nextNonces 连续分配 n 个 nonce
+func nextNonces(t *testing.T, m *NonceManager, n int) []uint64 {
+	t.Helper()
+	nonces := make([]uint64, n)
+	for i := range nonces {
+		nonce, err := m.Next(context.Background(), nonceTestAddress)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		nonces[i] = nonce
+	}
+	return nonces
+}
+
+func TestNonceManagerParallelNext(t *testing.T) {
+	source := &fakeNonceSource{pending: 7}
+	m := NewNonceManager(source, big.NewInt(1))
+
+	const n = 100
+	nonces := make([]uint64, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := m.Next(context.Background(), nonceTestAddress)
+			if err != nil {
+				t.Errorf("Next() error = %v", err)
+			}
+			nonces[i] = nonce
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(7+i) {
+			t.Fatalf("sorted nonces[%d] = %d, want %d (nonces not unique and consecutive)", i, nonce, 7+i)
+		}
+	}
+	if source.calls != 1 {
+		t.Errorf("PendingNonceAt called %d times, want 1", source.calls)
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	m := NewNonceManager(&fakeNonceSource{pending: 10}, big.NewInt(1))
+	nextNonces(t, m, 3) // 10, 11, 12
+
+	// 中间的 nonce 被归还后优先复用，之后继续递增
+	m.Release(nonceTestAddress, 11)
+	m.Release(nonceTestAddress, 11)
+	if got := nextNonces(t, m, 2); got[0] != 11 || got[1] != 13 {
+		t.Errorf("after releasing 11: Next() = %v, want [11 13]", got)
+	}
+
+	// 归还末尾的 nonce 时回退计数器，包括与之相连的已归还 nonce
+	m.Release(nonceTestAddress, 12)
+	m.Release(nonceTestAddress, 13)
+	if got := nextNonces(t, m, 1); got[0] != 12 {
+		t.Errorf("after releasing 12 and 13: Next() = %v, want [12]", got)
+	}
+
+	// 未分配过的 nonce 被忽略
+	m.Release(nonceTestAddress, 99)
+	if got := nextNonces(t, m, 1); got[0] != 13 {
+		t.Errorf("after releasing unallocated 99: Next() = %v, want [13]", got)
+	}
+}
+
+func TestNonceManagerHandleSendError(t *testing.T) {
+	tests := []struct {
+		name    string
+		sendErr error
+		want    uint64 // 处理错误后下一个分配的 nonce
+	}{
+		{
+			name:    "rejected by node",
+			sendErr: rpcTestError{code: -32000, msg: "insufficient funds for gas * price + value"},
+			want:    5, // 归还后复用
+		},
+		{
+			name:    "nonce too low",
+			sendErr: rpcTestError{code: -32000, msg: "nonce too low"},
+			want:    20, // 从节点重新同步
+		},
+		{
+			name:    "timeout",
+			sendErr: &url.Error{Op: "Post", URL: "http://node", Err: context.DeadlineExceeded},
+			want:    20,
+		},
+		{
+			name:    "connection reset",
+			sendErr: errors.New("read: connection reset by peer"),
+			want:    20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeNonceSource{pending: 5}
+			m := NewNonceManager(source, big.NewInt(1))
+			nonce := nextNonces(t, m, 1)[0]
+
+			// 节点此时的待处理 nonce 已包含其他交易
+			source.set(20, nil)
+			m.HandleSendError(context.Background(), nonceTestAddress, nonce, tt.sendErr)
+			if got := nextNonces(t, m, 1)[0]; got != tt.want {
+				t.Errorf("Next() after %v = %d, want %d", tt.sendErr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonceManagerHandleSendErrorResyncFails(t *testing.T) {
+	source := &fakeNonceSource{pending: 5}
+	m := NewNonceManager(source, big.NewInt(1))
+	nonce := nextNonces(t, m, 1)[0]
+
+	// 重新同步失败时清除本地状态，下次分配时再从节点获取
+	source.set(0, errors.New("node unavailable"))
+	m.HandleSendError(context.Background(), nonceTestAddress, nonce, context.DeadlineExceeded)
+	if _, err := m.Next(context.Background(), nonceTestAddress); err == nil {
+		t.Fatal("Next() succeeded while the node is unavailable")
+	}
+
+	source.set(6, nil)
+	if got := nextNonces(t, m, 1)[0]; got != 6 {
+		t.Errorf("Next() = %d, want 6", got)
+	}
+}
+
+func TestNonceManagerResyncBlocksNext(t *testing.T) {
+	source := &fakeNonceSource{pending: 5}
+	m := NewNonceManager(source, big.NewInt(1))
+	nextNonces(t, m, 1) // 5
+
+	// Resync 查询节点期间并发调用 Next
+	entered, block := make(chan struct{}), make(chan struct{})
+	source.mu.Lock()
+	source.entered, source.block = entered, block
+	source.mu.Unlock()
+
+	resynced := make(chan error)
+	go func() { resynced <- m.Resync(context.Background(), nonceTestAddress) }()
+	<-entered
+
+	concurrent := make(chan uint64)
+	go func() {
+		nonce, err := m.Next(context.Background(), nonceTestAddress)
+		if err != nil {
+			t.Errorf("Next() error = %v", err)
+		}
+		concurrent <- nonce
+	}()
+	time.Sleep(20 * time.Millisecond) // 让 Next 在同步期间运行
+
+	source.mu.Lock()
+	source.entered, source.block = nil, nil
+	source.mu.Unlock()
+	close(block)
+	if err := <-resynced; err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+
+	seen := map[uint64]bool{<-concurrent: true}
+	for _, nonce := range nextNonces(t, m, 2) {
+		if seen[nonce] {
+			t.Fatalf("nonce %d allocated twice around Resync", nonce)
+		}
+		seen[nonce] = true
+	}
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxRetryAfter 服务端 Retry-After 的上限，避免被异常值阻塞过久
+	maxRetryAfter = time.Minute
+)
+
+// RetryPolicy RPC 调用的重试策略
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数 (含首次)，小于 1 时按 1 处理
+	InitialBackoff time.Duration // 首次重试前的等待时间
+	MaxBackoff     time.Duration // 单次等待时间上限
+	Multiplier     float64       // 每次重试等待时间的增长倍数
+	Jitter         float64       // 随机抖动比例 (0-1)，避免多个客户端同时重试
+}
+
+// DefaultRetryPolicy 返回默认重试策略：最多 3 次，500ms 起步指数退避
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// Backoff 计算第 attempt 次失败后的等待时间 (attempt 从 1 开始)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Multiplier
+		if delay >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	// 在 [delay*(1-jitter), delay] 范围内随机
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// IsRetryableError 判断 RPC 错误是否值得重试
+// 传输层错误、限流和节点临时故障可以重试；执行回滚、参数错误、nonce 冲突等永久错误不重试
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if isTransportError(err) {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.ErrorCode() {
+	case -32005, 429: // 请求超限 (Infura/Alchemy 等服务商使用)
+		return true
+	case -32601, -32602, -32600, -32700, 3: // 方法不存在、参数错误、请求格式错误、执行回滚
+		return false
+	}
+
+	msg := strings.ToLower(rpcErr.Error())
+	for _, pattern := range []string{
+		"rate limit",
+		"too many requests",
+		"limit exceeded",
+		"capacity exceeded",
+		"header not found",
+		"timeout",
+		"timed out",
+		"temporarily unavailable",
+		"try again",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRetryAfter 解析 Retry-After 响应头，支持秒数和 HTTP 日期两种格式，结果限制在 0 到 1 分钟之间
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = at.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	return delay, true
+}
+
+// retryAfterTransport 记录节点在 429/503 响应中返回的 Retry-After
+type retryAfterTransport struct {
+	base     http.RoundTripper
+	endpoint *endpoint
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		now := time.Now()
+		if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			t.endpoint.setRetryAfter(now.Add(delay))
+		}
+	}
+	return resp, nil
+}
+
+// RateLimiter 令牌桶限流器，使客户端请求速率保持在服务商配额之内
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64 // 桶容量
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限流器，rate 为每秒请求数，burst 为允许的突发请求数
+// rate 小于等于 0 时返回 nil，表示不限流
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 阻塞直到获得一个令牌或 ctx 结束，nil 限流器立即返回
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Allow 有可用令牌时取出并返回 true，否则立即返回 false，nil 限流器总是返回 true
+func (l *RateLimiter) Allow() bool {
+	return l == nil || l.reserve() == 0
+}
+
+// reserve 尝试取出一个令牌，失败时返回需要等待的时间
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// sleepContext 等待 d 或直到 ctx 结束
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
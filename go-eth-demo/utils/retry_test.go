@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	constant := RetryPolicy{InitialBackoff: 50 * time.Millisecond, Multiplier: 1}
+	if got := constant.Backoff(10); got != 50*time.Millisecond {
+		t.Errorf("Backoff(10) with multiplier 1 = %v, want 50ms", got)
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		// 第 2 次重试的基准为 2s，抖动后在 [1s, 2s] 之间
+		if got := policy.Backoff(2); got < time.Second || got > 2*time.Second {
+			t.Fatalf("Backoff(2) = %v, want within [1s, 2s]", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "soon", wantOK: false},
+		{value: "5", want: 5 * time.Second, wantOK: true},
+		{value: " 2 ", want: 2 * time.Second, wantOK: true},
+		{value: "0", want: 0, wantOK: true},
+		{value: "-3", want: 0, wantOK: true},
+		{value: "3600", want: time.Minute, wantOK: true},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second, wantOK: true},
+		{value: now.Add(-time.Hour).Format(http.TimeFormat), want: 0, wantOK: true},
+		{value: now.Add(time.Hour).Format(http.TimeFormat), want: time.Minute, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", fmt.Errorf("call: %w", context.Canceled), false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"connection refused", &url.Error{Op: "Post", URL: "http://node", Err: errors.New("connection refused")}, true},
+		{"client quit", rpc.ErrClientQuit, true},
+		{"HTTP 429", rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"HTTP 503", rpc.HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"HTTP 400", rpc.HTTPError{StatusCode: http.StatusBadRequest}, false},
+		{"HTTP 401", rpc.HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{"limit exceeded code", rpcTestError{code: -32005, msg: "request limit reached"}, true},
+		{"rate limit code 429", rpcTestError{code: 429, msg: "too many requests"}, true},
+		{"rate limit message", rpcTestError{code: -32000, msg: "Rate limit exceeded, try again later"}, true},
+		{"header not found", rpcTestError{code: -32000, msg: "header not found"}, true},
+		{"execution reverted", rpcTestError{code: 3, msg: "execution reverted: try again"}, false},
+		{"invalid params", rpcTestError{code: -32602, msg: "invalid argument 0: timeout"}, false},
+		{"method not found", rpcTestError{code: -32601, msg: "the method eth_foo does not exist"}, false},
+		{"nonce too low", rpcTestError{code: -32000, msg: "nonce too low"}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableError(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryableError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0, 5) != nil || NewRateLimiter(-1, 5) != nil {
+		t.Error("NewRateLimiter with rate <= 0 should return nil")
+	}
+	var unlimited *RateLimiter
+	if !unlimited.Allow() || unlimited.Wait(context.Background()) != nil {
+		t.Error("nil limiter should never limit")
+	}
+
+	// 桶满时允许 burst 个突发请求，之后按 rate 补充
+	limiter := NewRateLimiter(50, 2)
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("burst of 2 not allowed")
+	}
+	if limiter.Allow() {
+		t.Fatal("third request allowed with an empty bucket")
+	}
+	time.Sleep(25 * time.Millisecond) // 50/s 每 20ms 补充一个令牌
+	if !limiter.Allow() {
+		t.Error("token not refilled after 25ms at 50/s")
+	}
+
+	// burst 小于 1 时按 1 处理
+	single := NewRateLimiter(50, 0)
+	if !single.Allow() || single.Allow() {
+		t.Error("burst 0 should allow exactly one request")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(20, 1) // 每 50ms 一个令牌
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Wait() returned after %v, want about 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() with canceled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestCallHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return nil, ""
+		}
+		return "0x10", ""
+	})
+
+	ec := newTestClient(t, server.URL)
+	ec.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, Multiplier: 1})
+
+	start := time.Now()
+	number, err := ec.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("BlockNumber() error = %v", err)
+	}
+	if number != 16 {
+		t.Errorf("BlockNumber() = %d, want 16", number)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("node called %d times, want 2", got)
+	}
+}
+
+func TestCallStopsAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return nil, ""
+	})
+
+	ec := newTestClient(t, server.URL)
+	ec.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 1})
+
+	var httpErr rpc.HTTPError
+	if _, err := ec.BlockNumber(context.Background()); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("BlockNumber() error = %v, want HTTP 503", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("node called %d times, want 3", got)
+	}
+}
+
+func TestSetRetryPolicyConcurrentWithCall(t *testing.T) {
+	server := newRPCServer(t, func(w http.ResponseWriter, method string) (any, string) {
+		return "0x1", ""
+	})
+	ec := newTestClient(t, server.URL)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			ec.SetRetryPolicy(RetryPolicy{MaxAttempts: i%3 + 1, InitialBackoff: time.Millisecond})
+			ec.SetRateLimit(float64(1000+i), 10)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := ec.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RevertError 携带解码后回滚原因的错误
+type RevertError struct {
+	Reason string      // 解码后的原因，如 "Insufficient balance" 或 "Unauthorized(0x...)"
+	Data   []byte      // 原始回滚数据，可能为空
+	TxHash common.Hash // 重放的交易哈希，直接调用时为空
+}
+
+// Error 实现 error 接口
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.Reason
+}
+
+// CallBackend 重放交易所需的节点接口，*ethclient.Client 满足该接口
+type CallBackend interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// DecodeRevertData 解码回滚数据
+// 支持 Error(string)、Panic(uint256)，以及 contractABI 中定义的自定义错误 (contractABI 可为 nil)
+func DecodeRevertData(data []byte, contractABI *abi.ABI) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("empty revert data")
+	}
+	if len(data) < 4 {
+		return "", fmt.Errorf("revert data too short: %s", hexutil.Encode(data))
+	}
+
+	// Error(string) 和 Panic(uint256)
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason, nil
+	}
+
+	// 自定义错误
+	if contractABI != nil {
+		var selector [4]byte
+		copy(selector[:], data[:4])
+		if abiErr, err := contractABI.ErrorByID(selector); err == nil {
+			values, err := abiErr.Unpack(data)
+			if err != nil {
+				return "", fmt.Errorf("failed to unpack custom error %s: %w", abiErr.Name, err)
+			}
+			return formatCustomError(abiErr, values), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown revert selector %s", hexutil.Encode(data[:4]))
+}
+
+// formatCustomError 将自定义错误格式化为 Name(arg1, arg2)
+func formatCustomError(abiErr *abi.Error, values interface{}) string {
+	args, ok := values.([]interface{})
+	if !ok {
+		return abiErr.Name + "()"
+	}
+
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprintf("%v", arg)
+		if addr, ok := arg.(common.Address); ok {
+			parts[i] = addr.Hex()
+		}
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(parts, ", "))
+}
+
+// RevertDataFromError 从 JSON-RPC 错误中提取回滚数据
+func RevertDataFromError(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// ExplainCallError 尝试从调用/估算 Gas 的错误中解码回滚原因
+// 成功时返回 *RevertError，否则原样返回 err
+func ExplainCallError(err error, contractABI *abi.ABI) error {
+	if err == nil {
+		return nil
+	}
+
+	data, ok := RevertDataFromError(err)
+	if !ok {
+		return err
+	}
+
+	reason, decodeErr := DecodeRevertData(data, contractABI)
+	if decodeErr != nil {
+		return &RevertError{Reason: hexutil.Encode(data), Data: data}
+	}
+	return &RevertError{Reason: reason, Data: data}
+}
+
+// ReplayRevertReason 通过 eth_call 重放失败的交易以获取回滚原因
+// 重放基于交易所在区块的父区块状态 (receipt.BlockNumber - 1)，同一区块中排在该交易之前的交易不会被执行，
+// 回滚依赖这些交易的状态变化时可能无法复现或得到不同的原因
+func ReplayRevertReason(ctx context.Context, backend CallBackend, tx *types.Transaction, receipt *types.Receipt, contractABI *abi.ABI) (*RevertError, error) {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s did not revert", tx.Hash().Hex())
+	}
+
+	from, err := GetTransactionSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		msg.GasPrice = tx.GasPrice()
+	} else {
+		msg.GasFeeCap = tx.GasFeeCap()
+		msg.GasTipCap = tx.GasTipCap()
+	}
+
+	if receipt.BlockNumber == nil || receipt.BlockNumber.Sign() <= 0 {
+		return nil, fmt.Errorf("receipt of %s has no block number", tx.Hash().Hex())
+	}
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+
+	_, callErr := backend.CallContract(ctx, msg, parent)
+	if callErr == nil {
+		// 重放成功但链上失败，通常是 Gas 不足
+		if receipt.GasUsed >= tx.Gas() {
+			return &RevertError{Reason: "out of gas", TxHash: tx.Hash()}, nil
+		}
+		return nil, fmt.Errorf("could not reproduce revert of %s", tx.Hash().Hex())
+	}
+
+	revertErr := &RevertError{TxHash: tx.Hash()}
+	if explained, ok := ExplainCallError(callErr, contractABI).(*RevertError); ok {
+		revertErr.Reason = explained.Reason
+		revertErr.Data = explained.Data
+		return revertErr, nil
+	}
+
+	// 节点未返回回滚数据时使用错误信息本身
+	revertErr.Reason = strings.TrimPrefix(callErr.Error(), "execution reverted: ")
+	if revertErr.Reason == "execution reverted" {
+		revertErr.Reason = ""
+	}
+	return revertErr, nil
+}
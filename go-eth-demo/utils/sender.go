@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// knownChainConfigs 已知网络的链配置，用于按区块高度和时间确定生效的签名规则
+var knownChainConfigs = []*params.ChainConfig{
+	params.MainnetChainConfig,
+	params.SepoliaChainConfig,
+	params.HoleskyChainConfig,
+	params.HoodiChainConfig,
+}
+
+// ChainConfigForID 返回已知网络的链配置，未知网络 (如本地开发链) 返回 nil
+func ChainConfigForID(chainID *big.Int) *params.ChainConfig {
+	if chainID == nil {
+		return nil
+	}
+	for _, config := range knownChainConfigs {
+		if config.ChainID.Cmp(chainID) == 0 {
+			return config
+		}
+	}
+	return nil
+}
+
+// TransactionSigner 返回能验证该交易签名的 Signer
+// EIP-155 之前未包含链 ID 的 legacy 交易使用 Homestead 规则，其余交易按交易自身的链 ID 选择
+// 支持所有交易类型 (legacy、EIP-2930、EIP-1559、blob、set-code)
+func TransactionSigner(tx *types.Transaction) types.Signer {
+	if !tx.Protected() {
+		return types.HomesteadSigner{}
+	}
+	return types.LatestSignerForChainID(tx.ChainId())
+}
+
+// BlockSigner 返回指定区块生效的 Signer，与节点验证该区块内交易时使用的规则一致
+// 未知网络无法确定分叉高度，返回 nil，调用方应退回 TransactionSigner
+func BlockSigner(chainID *big.Int, header *types.Header) types.Signer {
+	config := ChainConfigForID(chainID)
+	if config == nil || header == nil {
+		return nil
+	}
+	return types.MakeSigner(config, header.Number, header.Time)
+}
+
+// GetTransactionSender 恢复交易发送方地址，按交易类型选择签名规则
+func GetTransactionSender(tx *types.Transaction) (common.Address, error) {
+	if tx.Protected() && tx.ChainId().Sign() <= 0 {
+		return common.Address{}, fmt.Errorf("transaction %s has invalid chain ID %s", tx.Hash().Hex(), tx.ChainId())
+	}
+	sender, err := types.Sender(TransactionSigner(tx), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender of %s: %w", tx.Hash().Hex(), err)
+	}
+	return sender, nil
+}
+
+// GetBlockTransactionSender 恢复区块内交易的发送方地址，使用该区块生效的签名规则
+// 这样早期区块中的 Frontier 交易也能正确恢复；未知网络时按交易类型选择
+func GetBlockTransactionSender(chainID *big.Int, header *types.Header, tx *types.Transaction) (common.Address, error) {
+	signer := BlockSigner(chainID, header)
+	if signer == nil {
+		return GetTransactionSender(tx)
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender of %s in block %s: %w", tx.Hash().Hex(), header.Number, err)
+	}
+	return sender, nil
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/local/go-eth-demo/config"
+)
+
+// ErrNoSigner 配置中既没有 KeyStore 也没有私钥
+var ErrNoSigner = errors.New("no signer configured: set KEYSTORE_PATH/KEYSTORE_PASSWORD or PRIVATE_KEY")
+
+// Signer 交易签名者，隐藏私钥的来源 (明文私钥、加密 KeyStore 文件、测试密钥)
+type Signer interface {
+	// Address 返回签名者的账户地址
+	Address() common.Address
+	// SignTx 使用与交易类型匹配的签名器为交易签名
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// keySigner 基于内存中 ECDSA 私钥的签名者，所有实现最终都使用它签名
+type keySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func newKeySigner(key *ecdsa.PrivateKey) *keySigner {
+	return &keySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Address 返回签名者的账户地址
+func (s *keySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 使用 LatestSignerForChainID 为交易签名
+func (s *keySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// NewPrivateKeySigner 从十六进制私钥创建签名者，允许 0x 前缀
+func NewPrivateKeySigner(privateKeyHex string) (Signer, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return newKeySigner(key), nil
+}
+
+// NewKeystoreSigner 解密 KeyStore 文件并创建签名者
+func NewKeystoreSigner(path, password string) (Signer, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return newKeySigner(key.PrivateKey), nil
+}
+
+// NewTestSigner 生成仅存在于内存中的随机密钥签名者，用于测试和本地开发链
+func NewTestSigner() (Signer, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return newKeySigner(key), nil
+}
+
+// NewSignerFromConfig 根据配置创建签名者，KeyStore 优先于明文私钥
+func NewSignerFromConfig(cfg *config.Config) (Signer, error) {
+	switch {
+	case cfg.HasKeystore():
+		return NewKeystoreSigner(cfg.KeystorePath, cfg.KeystorePassword)
+	case cfg.HasPrivateKey():
+		return NewPrivateKeySigner(cfg.PrivateKey)
+	default:
+		return nil, ErrNoSigner
+	}
+}
+
+// NewSignerFromEnv 根据 KEYSTORE_PATH/KEYSTORE_PASSWORD 或 PRIVATE_KEY 环境变量创建签名者
+// 用于未使用 config 包、直接读取环境变量的程序
+func NewSignerFromEnv() (Signer, error) {
+	return NewSignerFromConfig(&config.Config{
+		PrivateKey:       os.Getenv("PRIVATE_KEY"),
+		KeystorePath:     os.Getenv("KEYSTORE_PATH"),
+		KeystorePassword: os.Getenv("KEYSTORE_PASSWORD"),
+	})
+}
+
+// NewTransactOpts 为 abigen 生成的合约绑定创建使用 Signer 签名的交易选项
+func NewTransactOpts(signer Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: signer.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return signer.SignTx(tx, chainID)
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 常用单位相对 Wei 的小数位数
+const (
+	GweiDecimals  uint8 = 9
+	EtherDecimals uint8 = 18
+)
+
+// ErrInvalidAmount 金额字符串不是合法的非负十进制数
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseUnits 将十进制字符串精确转换为最小单位的整数，如 ParseUnits("1.5", 18) 返回 1500000000000000000
+// 只接受非负的普通十进制写法 (不支持符号、指数和千分位)，小数位数超过 decimals 时返回错误而不是截断
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	s := strings.TrimSpace(value)
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if !isDigits(whole) || !isDigits(frac) || (hasPoint && frac == "") {
+		return nil, fmt.Errorf("%w: %q is not a non-negative decimal number", ErrInvalidAmount, value)
+	}
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	return amount, nil
+}
+
+// FormatUnits 将最小单位的整数精确格式化为十进制字符串，去掉小数部分末尾的 0，负数带 "-" 前缀
+// 对非负的 x 与 ParseUnits 互逆: ParseUnits(FormatUnits(x, d), d) == x；ParseUnits 不接受负数
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	point := len(digits) - int(decimals)
+	result := digits[:point]
+	if frac := strings.TrimRight(digits[point:], "0"); frac != "" {
+		result += "." + frac
+	}
+	if amount.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+// ParseEther 将以 ETH 为单位的十进制字符串转换为 Wei
+func ParseEther(value string) (*big.Int, error) {
+	return ParseUnits(value, EtherDecimals)
+}
+
+// ParseGwei 将以 Gwei 为单位的十进制字符串转换为 Wei
+func ParseGwei(value string) (*big.Int, error) {
+	return ParseUnits(value, GweiDecimals)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
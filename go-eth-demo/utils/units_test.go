@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestUnitsRoundTrip(t *testing.T) {
+	amounts := []string{
+		"0", "1", "9", "10", "123456789", "1000000000000000000",
+		"1500000000000000000", "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+	}
+	for _, decimals := range []uint8{0, 6, 9, 18} {
+		for _, s := range amounts {
+			x, _ := new(big.Int).SetString(s, 10)
+			formatted := FormatUnits(x, decimals)
+			parsed, err := ParseUnits(formatted, decimals)
+			if err != nil {
+				t.Errorf("ParseUnits(%q, %d) error = %v", formatted, decimals, err)
+				continue
+			}
+			if parsed.Cmp(x) != 0 {
+				t.Errorf("round trip of %s at %d decimals: FormatUnits = %q, ParseUnits = %s", s, decimals, formatted, parsed)
+			}
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals uint8
+		want     string
+	}{
+		{"0.1", 18, "100000000000000000"},
+		{"1.5", 18, "1500000000000000000"},
+		{"0.000000000000000001", 18, "1"},
+		{"1", 0, "1"},
+		{"2.5", 6, "2500000"},
+		{" 3 ", 9, "3000000000"},
+		{".5", 18, "500000000000000000"}, // 省略整数部分的写法按 0.5 处理
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.value, tt.decimals)
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d) error = %v", tt.value, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnitsRejects(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals uint8
+	}{
+		{"1.", 18},
+		{"-1", 18},
+		{"1e18", 18},
+		{"1,000", 18},
+		{"0.0000000000000000001", 18}, // 19 位小数
+		{"1.5", 0},
+		{"", 18},
+		{".", 18},
+		{"+1", 18},
+		{"0x10", 18},
+	}
+	for _, tt := range tests {
+		if got, err := ParseUnits(tt.value, tt.decimals); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseUnits(%q, %d) = %v, %v; want ErrInvalidAmount", tt.value, tt.decimals, got, err)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   *big.Int
+		decimals uint8
+		want     string
+	}{
+		{nil, 18, "0"},
+		{big.NewInt(0), 18, "0"},
+		{big.NewInt(1), 18, "0.000000000000000001"},
+		{big.NewInt(1500000000000000000), 18, "1.5"},
+		{big.NewInt(1000000), 6, "1"},
+		{big.NewInt(-2500000), 6, "-2.5"},
+		{big.NewInt(42), 0, "42"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%v, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
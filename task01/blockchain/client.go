@@ -3,18 +3,48 @@ package blockchain
 import (
 	"context"
 	"log"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // Client 封装以太坊客户端
 type Client struct {
-	client *ethclient.Client
-	ctx    context.Context
+	client       *ethclient.Client
+	ctx          context.Context
+	feeMode      config.FeeMode
+	gasMargin    uint64 // Gas 估算余量百分比
+	chainID      *big.Int
+	nonces       *utils.NonceManager
+	allowMainnet bool
 }
 
-// NewClient 创建新的以太坊客户端连接
-func NewClient(rpcURL string) (*Client, error) {
+// NewClient 创建新的以太坊客户端连接，并校验节点的链 ID 与 expectedChainID 一致
+func NewClient(rpcURL string, expectedChainID int64) (*Client, error) {
+	return newClient(rpcURL, big.NewInt(expectedChainID))
+}
+
+// NewClientFromConfig 根据配置创建客户端
+// 默认校验节点链 ID 与配置一致，仅在配置 SKIP_CHAIN_ID_CHECK 时跳过；ALLOW_MAINNET 控制是否允许在主网签名
+func NewClientFromConfig(cfg *config.Config) (*Client, error) {
+	var expected *big.Int
+	if !cfg.SkipChainIDCheck {
+		expected = big.NewInt(cfg.ChainID)
+	}
+
+	client, err := newClient(cfg.EthereumRPCURL, expected)
+	if err != nil {
+		return nil, err
+	}
+	client.allowMainnet = cfg.AllowMainnet
+	client.gasMargin = uint64(cfg.GasMargin)
+	return client, nil
+}
+
+// newClient 连接节点，expected 为 nil 时不校验链 ID
+func newClient(rpcURL string, expected *big.Int) (*Client, error) {
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, err
@@ -25,14 +55,25 @@ func NewClient(rpcURL string) (*Client, error) {
 	// 测试连接
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
+		client.Close()
 		return nil, err
 	}
 
+	if expected != nil {
+		if err := utils.CheckChainID(expected, chainID); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
 	log.Printf("成功连接到以太坊网络，Chain ID: %d", chainID)
 
 	return &Client{
-		client: client,
-		ctx:    ctx,
+		client:    client,
+		ctx:       ctx,
+		chainID:   chainID,
+		nonces:    utils.NewNonceManager(client, chainID),
+		gasMargin: utils.DefaultGasMargin,
 	}, nil
 }
 
@@ -46,6 +87,31 @@ func (c *Client) GetContext() context.Context {
 	return c.ctx
 }
 
+// SetFeeMode 设置发送交易时使用的费用模式，默认为 config.FeeModeAuto
+func (c *Client) SetFeeMode(mode config.FeeMode) {
+	c.feeMode = mode
+}
+
+// ChainID 获取连接时节点返回的链 ID
+func (c *Client) ChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// SetAllowMainnet 设置是否允许在主网签名交易
+func (c *Client) SetAllowMainnet(allow bool) {
+	c.allowMainnet = allow
+}
+
+// GuardSigner 包装 signer，使其只能为已连接节点的链签名交易，主网需允许后才能签名
+func (c *Client) GuardSigner(signer utils.Signer) utils.Signer {
+	return utils.NewChainGuard(signer, c.chainID, c.allowMainnet)
+}
+
+// NonceManager 获取发送交易使用的 nonce 管理器
+func (c *Client) NonceManager() *utils.NonceManager {
+	return c.nonces
+}
+
 // Close 关闭客户端连接
 func (c *Client) Close() {
 	c.client.Close()
@@ -0,0 +1,12 @@
+package blockchain
+
+import "math/big"
+
+// txFees 构建交易时使用的费用参数
+type txFees struct {
+	Dynamic   bool
+	GasPrice  *big.Int // 传统交易的 gasPrice
+	GasFeeCap *big.Int // 动态费用交易的最高费用
+	GasTipCap *big.Int // 动态费用交易的小费
+	BaseFee   *big.Int // 获取费用时最新区块的基础费用，传统交易为 nil
+}
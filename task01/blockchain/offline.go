@@ -0,0 +1,334 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
+)
+
+// OfflineTransaction 离线签名流程中在联网机器和离线机器之间传递的交易
+// 流程: 联网构建 (BuildTransaction) -> 离线签名 (SignOfflineTransaction) -> 联网广播 (BroadcastTransaction)
+type OfflineTransaction struct {
+	ChainID *big.Int
+	From    common.Address // 构建时指定的发送方，签名时校验签名者地址
+	Tx      *types.Transaction
+}
+
+// offlineTxFile 交易文件的 JSON 格式
+// raw 为交易的 RLP 编码，是唯一可信的数据；其余字段便于人工核对，读取时会与 raw 比对
+type offlineTxFile struct {
+	Signed               bool            `json:"signed"`
+	ChainID              string          `json:"chainId"`
+	From                 common.Address  `json:"from"`
+	Type                 uint8           `json:"type"`
+	Nonce                uint64          `json:"nonce"`
+	To                   *common.Address `json:"to"`
+	Value                string          `json:"value"`
+	Gas                  uint64          `json:"gas"`
+	GasPrice             string          `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string          `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string          `json:"maxPriorityFeePerGas,omitempty"`
+	Data                 hexutil.Bytes   `json:"data"`
+	SigningHash          common.Hash     `json:"signingHash"`
+	Hash                 *common.Hash    `json:"hash,omitempty"`
+	Raw                  hexutil.Bytes   `json:"raw"`
+}
+
+// BuildTransaction 按 EstimateTransaction 的结果构建未签名的转账交易，nonce 从节点获取
+// 返回的交易可写入文件交给离线机器签名
+func (c *Client) BuildTransaction(cost *utils.TxCost) (*OfflineTransaction, error) {
+	if cost.To == nil {
+		return nil, errors.New("暂不支持构建合约创建交易")
+	}
+
+	// 使用节点的待处理 nonce，离线签名期间不能再用同一账户发送其他交易
+	nonce, err := c.client.PendingNonceAt(c.ctx, cost.From)
+	if err != nil {
+		return nil, fmt.Errorf("获取nonce失败: %v", err)
+	}
+
+	tx := newTransferTx(c.chainID, nonce, *cost.To, cost.Value, cost.GasLimit, feesFromCost(cost), nil)
+	return &OfflineTransaction{ChainID: new(big.Int).Set(c.chainID), From: cost.From, Tx: tx}, nil
+}
+
+// SignOfflineTransaction 使用 signer 为未签名交易签名，不需要连接节点
+// 签名者地址必须与构建时指定的发送方一致；主网交易需 allowMainnet 为 true
+func SignOfflineTransaction(signer utils.Signer, otx *OfflineTransaction, allowMainnet bool) (*OfflineTransaction, error) {
+	if otx.Signed() {
+		return nil, errors.New("交易已签名")
+	}
+	if signer.Address() != otx.From {
+		return nil, fmt.Errorf("签名者地址 %s 与交易发送方 %s 不一致", signer.Address().Hex(), otx.From.Hex())
+	}
+
+	signedTx, err := utils.NewChainGuard(signer, otx.ChainID, allowMainnet).SignTx(otx.Tx, otx.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %w", err)
+	}
+	return &OfflineTransaction{ChainID: otx.ChainID, From: otx.From, Tx: signedTx}, nil
+}
+
+// BroadcastTransaction 通过 eth_sendRawTransaction 广播已签名的交易
+// 交易的链 ID 必须与已连接节点一致
+func (c *Client) BroadcastTransaction(otx *OfflineTransaction) (*TransactionInfo, error) {
+	if !otx.Signed() {
+		return nil, errors.New("交易未签名，请先使用离线签名")
+	}
+	if err := utils.CheckChainID(c.chainID, otx.ChainID); err != nil {
+		return nil, fmt.Errorf("拒绝广播: %w", err)
+	}
+
+	if err := c.client.SendTransaction(c.ctx, otx.Tx); err != nil {
+		return nil, fmt.Errorf("广播交易失败: %v", err)
+	}
+
+	log.Printf("交易已广播，哈希: %s", otx.Tx.Hash().Hex())
+	return newTransactionInfo(otx.Tx, otx.From), nil
+}
+
+// Signed 判断交易是否已签名
+func (otx *OfflineTransaction) Signed() bool {
+	v, r, s := otx.Tx.RawSignatureValues()
+	return v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+}
+
+// SigningHash 返回签名者实际签名的哈希，离线机器上可与联网机器显示的值核对
+func (otx *OfflineTransaction) SigningHash() common.Hash {
+	return types.LatestSignerForChainID(otx.ChainID).Hash(otx.Tx)
+}
+
+// RawHex 返回交易 RLP 编码的十六进制字符串，已签名时即 eth_sendRawTransaction 的参数
+func (otx *OfflineTransaction) RawHex() (string, error) {
+	raw, err := otx.Tx.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("编码交易失败: %v", err)
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// Fields 实现 output.Record，字段名与 TransactionInfo 保持一致
+func (otx *OfflineTransaction) Fields() []output.Field {
+	tx := otx.Tx
+	var gasPrice, feeCap, tipCap *big.Int
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		gasPrice = tx.GasPrice()
+	} else {
+		feeCap, tipCap = tx.GasFeeCap(), tx.GasTipCap()
+	}
+	var hash interface{}
+	if otx.Signed() {
+		hash = tx.Hash()
+	}
+	raw, _ := otx.RawHex()
+
+	return []output.Field{
+		{Key: "signed", Label: "已签名", Value: otx.Signed()},
+		{Key: "chain_id", Label: "Chain ID", Value: otx.ChainID},
+		{Key: "from", Label: "发送方", Value: otx.From},
+		{Key: "type", Label: "交易类型", Value: tx.Type()},
+		{Key: "nonce", Label: "Nonce", Value: tx.Nonce()},
+		{Key: "to", Label: "接收方", Value: tx.To()},
+		{Key: "value", Label: "转账金额", Value: tx.Value(), Text: utils.WeiToEther(tx.Value()) + " ETH (" + tx.Value().String() + " Wei)"},
+		{Key: "gas", Label: "Gas限制", Value: tx.Gas()},
+		{Key: "gas_price", Label: "Gas价格 (Wei)", Value: gasPrice},
+		{Key: "max_fee_per_gas", Label: "最高费用 (Wei)", Value: feeCap},
+		{Key: "max_priority_fee_per_gas", Label: "小费 (Wei)", Value: tipCap},
+		{Key: "max_cost", Label: "最大花费", Value: tx.Cost(), Text: utils.WeiToEther(tx.Cost()) + " ETH"},
+		{Key: "data", Label: "数据", Value: tx.Data()},
+		{Key: "signing_hash", Label: "签名哈希", Value: otx.SigningHash()},
+		{Key: "hash", Label: "交易哈希", Value: hash},
+		{Key: "raw", Label: "RLP 编码", Value: raw},
+	}
+}
+
+// MarshalJSON 编码为交易文件格式
+func (otx *OfflineTransaction) MarshalJSON() ([]byte, error) {
+	raw, err := otx.Tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("编码交易失败: %v", err)
+	}
+
+	tx := otx.Tx
+	file := offlineTxFile{
+		Signed:      otx.Signed(),
+		ChainID:     otx.ChainID.String(),
+		From:        otx.From,
+		Type:        tx.Type(),
+		Nonce:       tx.Nonce(),
+		To:          tx.To(),
+		Value:       tx.Value().String(),
+		Gas:         tx.Gas(),
+		Data:        tx.Data(),
+		SigningHash: otx.SigningHash(),
+		Raw:         raw,
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		file.GasPrice = tx.GasPrice().String()
+	} else {
+		file.MaxFeePerGas = tx.GasFeeCap().String()
+		file.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+	if file.Signed {
+		hash := tx.Hash()
+		file.Hash = &hash
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// UnmarshalJSON 解码交易文件，可读字段与 raw 不一致时返回错误，防止核对的内容与实际签名的内容不同
+func (otx *OfflineTransaction) UnmarshalJSON(data []byte) error {
+	var file offlineTxFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析交易文件失败: %v", err)
+	}
+	if len(file.Raw) == 0 {
+		return errors.New("交易文件缺少 raw 字段")
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(file.Raw); err != nil {
+		return fmt.Errorf("解码交易失败: %v", err)
+	}
+	chainID, ok := new(big.Int).SetString(file.ChainID, 10)
+	if !ok || chainID.Sign() <= 0 {
+		return fmt.Errorf("无效的 chainId: %q", file.ChainID)
+	}
+
+	decoded := &OfflineTransaction{ChainID: chainID, From: file.From, Tx: tx}
+	if err := decoded.verify(); err != nil {
+		return err
+	}
+
+	// 重新编码后与文件逐字段比对
+	var expected offlineTxFile
+	encoded, err := decoded.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(encoded, &expected); err != nil {
+		return err
+	}
+	if mismatch := diffOfflineTxFile(&file, &expected); mismatch != "" {
+		return fmt.Errorf("交易文件的 %s 字段与 raw 不一致", mismatch)
+	}
+
+	*otx = *decoded
+	return nil
+}
+
+// verify 检查链 ID 与交易体一致，已签名交易的发送方与 From 一致
+func (otx *OfflineTransaction) verify() error {
+	tx := otx.Tx
+	if tx.Type() != types.LegacyTxType || tx.Protected() {
+		if tx.ChainId().Cmp(otx.ChainID) != 0 {
+			return fmt.Errorf("交易的链 ID %s 与文件中的 chainId %s 不一致", tx.ChainId(), otx.ChainID)
+		}
+	}
+	if otx.Signed() {
+		sender, err := utils.GetTransactionSender(tx)
+		if err != nil {
+			return fmt.Errorf("恢复交易发送方失败: %v", err)
+		}
+		if sender != otx.From {
+			return fmt.Errorf("交易签名者 %s 与发送方 %s 不一致", sender.Hex(), otx.From.Hex())
+		}
+	}
+	return nil
+}
+
+// diffOfflineTxFile 返回第一个不一致的字段名，全部一致时返回空字符串
+func diffOfflineTxFile(got, want *offlineTxFile) string {
+	switch {
+	case got.Signed != want.Signed:
+		return "signed"
+	case got.From != want.From:
+		return "from"
+	case got.Type != want.Type:
+		return "type"
+	case got.Nonce != want.Nonce:
+		return "nonce"
+	case (got.To == nil) != (want.To == nil) || (got.To != nil && *got.To != *want.To):
+		return "to"
+	case got.Value != want.Value:
+		return "value"
+	case got.Gas != want.Gas:
+		return "gas"
+	case got.GasPrice != want.GasPrice:
+		return "gasPrice"
+	case got.MaxFeePerGas != want.MaxFeePerGas:
+		return "maxFeePerGas"
+	case got.MaxPriorityFeePerGas != want.MaxPriorityFeePerGas:
+		return "maxPriorityFeePerGas"
+	case !bytes.Equal(got.Data, want.Data):
+		return "data"
+	case got.SigningHash != want.SigningHash:
+		return "signingHash"
+	case (got.Hash == nil) != (want.Hash == nil) || (got.Hash != nil && *got.Hash != *want.Hash):
+		return "hash"
+	}
+	return ""
+}
+
+// WriteOfflineTransaction 将交易写入 JSON 文件
+func WriteOfflineTransaction(path string, otx *OfflineTransaction) error {
+	data, err := otx.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("写入交易文件失败: %v", err)
+	}
+	return nil
+}
+
+// ReadOfflineTransaction 读取交易文件
+// 除 WriteOfflineTransaction 写出的 JSON 外，也接受只包含已签名交易十六进制编码的文件
+func ReadOfflineTransaction(path string) (*OfflineTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取交易文件失败: %v", err)
+	}
+	return ParseOfflineTransaction(data)
+}
+
+// ParseOfflineTransaction 解析 JSON 交易文件或已签名交易的十六进制编码
+func ParseOfflineTransaction(data []byte) (*OfflineTransaction, error) {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		otx := new(OfflineTransaction)
+		if err := json.Unmarshal([]byte(text), otx); err != nil {
+			return nil, err
+		}
+		return otx, nil
+	}
+
+	raw, err := hexutil.Decode(text)
+	if err != nil {
+		return nil, fmt.Errorf("无效的交易编码: %v", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("解码交易失败: %v", err)
+	}
+
+	// 只有编码时无法得知发送方和链 ID，需从签名中恢复
+	if !tx.Protected() {
+		return nil, errors.New("不支持未签名或不含链 ID 的原始交易，请使用 JSON 交易文件")
+	}
+	from, err := utils.GetTransactionSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("恢复交易发送方失败: %v", err)
+	}
+	return &OfflineTransaction{ChainID: tx.ChainId(), From: from, Tx: tx}, nil
+}
@@ -0,0 +1,198 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/local/go-eth-demo/utils"
+)
+
+const (
+	testKey      = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	otherTestKey = "8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"
+)
+
+var testChainID = big.NewInt(11155111)
+
+// newTestSigner 使用固定私钥创建签名者
+func newTestSigner(t *testing.T, key string) utils.Signer {
+	t.Helper()
+	signer, err := utils.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner() error = %v", err)
+	}
+	return signer
+}
+
+// buildTestTransaction 与 BuildTransaction 相同的方式构建未签名转账，不需要连接节点
+func buildTestTransaction(from common.Address, dynamic bool) *OfflineTransaction {
+	to := common.HexToAddress("0x742d35Cc6634C0532925a3b8D0C9e3e0C8b0e4c2")
+	cost := &utils.TxCost{From: from, To: &to, Value: big.NewInt(1e15), GasLimit: 21000}
+	if dynamic {
+		cost.BaseFee = big.NewInt(1e9)
+		cost.GasTipCap = big.NewInt(2e9)
+		cost.GasFeeCap = big.NewInt(4e9)
+	} else {
+		cost.GasPrice = big.NewInt(3e9)
+	}
+
+	tx := newTransferTx(testChainID, 7, *cost.To, cost.Value, cost.GasLimit, feesFromCost(cost), nil)
+	return &OfflineTransaction{ChainID: new(big.Int).Set(testChainID), From: from, Tx: tx}
+}
+
+// writeAndRead 写入交易文件后重新读取
+func writeAndRead(t *testing.T, otx *OfflineTransaction) *OfflineTransaction {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tx.json")
+	if err := WriteOfflineTransaction(path, otx); err != nil {
+		t.Fatalf("WriteOfflineTransaction() error = %v", err)
+	}
+	read, err := ReadOfflineTransaction(path)
+	if err != nil {
+		t.Fatalf("ReadOfflineTransaction() error = %v", err)
+	}
+	return read
+}
+
+// editField 修改交易文件 JSON 中的一个字段
+func editField(t *testing.T, otx *OfflineTransaction, key string, value any) []byte {
+	t.Helper()
+	data, err := otx.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	file[key] = value
+	edited, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return edited
+}
+
+func TestOfflineTransactionRoundTrip(t *testing.T) {
+	for _, dynamic := range []bool{false, true} {
+		name := "legacy"
+		if dynamic {
+			name = "eip1559"
+		}
+		t.Run(name, func(t *testing.T) {
+			signer := newTestSigner(t, testKey)
+			built := buildTestTransaction(signer.Address(), dynamic)
+
+			unsigned := writeAndRead(t, built)
+			if unsigned.Signed() {
+				t.Fatal("unsigned transaction read back as signed")
+			}
+			if unsigned.From != built.From || unsigned.ChainID.Cmp(built.ChainID) != 0 {
+				t.Errorf("read from=%s chainId=%s, want %s %s", unsigned.From.Hex(), unsigned.ChainID, built.From.Hex(), built.ChainID)
+			}
+			if unsigned.SigningHash() != built.SigningHash() {
+				t.Errorf("signing hash changed: %s != %s", unsigned.SigningHash().Hex(), built.SigningHash().Hex())
+			}
+
+			signed, err := SignOfflineTransaction(signer, unsigned, false)
+			if err != nil {
+				t.Fatalf("SignOfflineTransaction() error = %v", err)
+			}
+
+			read := writeAndRead(t, signed)
+			if !read.Signed() {
+				t.Fatal("signed transaction read back as unsigned")
+			}
+			if read.Tx.Hash() != signed.Tx.Hash() {
+				t.Errorf("hash = %s, want %s", read.Tx.Hash().Hex(), signed.Tx.Hash().Hex())
+			}
+			if read.Tx.Type() != built.Tx.Type() || read.Tx.Nonce() != 7 || read.Tx.Value().Cmp(big.NewInt(1e15)) != 0 {
+				t.Errorf("transaction fields changed: type=%d nonce=%d value=%s", read.Tx.Type(), read.Tx.Nonce(), read.Tx.Value())
+			}
+			sender, err := utils.GetTransactionSender(read.Tx)
+			if err != nil || sender != signer.Address() {
+				t.Errorf("sender = %s, %v; want %s", sender.Hex(), err, signer.Address().Hex())
+			}
+
+			if _, err := SignOfflineTransaction(signer, read, false); err == nil {
+				t.Error("signing an already signed transaction succeeded")
+			}
+		})
+	}
+}
+
+func TestOfflineTransactionRejectsEditedFields(t *testing.T) {
+	signer := newTestSigner(t, testKey)
+	unsigned := buildTestTransaction(signer.Address(), true)
+	signed, err := SignOfflineTransaction(signer, unsigned, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edits := []struct {
+		key   string
+		value any
+	}{
+		{"to", "0x0000000000000000000000000000000000000001"},
+		{"value", "2000000000000000"},
+		{"signingHash", common.HexToHash("0x01").Hex()},
+	}
+	for _, otx := range []*OfflineTransaction{unsigned, signed} {
+		for _, edit := range edits {
+			data := editField(t, otx, edit.key, edit.value)
+			_, err := ParseOfflineTransaction(data)
+			if err == nil || !strings.Contains(err.Error(), edit.key) {
+				t.Errorf("signed=%v, edited %s: error = %v, want mismatch on %s", otx.Signed(), edit.key, err, edit.key)
+			}
+		}
+	}
+}
+
+func TestOfflineTransactionRejectsWrongSigner(t *testing.T) {
+	signer := newTestSigner(t, testKey)
+	other := newTestSigner(t, otherTestKey)
+
+	// 已签名文件的 from 被改为其他地址
+	signed, err := SignOfflineTransaction(signer, buildTestTransaction(signer.Address(), false), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := editField(t, signed, "from", other.Address().Hex())
+	if _, err := ParseOfflineTransaction(data); err == nil || !strings.Contains(err.Error(), "不一致") {
+		t.Errorf("ParseOfflineTransaction() error = %v, want signer mismatch", err)
+	}
+
+	// 用其他账户的私钥签名交易
+	if _, err := SignOfflineTransaction(other, buildTestTransaction(signer.Address(), false), false); err == nil {
+		t.Error("signing with a different account succeeded")
+	}
+}
+
+func TestReadOfflineTransactionRawHex(t *testing.T) {
+	signer := newTestSigner(t, testKey)
+	signed, err := SignOfflineTransaction(signer, buildTestTransaction(signer.Address(), true), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := signed.RawHex()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tx.hex")
+	if err := os.WriteFile(path, []byte(raw+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadOfflineTransaction(path)
+	if err != nil {
+		t.Fatalf("ReadOfflineTransaction() error = %v", err)
+	}
+	if read.From != signer.Address() || read.Tx.Hash() != signed.Tx.Hash() || read.ChainID.Cmp(testChainID) != 0 {
+		t.Errorf("read from=%s hash=%s chainId=%s", read.From.Hex(), read.Tx.Hash().Hex(), read.ChainID)
+	}
+}
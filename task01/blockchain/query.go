@@ -1,14 +1,24 @@
 package blockchain
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/output"
 )
 
+// ErrBlockNotFound 表示节点上不存在所查询的区块，与网络/传输错误区分开
+var ErrBlockNotFound = errors.New("区块不存在")
+
 // BlockInfo 存储区块信息
 type BlockInfo struct {
 	Number          *big.Int
@@ -20,18 +30,34 @@ type BlockInfo struct {
 	GasLimit        uint64
 	Miner           string
 	Difficulty      *big.Int
-	TotalDifficulty *big.Int
+	TotalDifficulty *big.Int // 累计难度，节点未提供时为 nil（合并后的节点通常不再返回）
 	Size            uint64
+
+	// 伦敦/上海/坎昆升级引入的区块头字段，升级前的区块为 nil
+	BaseFee          *big.Int     // EIP-1559 基础费用
+	WithdrawalsRoot  *common.Hash // EIP-4895 提款根
+	WithdrawalsCount int          // 区块中的提款数量
+	BlobGasUsed      *uint64      // EIP-4844 Blob Gas 使用量
+	ExcessBlobGas    *uint64      // EIP-4844 超额 Blob Gas
+	ParentBeaconRoot *common.Hash // EIP-4788 父信标区块根
 }
 
 // QueryBlockByNumber 根据区块号查询区块信息
 func (c *Client) QueryBlockByNumber(blockNumber *big.Int) (*BlockInfo, error) {
-	block, err := c.client.BlockByNumber(c.ctx, blockNumber)
+	return c.queryBlockByNumber(c.ctx, blockNumber)
+}
+
+// queryBlockByNumber 使用指定上下文根据区块号查询区块信息
+func (c *Client) queryBlockByNumber(ctx context.Context, blockNumber *big.Int) (*BlockInfo, error) {
+	block, err := c.client.BlockByNumber(ctx, blockNumber)
 	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("%w: 区块号 %s", ErrBlockNotFound, blockNumber.String())
+		}
 		return nil, fmt.Errorf("查询区块失败: %v", err)
 	}
 
-	return c.extractBlockInfo(block), nil
+	return c.extractBlockInfo(ctx, block), nil
 }
 
 // QueryLatestBlock 查询最新区块信息
@@ -41,31 +67,89 @@ func (c *Client) QueryLatestBlock() (*BlockInfo, error) {
 		return nil, fmt.Errorf("查询最新区块失败: %v", err)
 	}
 
-	return c.extractBlockInfo(block), nil
+	return c.extractBlockInfo(c.ctx, block), nil
 }
 
 // QueryBlockByHash 根据区块哈希查询区块信息
+// 区块不存在时返回包装了 ErrBlockNotFound 的错误，可用 errors.Is 判断
 func (c *Client) QueryBlockByHash(blockHash string) (*BlockInfo, error) {
-	// 这里可以实现根据哈希查询区块的逻辑
-	// 为了简化，暂时不实现
-	return nil, fmt.Errorf("根据哈希查询区块功能暂未实现")
+	hash, err := parseHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := c.client.BlockByHash(c.ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("%w: 区块哈希 %s", ErrBlockNotFound, hash.Hex())
+		}
+		return nil, fmt.Errorf("根据哈希查询区块失败: %v", err)
+	}
+
+	// 校验节点返回的区块确实是请求的区块
+	if block.Hash() != hash {
+		return nil, fmt.Errorf("节点返回的区块哈希不匹配: 期望 %s, 实际 %s", hash.Hex(), block.Hash().Hex())
+	}
+
+	return c.extractBlockInfo(c.ctx, block), nil
+}
+
+// parseHash 解析并校验32字节的十六进制哈希 (区块哈希或交易哈希)
+func parseHash(hashHex string) (common.Hash, error) {
+	raw, err := hexutil.Decode(hashHex)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("无效的哈希 %q: %v", hashHex, err)
+	}
+	if len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("无效的哈希 %q: 长度应为 %d 字节", hashHex, common.HashLength)
+	}
+	return common.BytesToHash(raw), nil
 }
 
 // extractBlockInfo 从区块中提取信息
-func (c *Client) extractBlockInfo(block *types.Block) *BlockInfo {
+func (c *Client) extractBlockInfo(ctx context.Context, block *types.Block) *BlockInfo {
+	header := block.Header()
+
 	return &BlockInfo{
-		Number:          block.Number(),
-		Hash:            block.Hash().Hex(),
-		ParentHash:      block.ParentHash().Hex(),
-		Timestamp:       block.Time(),
-		TxCount:         len(block.Transactions()),
-		GasUsed:         block.GasUsed(),
-		GasLimit:        block.GasLimit(),
-		Miner:           block.Coinbase().Hex(),
-		Difficulty:      block.Difficulty(),
-		TotalDifficulty: block.Difficulty(), // 注意：这里简化了，实际应该是累计难度
-		Size:            block.Size(),
+		Number:           block.Number(),
+		Hash:             block.Hash().Hex(),
+		ParentHash:       block.ParentHash().Hex(),
+		Timestamp:        block.Time(),
+		TxCount:          len(block.Transactions()),
+		GasUsed:          block.GasUsed(),
+		GasLimit:         block.GasLimit(),
+		Miner:            block.Coinbase().Hex(),
+		Difficulty:       block.Difficulty(),
+		TotalDifficulty:  c.queryTotalDifficulty(ctx, block),
+		Size:             block.Size(),
+		BaseFee:          header.BaseFee,
+		WithdrawalsRoot:  header.WithdrawalsHash,
+		WithdrawalsCount: len(block.Withdrawals()),
+		BlobGasUsed:      header.BlobGasUsed,
+		ExcessBlobGas:    header.ExcessBlobGas,
+		ParentBeaconRoot: header.ParentBeaconRoot,
+	}
+}
+
+// queryTotalDifficulty 通过原始 eth_getBlockByNumber 调用获取累计难度
+// ethclient 的区块类型不包含 totalDifficulty 字段，节点不提供或查询失败时返回 nil
+func (c *Client) queryTotalDifficulty(ctx context.Context, block *types.Block) *big.Int {
+	var raw struct {
+		Hash            common.Hash  `json:"hash"`
+		TotalDifficulty *hexutil.Big `json:"totalDifficulty"`
+	}
+
+	err := c.client.Client().CallContext(ctx, &raw, "eth_getBlockByNumber", hexutil.EncodeBig(block.Number()), false)
+	if err != nil || raw.TotalDifficulty == nil {
+		return nil
 	}
+
+	// 查询期间发生重组时，同一高度可能已是另一个区块
+	if raw.Hash != block.Hash() {
+		return nil
+	}
+
+	return raw.TotalDifficulty.ToInt()
 }
 
 // PrintBlockInfo 打印区块信息到控制台
@@ -80,26 +164,125 @@ func (info *BlockInfo) PrintBlockInfo() {
 	fmt.Printf("Gas 限制: %d\n", info.GasLimit)
 	fmt.Printf("矿工地址: %s\n", info.Miner)
 	fmt.Printf("难度: %s\n", info.Difficulty.String())
+	if info.TotalDifficulty != nil {
+		fmt.Printf("累计难度: %s\n", info.TotalDifficulty.String())
+	} else {
+		fmt.Println("累计难度: 节点未提供")
+	}
 	fmt.Printf("区块大小: %d bytes\n", info.Size)
+	if info.BaseFee != nil {
+		fmt.Printf("基础费用: %s Wei\n", info.BaseFee.String())
+	}
+	if info.WithdrawalsRoot != nil {
+		fmt.Printf("提款根: %s\n", info.WithdrawalsRoot.Hex())
+		fmt.Printf("提款数量: %d\n", info.WithdrawalsCount)
+	}
+	if info.BlobGasUsed != nil {
+		fmt.Printf("Blob Gas 使用量: %d\n", *info.BlobGasUsed)
+	}
+	if info.ExcessBlobGas != nil {
+		fmt.Printf("超额 Blob Gas: %d\n", *info.ExcessBlobGas)
+	}
+	if info.ParentBeaconRoot != nil {
+		fmt.Printf("父信标区块根: %s\n", info.ParentBeaconRoot.Hex())
+	}
 	fmt.Println("================================================")
 }
 
-// QueryMultipleBlocks 查询多个区块信息
-func (c *Client) QueryMultipleBlocks(startBlock, count int64) ([]*BlockInfo, error) {
-	var blocks []*BlockInfo
+// Fields 实现 output.Record，字段名与 go-eth-demo 的 output.Block 保持一致
+func (info *BlockInfo) Fields() []output.Field {
+	return []output.Field{
+		{Key: "number", Label: "区块号", Value: info.Number.Uint64()},
+		{Key: "hash", Label: "区块哈希", Value: info.Hash},
+		{Key: "parent_hash", Label: "父区块哈希", Value: info.ParentHash},
+		{Key: "timestamp", Label: "时间戳", Value: info.Timestamp,
+			Text: fmt.Sprintf("%d (%s)", info.Timestamp, time.Unix(int64(info.Timestamp), 0).Format("2006-01-02 15:04:05"))},
+		{Key: "miner", Label: "矿工地址", Value: info.Miner},
+		{Key: "transactions", Label: "交易数量", Value: info.TxCount},
+		{Key: "gas_used", Label: "Gas 使用量", Value: info.GasUsed},
+		{Key: "gas_limit", Label: "Gas 限制", Value: info.GasLimit},
+		{Key: "base_fee_per_gas", Label: "基础费用 (Wei)", Value: info.BaseFee},
+		{Key: "size", Label: "区块大小 (bytes)", Value: info.Size},
+		{Key: "difficulty", Label: "难度", Value: info.Difficulty},
+		{Key: "total_difficulty", Label: "累计难度", Value: info.TotalDifficulty},
+		{Key: "withdrawals_root", Label: "提款根", Value: info.WithdrawalsRoot},
+		{Key: "withdrawals", Label: "提款数量", Value: info.WithdrawalsCount},
+		{Key: "blob_gas_used", Label: "Blob Gas 使用量", Value: info.BlobGasUsed},
+		{Key: "excess_blob_gas", Label: "超额 Blob Gas", Value: info.ExcessBlobGas},
+		{Key: "parent_beacon_block_root", Label: "父信标区块根", Value: info.ParentBeaconRoot},
+	}
+}
+
+// DefaultQueryWorkers 批量查询区块时默认的并发数
+const DefaultQueryWorkers = 8
 
-	log.Printf("开始查询从区块 %d 开始的 %d 个区块...", startBlock, count)
+// BlockRangeResult 批量查询区块的结果
+type BlockRangeResult struct {
+	Blocks []*BlockInfo    // 成功查询的区块，按区块号升序排列
+	Errors map[int64]error // 查询失败的区块号及对应错误
+}
 
+// Failed 返回查询失败的区块数量
+func (r *BlockRangeResult) Failed() int {
+	return len(r.Errors)
+}
+
+// QueryMultipleBlocks 使用有限并发查询从 startBlock 开始的 count 个区块
+// workers <= 0 时使用 DefaultQueryWorkers；单个区块失败记录在 Errors 中，
+// 只有参数无效或 ctx 被取消时才返回 error
+func (c *Client) QueryMultipleBlocks(ctx context.Context, startBlock, count int64, workers int) (*BlockRangeResult, error) {
+	if startBlock < 0 || count <= 0 {
+		return nil, fmt.Errorf("无效的查询范围: 起始区块 %d, 数量 %d", startBlock, count)
+	}
+	if workers <= 0 {
+		workers = DefaultQueryWorkers
+	}
+	if int64(workers) > count {
+		workers = int(count)
+	}
+
+	log.Printf("开始查询从区块 %d 开始的 %d 个区块 (并发数 %d)...", startBlock, count, workers)
+
+	blocks := make([]*BlockInfo, count)
+	errs := make([]error, count)
+
+	jobs := make(chan int64)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				blocks[i], errs[i] = c.queryBlockByNumber(ctx, big.NewInt(startBlock+i))
+			}
+		}()
+	}
+
+dispatch:
 	for i := int64(0); i < count; i++ {
-		blockNumber := big.NewInt(startBlock + i)
-		blockInfo, err := c.QueryBlockByNumber(blockNumber)
-		if err != nil {
-			log.Printf("查询区块 %d 失败: %v", startBlock+i, err)
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("批量查询区块被取消: %w", err)
+	}
+
+	result := &BlockRangeResult{Errors: make(map[int64]error)}
+	for i := int64(0); i < count; i++ {
+		if errs[i] != nil {
+			result.Errors[startBlock+i] = errs[i]
 			continue
 		}
-		blocks = append(blocks, blockInfo)
-		log.Printf("成功查询区块 %d", startBlock+i)
+		result.Blocks = append(result.Blocks, blocks[i])
 	}
 
-	return blocks, nil
+	log.Printf("批量查询完成: 成功 %d 个, 失败 %d 个", len(result.Blocks), result.Failed())
+
+	return result, nil
 }
@@ -0,0 +1,188 @@
+package blockchain
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/local/go-eth-demo/utils"
+)
+
+// MinReplacementBump 节点接受替换交易所需的最小费用涨幅 (百分比)
+const MinReplacementBump = 10
+
+// SpeedUpTransaction 以相同 nonce 和相同内容、更高费用重新广播待处理交易
+// bumpPercent 小于 MinReplacementBump 时按 MinReplacementBump 处理
+func (c *Client) SpeedUpTransaction(signer utils.Signer, txHash string, bumpPercent int64) (*TransactionInfo, error) {
+	return c.replaceTransaction(signer, txHash, bumpPercent, false)
+}
+
+// CancelTransaction 以相同 nonce、更高费用发送 0 值转给自己的交易，使原交易失效
+// bumpPercent 小于 MinReplacementBump 时按 MinReplacementBump 处理
+func (c *Client) CancelTransaction(signer utils.Signer, txHash string, bumpPercent int64) (*TransactionInfo, error) {
+	return c.replaceTransaction(signer, txHash, bumpPercent, true)
+}
+
+// replaceTransaction 构建并广播替换交易
+func (c *Client) replaceTransaction(signer utils.Signer, txHash string, bumpPercent int64, cancel bool) (*TransactionInfo, error) {
+	// 只为已连接节点的链签名，主网需显式允许
+	signer = c.GuardSigner(signer)
+	fromAddress := signer.Address()
+
+	hash, err := parseHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询原交易，只有仍在交易池中的交易可以被替换
+	original, isPending, err := c.client.TransactionByHash(c.ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询原交易失败: %v", err)
+	}
+	if !isPending {
+		return nil, fmt.Errorf("交易 %s 已被打包，无法替换", hash.Hex())
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(c.chainID), original)
+	if err != nil {
+		return nil, fmt.Errorf("恢复原交易发送方失败: %v", err)
+	}
+	if sender != fromAddress {
+		return nil, fmt.Errorf("原交易发送方 %s 与当前签名者地址 %s 不一致", sender.Hex(), fromAddress.Hex())
+	}
+
+	// 只支持可以重新构建的交易类型，blob 交易需要附带 blob 数据，set-code 交易需要重新签署授权
+	switch original.Type() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	default:
+		return nil, fmt.Errorf("不支持替换类型为 %d 的交易 (仅支持传统、访问列表和 EIP-1559 交易)", original.Type())
+	}
+
+	if bumpPercent < MinReplacementBump {
+		bumpPercent = MinReplacementBump
+	}
+
+	fees, err := c.replacementFees(original, bumpPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	// 加速保持原交易内容和访问列表，取消则改为 0 值转给自己
+	to := fromAddress
+	value := big.NewInt(0)
+	gasLimit := uint64(21000)
+	var data []byte
+	var accessList types.AccessList
+	if !cancel {
+		if original.To() == nil {
+			return nil, fmt.Errorf("暂不支持替换合约创建交易")
+		}
+		to = *original.To()
+		value = original.Value()
+		gasLimit = original.Gas()
+		data = original.Data()
+		accessList = original.AccessList()
+	}
+
+	tx := newReplacementTx(original.Type(), c.chainID, original.Nonce(), to, value, gasLimit, fees, data, accessList)
+
+	signedTx, err := signer.SignTx(tx, c.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %v", err)
+	}
+
+	if err := c.client.SendTransaction(c.ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("发送替换交易失败: %v", err)
+	}
+
+	action := "加速"
+	if cancel {
+		action = "取消"
+	}
+	log.Printf("%s交易已发送，nonce %d: %s -> %s", action, original.Nonce(), hash.Hex(), signedTx.Hash().Hex())
+
+	return newTransactionInfo(signedTx, fromAddress), nil
+}
+
+// newReplacementTx 按原交易的类型构建替换交易，只有费用字段与原交易不同
+func newReplacementTx(txType uint8, chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, fees *txFees, data []byte, accessList types.AccessList) *types.Transaction {
+	switch txType {
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasPrice:   fees.GasPrice,
+			Gas:        gasLimit,
+			To:         &to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		})
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasTipCap:  fees.GasTipCap,
+			GasFeeCap:  fees.GasFeeCap,
+			Gas:        gasLimit,
+			To:         &to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		})
+	default:
+		return newTransferTx(chainID, nonce, to, value, gasLimit, fees, data)
+	}
+}
+
+// replacementFees 计算替换交易的费用：在原费用基础上至少上涨 bumpPercent，且不低于当前网络建议值
+// 传统和访问列表交易使用 gasPrice，EIP-1559 交易使用小费和最高费用
+func (c *Client) replacementFees(original *types.Transaction, bumpPercent int64) (*txFees, error) {
+	if original.Type() == types.LegacyTxType || original.Type() == types.AccessListTxType {
+		gasPrice, err := c.client.SuggestGasPrice(c.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取gas价格失败: %v", err)
+		}
+		return &txFees{GasPrice: maxBig(bumpFee(original.GasPrice(), bumpPercent), gasPrice)}, nil
+	}
+
+	header, err := c.client.HeaderByNumber(c.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块头失败: %v", err)
+	}
+	tip, err := c.client.SuggestGasTipCap(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取建议小费失败: %v", err)
+	}
+
+	// 小费和最高费用都必须满足最小涨幅
+	newTip := maxBig(bumpFee(original.GasTipCap(), bumpPercent), tip)
+	newFeeCap := bumpFee(original.GasFeeCap(), bumpPercent)
+	if header.BaseFee != nil {
+		suggested := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+		suggested.Add(suggested, newTip)
+		newFeeCap = maxBig(newFeeCap, suggested)
+	}
+	if newFeeCap.Cmp(newTip) < 0 {
+		newFeeCap = new(big.Int).Set(newTip)
+	}
+
+	return &txFees{Dynamic: true, GasFeeCap: newFeeCap, GasTipCap: newTip}, nil
+}
+
+// bumpFee 将费用上涨 percent 百分比，向上取整
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// maxBig 返回两个大整数中的较大值
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -1,96 +1,174 @@
 package blockchain
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // TransactionInfo 存储交易信息
 type TransactionInfo struct {
-	Hash     string
-	From     string
-	To       string
-	Value    *big.Int
-	GasLimit uint64
-	GasPrice *big.Int
-	Nonce    uint64
-	Data     []byte
+	Hash      string
+	From      string
+	To        string
+	Value     *big.Int
+	GasLimit  uint64
+	GasPrice  *big.Int // 传统交易为 gasPrice，动态费用交易为最高费用
+	GasFeeCap *big.Int // 动态费用交易的最高费用，传统交易为 nil
+	GasTipCap *big.Int // 动态费用交易的小费，传统交易为 nil
+	Type      uint8
+	Nonce     uint64
+	Data      []byte
 }
 
 // SendTransaction 发送以太币转账交易
-func (c *Client) SendTransaction(privateKeyHex, toAddress string, amount *big.Int) (*TransactionInfo, error) {
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+// Gas 限制按节点估算值加余量确定，余额不足以支付金额和最高手续费时不签名
+func (c *Client) SendTransaction(signer utils.Signer, toAddress string, amount *big.Int) (*TransactionInfo, error) {
+	cost, err := c.EstimateTransaction(signer.Address(), toAddress, amount)
 	if err != nil {
-		return nil, fmt.Errorf("解析私钥失败: %v", err)
+		return nil, err
 	}
+	if err := cost.CheckBalance(); err != nil {
+		return nil, err
+	}
+	return c.SendEstimatedTransaction(signer, cost)
+}
 
-	// 获取发送方地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("获取公钥失败")
+// EstimateTransaction 估算转账交易的 Gas 限制和费用，并查询发送方余额，用于签名前预览
+// 接收方为合约时按实际执行估算；普通地址的转账固定为 21000，不加余量
+func (c *Client) EstimateTransaction(from common.Address, toAddress string, amount *big.Int) (*utils.TxCost, error) {
+	if !common.IsHexAddress(toAddress) {
+		return nil, fmt.Errorf("无效的接收方地址: %s", toAddress)
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	to := common.HexToAddress(toAddress)
 
-	// 获取nonce
-	nonce, err := c.client.PendingNonceAt(c.ctx, fromAddress)
+	cost, err := utils.SuggestFees(c.ctx, c.client, c.feeMode)
 	if err != nil {
-		return nil, fmt.Errorf("获取nonce失败: %v", err)
+		return nil, fmt.Errorf("获取建议费用失败: %w", err)
 	}
 
-	// 获取gas价格
-	gasPrice, err := c.client.SuggestGasPrice(c.ctx)
+	msg := ethereum.CallMsg{From: from, To: &to, Value: amount}
+	estimate, gasLimit, err := utils.EstimateGasLimit(c.ctx, c.client, msg, c.gasMargin)
 	if err != nil {
-		return nil, fmt.Errorf("获取gas价格失败: %v", err)
+		return nil, fmt.Errorf("估算Gas失败: %v", err)
+	}
+	if estimate == params.TxGas {
+		gasLimit = estimate
 	}
 
-	// 设置gas限制
-	gasLimit := uint64(21000) // 标准转账的gas限制
+	balance, err := c.client.BalanceAt(c.ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取余额失败: %v", err)
+	}
 
-	// 创建交易
-	toAddr := common.HexToAddress(toAddress)
-	tx := types.NewTransaction(nonce, toAddr, amount, gasLimit, gasPrice, nil)
+	cost.From, cost.To, cost.Value = from, &to, amount
+	cost.GasEstimate, cost.GasLimit = estimate, gasLimit
+	cost.Balance = balance
+	return cost, nil
+}
+
+// SendEstimatedTransaction 按 EstimateTransaction 的结果签名并发送转账交易，
+// 使用预览中的 Gas 限制和费用，保证发送的交易与用户确认的内容一致
+func (c *Client) SendEstimatedTransaction(signer utils.Signer, cost *utils.TxCost) (*TransactionInfo, error) {
+	// 只为已连接节点的链签名，主网需显式允许
+	signer = c.GuardSigner(signer)
+	fromAddress := signer.Address()
+	if fromAddress != cost.From || cost.To == nil {
+		return nil, fmt.Errorf("预览的发送方 %s 与签名者地址 %s 不一致", cost.From.Hex(), fromAddress.Hex())
+	}
 
-	// 获取链ID
-	chainID, err := c.client.ChainID(c.ctx)
+	// 分配nonce，之后的失败路径都需要归还或重新同步
+	nonce, err := c.nonces.Next(c.ctx, fromAddress)
 	if err != nil {
-		return nil, fmt.Errorf("获取链ID失败: %v", err)
+		return nil, fmt.Errorf("获取nonce失败: %v", err)
 	}
 
+	// 创建交易
+	tx := newTransferTx(c.chainID, nonce, *cost.To, cost.Value, cost.GasLimit, feesFromCost(cost), nil)
+
 	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signer.SignTx(tx, c.chainID)
 	if err != nil {
+		c.nonces.Release(fromAddress, nonce)
 		return nil, fmt.Errorf("签名交易失败: %v", err)
 	}
 
 	// 发送交易
 	err = c.client.SendTransaction(c.ctx, signedTx)
 	if err != nil {
+		c.nonces.HandleSendError(c.ctx, fromAddress, nonce, err)
 		return nil, fmt.Errorf("发送交易失败: %v", err)
 	}
 
 	log.Printf("交易已发送，哈希: %s", signedTx.Hash().Hex())
 
 	// 返回交易信息
-	txInfo := &TransactionInfo{
-		Hash:     signedTx.Hash().Hex(),
-		From:     fromAddress.Hex(),
-		To:       toAddress,
-		Value:    amount,
-		GasLimit: gasLimit,
-		GasPrice: gasPrice,
-		Nonce:    nonce,
-		Data:     signedTx.Data(),
+	return newTransactionInfo(signedTx, fromAddress), nil
+}
+
+// feesFromCost 从费用预览中取出构建交易使用的费用参数
+func feesFromCost(cost *utils.TxCost) *txFees {
+	return &txFees{
+		Dynamic:   cost.Dynamic(),
+		GasPrice:  cost.GasPrice,
+		GasFeeCap: cost.GasFeeCap,
+		GasTipCap: cost.GasTipCap,
+		BaseFee:   cost.BaseFee,
+	}
+}
+
+// newTransferTx 根据费用参数创建传统交易或动态费用交易
+func newTransferTx(chainID *big.Int, nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, fees *txFees, data []byte) *types.Transaction {
+	if fees.Dynamic {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       gasLimit,
+			To:        &to,
+			Value:     amount,
+			Data:      data,
+		})
 	}
 
-	return txInfo, nil
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.GasPrice,
+		Gas:      gasLimit,
+		To:       &to,
+		Value:    amount,
+		Data:     data,
+	})
+}
+
+// newTransactionInfo 从已签名交易中提取交易信息
+func newTransactionInfo(tx *types.Transaction, from common.Address) *TransactionInfo {
+	info := &TransactionInfo{
+		Hash:     tx.Hash().Hex(),
+		From:     from.Hex(),
+		Value:    tx.Value(),
+		GasLimit: tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Type:     tx.Type(),
+		Nonce:    tx.Nonce(),
+		Data:     tx.Data(),
+	}
+	if tx.To() != nil {
+		info.To = tx.To().Hex()
+	}
+	if tx.Type() != types.LegacyTxType && tx.Type() != types.AccessListTxType {
+		info.GasFeeCap = tx.GasFeeCap()
+		info.GasTipCap = tx.GasTipCap()
+	}
+	return info
 }
 
 // GetBalance 获取地址余额
@@ -110,25 +188,47 @@ func (info *TransactionInfo) PrintTransactionInfo() {
 	fmt.Printf("发送方: %s\n", info.From)
 	fmt.Printf("接收方: %s\n", info.To)
 	fmt.Printf("转账金额: %s Wei\n", info.Value.String())
-	fmt.Printf("转账金额: %s ETH\n", weiToEther(info.Value).String())
+	fmt.Printf("转账金额: %s ETH\n", utils.WeiToEther(info.Value))
 	fmt.Printf("Gas限制: %d\n", info.GasLimit)
-	fmt.Printf("Gas价格: %s Wei\n", info.GasPrice.String())
+	if info.GasFeeCap != nil {
+		fmt.Printf("交易类型: EIP-1559 (type %d)\n", info.Type)
+		fmt.Printf("最高费用: %s Wei\n", info.GasFeeCap.String())
+		fmt.Printf("小费: %s Wei\n", info.GasTipCap.String())
+	} else {
+		fmt.Printf("Gas价格: %s Wei\n", info.GasPrice.String())
+	}
 	fmt.Printf("Nonce: %d\n", info.Nonce)
 	fmt.Println("================================================")
 }
 
-// weiToEther 将Wei转换为Ether
-func weiToEther(wei *big.Int) *big.Float {
-	ether := new(big.Float)
-	ether.SetString(wei.String())
-	return ether.Quo(ether, big.NewFloat(1e18))
+// Fields 实现 output.Record，字段名与 go-eth-demo 的 output.Transaction 保持一致
+// 动态费用交易不输出 gas_price，对应的费用见 max_fee_per_gas 和 max_priority_fee_per_gas
+func (info *TransactionInfo) Fields() []output.Field {
+	var gasPrice *big.Int
+	if info.GasFeeCap == nil {
+		gasPrice = info.GasPrice
+	}
+	var to interface{}
+	if info.To != "" {
+		to = info.To
+	}
+	return []output.Field{
+		{Key: "hash", Label: "交易哈希", Value: info.Hash},
+		{Key: "type", Label: "交易类型", Value: info.Type},
+		{Key: "from", Label: "发送方", Value: info.From},
+		{Key: "to", Label: "接收方", Value: to},
+		{Key: "value", Label: "转账金额 (Wei)", Value: info.Value},
+		{Key: "nonce", Label: "Nonce", Value: info.Nonce},
+		{Key: "gas", Label: "Gas限制", Value: info.GasLimit},
+		{Key: "gas_price", Label: "Gas价格 (Wei)", Value: gasPrice},
+		{Key: "max_fee_per_gas", Label: "最高费用 (Wei)", Value: info.GasFeeCap},
+		{Key: "max_priority_fee_per_gas", Label: "小费 (Wei)", Value: info.GasTipCap},
+		{Key: "data_size", Label: "数据长度", Value: len(info.Data)},
+	}
 }
 
-// EtherToWei 将Ether转换为Wei
-func EtherToWei(ether float64) *big.Int {
-	etherBig := big.NewFloat(ether)
-	weiBig := new(big.Float).Mul(etherBig, big.NewFloat(1e18))
-	wei := new(big.Int)
-	weiBig.Int(wei)
-	return wei
+// EtherToWei 将十进制字符串表示的 Ether 精确转换为 Wei，如 "0.1" 转换为 1e17
+// 小数超过 18 位或格式不合法时返回错误
+func EtherToWei(ether string) (*big.Int, error) {
+	return utils.ParseEther(ether)
 }
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/local/go-eth-demo/utils"
 )
 
 // CounterManager 管理Counter合约交互
@@ -19,10 +22,15 @@ type CounterManager struct {
 	auth     *bind.TransactOpts
 	address  common.Address
 	ctx      context.Context
+	abi      *abi.ABI
+
+	// 交易确认等待配置，为 nil 时发送后立即返回
+	waitOpts    *utils.WaitOptions
+	waitTimeout time.Duration
 }
 
-// NewCounterManager 创建新的Counter合约管理器
-func NewCounterManager(client *ethclient.Client, contractAddress string, privateKeyHex string) (*CounterManager, error) {
+// NewCounterManager 创建新的Counter合约管理器，signer 为 nil 时只能调用只读方法
+func NewCounterManager(client *ethclient.Client, contractAddress string, signer utils.Signer) (*CounterManager, error) {
 	ctx := context.Background()
 
 	// 解析合约地址
@@ -34,23 +42,21 @@ func NewCounterManager(client *ethclient.Client, contractAddress string, private
 		return nil, fmt.Errorf("创建合约实例失败: %v", err)
 	}
 
+	// 解析 ABI，用于解码回滚原因
+	parsedABI, err := ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("解析合约ABI失败: %v", err)
+	}
+
 	// 创建交易授权
 	var auth *bind.TransactOpts
-	if privateKeyHex != "" {
-		privateKey, err := crypto.HexToECDSA(privateKeyHex)
-		if err != nil {
-			return nil, fmt.Errorf("解析私钥失败: %v", err)
-		}
-
+	if signer != nil {
 		chainID, err := client.ChainID(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("获取链ID失败: %v", err)
 		}
 
-		auth, err = bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-		if err != nil {
-			return nil, fmt.Errorf("创建交易授权失败: %v", err)
-		}
+		auth = utils.NewTransactOpts(signer, chainID)
 	}
 
 	return &CounterManager{
@@ -59,6 +65,7 @@ func NewCounterManager(client *ethclient.Client, contractAddress string, private
 		auth:     auth,
 		address:  address,
 		ctx:      ctx,
+		abi:      parsedABI,
 	}, nil
 }
 
@@ -89,33 +96,82 @@ func (cm *CounterManager) GetOwner() (common.Address, error) {
 	return owner, nil
 }
 
+// EnableConfirmationWait 使 Increment/Add 在发送后等待交易达到指定确认数
+// 交易执行失败、被替换或被丢弃时返回错误
+func (cm *CounterManager) EnableConfirmationWait(confirmations uint64, timeout time.Duration) {
+	cm.waitOpts = &utils.WaitOptions{Confirmations: confirmations}
+	cm.waitTimeout = timeout
+}
+
+// DisableConfirmationWait 恢复为发送后立即返回
+func (cm *CounterManager) DisableConfirmationWait() {
+	cm.waitOpts = nil
+}
+
+// waitForConfirmation 在启用确认等待时等待交易结果
+func (cm *CounterManager) waitForConfirmation(tx *types.Transaction) error {
+	if cm.waitOpts == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cm.ctx, cm.waitTimeout)
+	defer cancel()
+
+	opts := *cm.waitOpts
+	opts.From = cm.auth.From
+
+	result, err := utils.WaitForTransaction(ctx, cm.client, tx, opts)
+	if err != nil {
+		return fmt.Errorf("等待交易确认失败: %v", err)
+	}
+	if result.Status == utils.TxStatusReverted {
+		revertErr, err := utils.ReplayRevertReason(ctx, cm.client, tx, result.Receipt, cm.abi)
+		if err != nil {
+			return fmt.Errorf("交易执行失败 (无法获取回滚原因: %v): %w", err, result.Err())
+		}
+		return fmt.Errorf("交易执行失败: %w", revertErr)
+	}
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("交易未成功: %w", err)
+	}
+
+	log.Printf("交易 %s 已确认 (确认数 %d)", tx.Hash().Hex(), result.Confirmations)
+	return nil
+}
+
 // Increment 增加计数器
 func (cm *CounterManager) Increment() (string, error) {
 	if cm.auth == nil {
-		return "", fmt.Errorf("未配置私钥，无法发送交易")
+		return "", fmt.Errorf("未配置签名者，无法发送交易")
 	}
 
 	tx, err := cm.contract.Increment(cm.auth)
 	if err != nil {
-		return "", fmt.Errorf("增加计数器失败: %v", err)
+		return "", fmt.Errorf("增加计数器失败: %w", utils.ExplainCallError(err, cm.abi))
 	}
 
 	log.Printf("增加计数器交易已发送: %s", tx.Hash().Hex())
+	if err := cm.waitForConfirmation(tx); err != nil {
+		return tx.Hash().Hex(), err
+	}
 	return tx.Hash().Hex(), nil
 }
 
 // Add 增加指定数量
 func (cm *CounterManager) Add(value *big.Int) (string, error) {
 	if cm.auth == nil {
-		return "", fmt.Errorf("未配置私钥，无法发送交易")
+		return "", fmt.Errorf("未配置签名者，无法发送交易")
 	}
 
 	tx, err := cm.contract.Add(cm.auth, value)
 	if err != nil {
-		return "", fmt.Errorf("增加数量失败: %v", err)
+		return "", fmt.Errorf("增加数量失败: %w", utils.ExplainCallError(err, cm.abi))
 	}
 
 	log.Printf("增加数量 %s 交易已发送: %s", value.String(), tx.Hash().Hex())
+	if err := cm.waitForConfirmation(tx); err != nil {
+		return tx.Hash().Hex(), err
+	}
 	return tx.Hash().Hex(), nil
 }
 
@@ -136,15 +192,9 @@ func (cm *CounterManager) PrintContractInfo() {
 }
 
 // DeployCounter 部署Counter合约
-func DeployCounter(client *ethclient.Client, privateKeyHex string, initialValue *big.Int) (common.Address, string, error) {
+func DeployCounter(client *ethclient.Client, signer utils.Signer, initialValue *big.Int) (common.Address, string, error) {
 	ctx := context.Background()
 
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return common.Address{}, "", fmt.Errorf("解析私钥失败: %v", err)
-	}
-
 	// 获取链ID
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
@@ -152,10 +202,7 @@ func DeployCounter(client *ethclient.Client, privateKeyHex string, initialValue
 	}
 
 	// 创建交易授权
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	if err != nil {
-		return common.Address{}, "", fmt.Errorf("创建交易授权失败: %v", err)
-	}
+	auth := utils.NewTransactOpts(signer, chainID)
 
 	// 部署合约
 	address, tx, _, err := DeployContracts(auth, client, initialValue)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -9,8 +10,9 @@ import (
 	"strings"
 
 	"github.com/local/dapp-basics-task01/blockchain"
-	"github.com/local/dapp-basics-task01/config"
 	"github.com/local/dapp-basics-task01/contracts"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/utils"
 )
 
 func main() {
@@ -18,16 +20,26 @@ func main() {
 	fmt.Println("=====================================")
 
 	// 加载配置
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 	fmt.Printf("📡 连接网络: %s\n", cfg.NetworkName)
 
 	// 创建区块链客户端
-	client, err := blockchain.NewClient(cfg.EthereumRPCURL)
+	client, err := blockchain.NewClientFromConfig(cfg)
 	if err != nil {
 		log.Fatalf("创建客户端失败: %v", err)
 	}
 	defer client.Close()
 
+	// 加载签名者，未配置时跳过需要发送交易的演示
+	signer, err := utils.NewSignerFromConfig(cfg)
+	if err != nil && !errors.Is(err, utils.ErrNoSigner) {
+		log.Fatalf("加载签名者失败: %v", err)
+	}
+	signer = client.GuardSigner(signer)
+
 	// 显示演示菜单
 	showDemoMenu()
 
@@ -44,13 +56,13 @@ func main() {
 		case "1":
 			demoBlockQuery(client)
 		case "2":
-			demoTransaction(client, cfg)
+			demoTransaction(client, cfg, signer)
 		case "3":
-			demoContractDeploy(client, cfg)
+			demoContractDeploy(client, signer)
 		case "4":
-			demoContractInteraction(client, cfg, scanner)
+			demoContractInteraction(client, signer, scanner)
 		case "5":
-			demoFullWorkflow(client, cfg)
+			demoFullWorkflow(client, signer)
 		case "6":
 			showDemoMenu()
 		case "0":
@@ -96,11 +108,11 @@ func demoBlockQuery(client *blockchain.Client) {
 	blockInfo.PrintBlockInfo()
 }
 
-func demoTransaction(client *blockchain.Client, cfg *config.Config) {
+func demoTransaction(client *blockchain.Client, cfg *config.Config, signer utils.Signer) {
 	fmt.Println("\n💸 === 转账交易演示 ===")
 
-	if cfg.PrivateKey == "" {
-		fmt.Println("❌ 未配置私钥，跳过转账演示")
+	if signer == nil {
+		fmt.Println("❌ 未配置签名者，跳过转账演示")
 		return
 	}
 
@@ -110,10 +122,14 @@ func demoTransaction(client *blockchain.Client, cfg *config.Config) {
 	}
 
 	// 发送小额转账 (0.001 ETH)
-	amount := blockchain.EtherToWei(0.001)
+	amount, err := blockchain.EtherToWei("0.001")
+	if err != nil {
+		log.Printf("金额转换失败: %v", err)
+		return
+	}
 	fmt.Printf("发送 0.001 ETH 到 %s\n", cfg.ToAddress)
 
-	txInfo, err := client.SendTransaction(cfg.PrivateKey, cfg.ToAddress, amount)
+	txInfo, err := client.SendTransaction(signer, cfg.ToAddress, amount)
 	if err != nil {
 		log.Printf("发送交易失败: %v", err)
 		return
@@ -123,11 +139,11 @@ func demoTransaction(client *blockchain.Client, cfg *config.Config) {
 	fmt.Printf("🔗 查看交易: https://sepolia.etherscan.io/tx/%s\n", txInfo.Hash)
 }
 
-func demoContractDeploy(client *blockchain.Client, cfg *config.Config) {
+func demoContractDeploy(client *blockchain.Client, signer utils.Signer) {
 	fmt.Println("\n🚀 === 合约部署演示 ===")
 
-	if cfg.PrivateKey == "" {
-		fmt.Println("❌ 未配置私钥，跳过合约部署演示")
+	if signer == nil {
+		fmt.Println("❌ 未配置签名者，跳过合约部署演示")
 		return
 	}
 
@@ -135,7 +151,7 @@ func demoContractDeploy(client *blockchain.Client, cfg *config.Config) {
 	initialValue := big.NewInt(42)
 	fmt.Printf("部署Counter合约，初始值: %s\n", initialValue.String())
 
-	address, txHash, err := contracts.DeployCounter(client.GetClient(), cfg.PrivateKey, initialValue)
+	address, txHash, err := contracts.DeployCounter(client.GetClient(), signer, initialValue)
 	if err != nil {
 		log.Printf("部署合约失败: %v", err)
 		return
@@ -150,7 +166,7 @@ func demoContractDeploy(client *blockchain.Client, cfg *config.Config) {
 	saveContractAddress(address.Hex())
 }
 
-func demoContractInteraction(client *blockchain.Client, cfg *config.Config, scanner *bufio.Scanner) {
+func demoContractInteraction(client *blockchain.Client, signer utils.Signer, scanner *bufio.Scanner) {
 	fmt.Println("\n🔧 === 合约交互演示 ===")
 
 	fmt.Print("请输入合约地址: ")
@@ -165,7 +181,7 @@ func demoContractInteraction(client *blockchain.Client, cfg *config.Config, scan
 	}
 
 	// 创建合约管理器
-	counterManager, err := contracts.NewCounterManager(client.GetClient(), contractAddress, cfg.PrivateKey)
+	counterManager, err := contracts.NewCounterManager(client.GetClient(), contractAddress, signer)
 	if err != nil {
 		log.Printf("创建合约管理器失败: %v", err)
 		return
@@ -174,8 +190,8 @@ func demoContractInteraction(client *blockchain.Client, cfg *config.Config, scan
 	// 显示合约信息
 	counterManager.PrintContractInfo()
 
-	// 如果有私钥，演示写操作
-	if cfg.PrivateKey != "" {
+	// 如果有签名者，演示写操作
+	if signer != nil {
 		fmt.Println("\n执行合约操作:")
 
 		// 增加计数器
@@ -207,11 +223,11 @@ func demoContractInteraction(client *blockchain.Client, cfg *config.Config, scan
 	}
 }
 
-func demoFullWorkflow(client *blockchain.Client, cfg *config.Config) {
+func demoFullWorkflow(client *blockchain.Client, signer utils.Signer) {
 	fmt.Println("\n🎯 === 完整工作流演示 ===")
 
-	if cfg.PrivateKey == "" {
-		fmt.Println("❌ 未配置私钥，无法执行完整工作流")
+	if signer == nil {
+		fmt.Println("❌ 未配置签名者，无法执行完整工作流")
 		return
 	}
 
@@ -225,7 +241,7 @@ func demoFullWorkflow(client *blockchain.Client, cfg *config.Config) {
 
 	fmt.Println("\n步骤2: 部署Counter合约")
 	initialValue := big.NewInt(100)
-	address, txHash, err := contracts.DeployCounter(client.GetClient(), cfg.PrivateKey, initialValue)
+	address, txHash, err := contracts.DeployCounter(client.GetClient(), signer, initialValue)
 	if err != nil {
 		log.Printf("部署合约失败: %v", err)
 		return
@@ -234,7 +250,7 @@ func demoFullWorkflow(client *blockchain.Client, cfg *config.Config) {
 	fmt.Printf("✅ 部署交易: %s\n", txHash)
 
 	fmt.Println("\n步骤3: 与合约交互")
-	counterManager, err := contracts.NewCounterManager(client.GetClient(), address.Hex(), cfg.PrivateKey)
+	counterManager, err := contracts.NewCounterManager(client.GetClient(), address.Hex(), signer)
 	if err != nil {
 		log.Printf("创建合约管理器失败: %v", err)
 		return
@@ -2,40 +2,286 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/local/dapp-basics-task01/blockchain"
-	"github.com/local/dapp-basics-task01/config"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/output"
+	"github.com/local/go-eth-demo/utils"
 )
 
+// maxBlockQueryCount 单次批量查询允许的最大区块数量
+const maxBlockQueryCount = 1000
+
+// outputFormat 查询结果的输出格式，由 -o 参数指定
+var outputFormat = output.FormatText
+
+// ui 提示和菜单的输出位置，非文本格式时写到 stderr，保证 stdout 只包含结果
+var ui io.Writer = os.Stdout
+
+// stdin 菜单、批处理和确认提示共用的标准输入，避免各自缓冲导致输入被提前读走
+var stdin = bufio.NewScanner(os.Stdin)
+
+// stdinIsBatch 批处理文件从标准输入读取时为 true，此时无法交互确认，需使用 -yes
+var stdinIsBatch bool
+
+// app 各操作共用的客户端、配置和签名者
+type app struct {
+	client *blockchain.Client // 离线命令执行时为 nil
+	cfg    *config.Config
+	signer utils.Signer // 未配置时为 nil，只能使用查询功能
+}
+
+// command 非交互模式下的子命令
+type command struct {
+	name    string
+	usage   string
+	desc    string
+	offline bool // 不需要连接节点，可在离线机器上执行
+	run     func(a *app, args []string) error
+}
+
+// commands 支持的子命令，也是批处理文件中每行可用的命令
+var commands = []command{
+	{name: "block", usage: "block [latest|<区块号>|<区块哈希>]", desc: "查询区块，默认最新区块", run: (*app).runBlock},
+	{name: "blocks", usage: "blocks <起始区块号> <数量>", desc: "查询多个区块", run: (*app).runBlocks},
+	{name: "balance", usage: "balance <地址>", desc: "查询地址余额", run: (*app).runBalance},
+	{name: "send", usage: "send [-to <地址>] -amount <ETH> [-yes]", desc: "估算 Gas 和手续费，确认后发送转账交易，-to 默认使用 TO_ADDRESS", run: (*app).runSend},
+	{name: "speedup", usage: "speedup <交易哈希> [-bump <百分比>]", desc: "加速待处理交易", run: (*app).runSpeedUp},
+	{name: "cancel", usage: "cancel <交易哈希> [-bump <百分比>]", desc: "取消待处理交易", run: (*app).runCancel},
+	{name: "build", usage: "build [-from <地址>] [-to <地址>] -amount <ETH> [-gas-limit <数量>] -out <文件>", desc: "构建未签名交易并写入文件，nonce、Gas 限制和费用从节点获取", run: (*app).runBuild},
+	{name: "sign", usage: "sign <文件> -out <文件> [-keystore <文件>] [-password-file <文件>] [-yes]", desc: "离线签名交易文件，默认使用配置中的签名者", offline: true, run: (*app).runSign},
+	{name: "broadcast", usage: "broadcast <文件>", desc: "通过 eth_sendRawTransaction 广播已签名的交易文件", run: (*app).runBroadcast},
+	{name: "decode", usage: "decode <文件>", desc: "显示交易文件的内容和签名哈希", offline: true, run: (*app).runDecode},
+}
+
 func main() {
-	fmt.Println("🚀 DApp基础任务 - 区块链读写演示")
-	fmt.Println("=====================================")
+	os.Exit(run())
+}
 
-	// 加载配置
-	cfg := config.LoadConfig()
-	fmt.Printf("📡 连接网络: %s\n", cfg.NetworkName)
-	fmt.Printf("🔗 RPC URL: %s\n", cfg.EthereumRPCURL)
+// run 解析参数并执行，返回进程退出码
+// 不带子命令时进入交互式菜单；带子命令时执行一次后退出；-batch 时依次执行文件中的命令
+func run() int {
+	formatName := flag.String("o", "text", "输出格式: "+strings.Join(output.Formats(), ", "))
+	batchFile := flag.String("batch", "", "从文件读取命令并依次执行，- 表示标准输入")
+	stopOnError := flag.Bool("stop-on-error", false, "批处理模式下遇到失败的命令立即停止")
+	flag.Usage = usage
+	flag.Parse()
 
-	// 创建区块链客户端
-	client, err := blockchain.NewClient(cfg.EthereumRPCURL)
+	format, err := output.ParseFormat(*formatName)
 	if err != nil {
-		log.Fatalf("创建客户端失败: %v", err)
+		fmt.Fprintf(os.Stderr, "参数错误: %v\n", err)
+		return 2
+	}
+	outputFormat = format
+	if outputFormat != output.FormatText {
+		ui = os.Stderr
+	}
+
+	interactive := *batchFile == "" && flag.NArg() == 0
+	if flag.NArg() > 0 && flag.Arg(0) == "help" {
+		usage()
+		return 0
+	}
+	if flag.NArg() > 0 && findCommand(flag.Arg(0)) == nil {
+		fmt.Fprintf(os.Stderr, "❌ 未知命令: %s\n", flag.Arg(0))
+		usage()
+		return 2
+	}
+
+	if interactive {
+		fmt.Fprintln(ui, "🚀 DApp基础任务 - 区块链读写演示")
+		fmt.Fprintln(ui, "=====================================")
 	}
-	defer client.Close()
 
-	// 显示菜单
+	var a *app
+	if c := findCommand(flag.Arg(0)); *batchFile == "" && c != nil && c.offline {
+		a, err = newOfflineApp()
+	} else {
+		a, err = newApp(interactive)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer a.close()
+
+	switch {
+	case *batchFile != "":
+		return a.runBatch(*batchFile, *stopOnError)
+	case !interactive:
+		if err := a.execute(flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	a.runMenu()
+	return 0
+}
+
+// usage 打印命令行用法
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "用法:")
+	fmt.Fprintln(out, "  task01 [-o 格式]                      交互式菜单")
+	fmt.Fprintln(out, "  task01 [-o 格式] <命令> [参数]        执行一个命令")
+	fmt.Fprintln(out, "  task01 [-o 格式] -batch <文件>        依次执行文件中的命令 (每行一个，# 开头为注释)")
+	fmt.Fprintln(out, "\n命令:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %s\n        %s\n", c.usage, c.desc)
+	}
+	fmt.Fprintln(out, "\n选项:")
+	flag.PrintDefaults()
+}
+
+// newApp 加载配置、连接节点并加载签名者，verbose 时打印连接信息
+func newApp(verbose bool) (*app, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	if verbose {
+		fmt.Fprintf(ui, "📡 连接网络: %s\n", cfg.NetworkName)
+		fmt.Fprintf(ui, "🔗 RPC URL: %s\n", cfg.EthereumRPCURL)
+	}
+
+	client, err := blockchain.NewClientFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建客户端失败: %w", err)
+	}
+
+	feeMode, err := config.ParseFeeMode(cfg.FeeMode)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("配置错误: %w", err)
+	}
+	client.SetFeeMode(feeMode)
+
+	// 加载签名者，未配置时只能使用查询功能
+	signer, err := utils.NewSignerFromConfig(cfg)
+	if err != nil && !errors.Is(err, utils.ErrNoSigner) {
+		client.Close()
+		return nil, fmt.Errorf("加载签名者失败: %w", err)
+	}
+	signer = client.GuardSigner(signer)
+	if utils.IsMainnet(client.ChainID()) && !cfg.AllowMainnet {
+		fmt.Fprintln(os.Stderr, "⚠️  已连接以太坊主网，未设置 ALLOW_MAINNET=true，所有交易签名将被拒绝")
+	}
+
+	return &app{client: client, cfg: cfg, signer: signer}, nil
+}
+
+// newOfflineApp 只加载配置和签名者，不连接节点，用于离线命令
+func newOfflineApp() (*app, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	signer, err := utils.NewSignerFromConfig(cfg)
+	if err != nil && !errors.Is(err, utils.ErrNoSigner) {
+		return nil, fmt.Errorf("加载签名者失败: %w", err)
+	}
+	return &app{cfg: cfg, signer: signer}, nil
+}
+
+// close 关闭节点连接，离线模式下没有连接
+func (a *app) close() {
+	if a.client != nil {
+		a.client.Close()
+	}
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// execute 执行一个子命令，args[0] 为命令名
+func (a *app) execute(args []string) error {
+	if len(args) == 0 {
+		return errors.New("缺少命令")
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		return fmt.Errorf("未知命令: %s", args[0])
+	}
+	if !c.offline && a.client == nil {
+		return fmt.Errorf("%s 命令需要连接节点", c.name)
+	}
+	return c.run(a, args[1:])
+}
+
+// runBatch 依次执行批处理文件中的命令并报告每条命令的结果
+// 有命令失败时返回 1；stopOnError 为 true 时在第一个失败处停止
+func (a *app) runBatch(path string, stopOnError bool) int {
+	scanner := stdin
+	if path == "-" {
+		stdinIsBatch = true
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ 打开批处理文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		scanner = bufio.NewScanner(f)
+	}
+
+	succeeded, failed := 0, 0
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fmt.Fprintf(ui, "\n▶ [第 %d 行] %s\n", lineNo, line)
+		if err := a.execute(strings.Fields(line)); err != nil {
+			failed++
+			fmt.Fprintf(ui, "❌ [第 %d 行] 失败: %v\n", lineNo, err)
+			if stopOnError {
+				break
+			}
+			continue
+		}
+		succeeded++
+		fmt.Fprintf(ui, "✅ [第 %d 行] 成功\n", lineNo)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 读取批处理文件失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(ui, "\n📊 批处理完成: 成功 %d 个, 失败 %d 个\n", succeeded, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runMenu 交互式菜单，从标准输入读取选择和参数
+func (a *app) runMenu() {
 	showMenu()
 
-	// 处理用户输入
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := stdin
 	for {
-		fmt.Print("\n请选择操作 (输入数字): ")
+		fmt.Fprint(ui, "\n请选择操作 (输入数字): ")
 		if !scanner.Scan() {
 			break
 		}
@@ -43,179 +289,620 @@ func main() {
 		choice := strings.TrimSpace(scanner.Text())
 		switch choice {
 		case "1":
-			queryLatestBlock(client)
+			a.queryLatestBlock()
 		case "2":
-			queryBlockByNumber(client, scanner)
+			a.queryBlockByNumber(scanner)
 		case "3":
-			queryMultipleBlocks(client, scanner)
+			a.queryMultipleBlocks(scanner)
 		case "4":
-			checkBalance(client, scanner)
+			a.checkBalance(scanner)
 		case "5":
-			sendTransaction(client, cfg, scanner)
+			a.sendTransaction(scanner)
 		case "6":
 			showMenu()
+		case "7":
+			a.queryBlockByHash(scanner)
+		case "8":
+			a.replaceTransaction(scanner, false)
+		case "9":
+			a.replaceTransaction(scanner, true)
 		case "0":
-			fmt.Println("👋 再见！")
+			fmt.Fprintln(ui, "👋 再见！")
 			return
 		default:
-			fmt.Println("❌ 无效选择，请重新输入")
+			fmt.Fprintln(ui, "❌ 无效选择，请重新输入")
 		}
 	}
 }
 
 func showMenu() {
-	fmt.Println("\n📋 可用操作:")
-	fmt.Println("1. 查询最新区块")
-	fmt.Println("2. 查询指定区块")
-	fmt.Println("3. 查询多个区块")
-	fmt.Println("4. 查询地址余额")
-	fmt.Println("5. 发送转账交易")
-	fmt.Println("6. 显示菜单")
-	fmt.Println("0. 退出")
+	fmt.Fprintln(ui, "\n📋 可用操作:")
+	fmt.Fprintln(ui, "1. 查询最新区块")
+	fmt.Fprintln(ui, "2. 查询指定区块")
+	fmt.Fprintln(ui, "3. 查询多个区块")
+	fmt.Fprintln(ui, "4. 查询地址余额")
+	fmt.Fprintln(ui, "5. 发送转账交易")
+	fmt.Fprintln(ui, "6. 显示菜单")
+	fmt.Fprintln(ui, "7. 根据哈希查询区块")
+	fmt.Fprintln(ui, "8. 加速待处理交易")
+	fmt.Fprintln(ui, "9. 取消待处理交易")
+	fmt.Fprintln(ui, "0. 退出")
 }
 
-func queryLatestBlock(client *blockchain.Client) {
-	fmt.Println("\n🔍 查询最新区块...")
-	blockInfo, err := client.QueryLatestBlock()
-	if err != nil {
-		log.Printf("查询失败: %v", err)
-		return
+// prompt 打印提示并读取一行输入，输入结束时返回 false
+func prompt(scanner *bufio.Scanner, message string) (string, bool) {
+	fmt.Fprint(ui, message)
+	if !scanner.Scan() {
+		return "", false
 	}
-	blockInfo.PrintBlockInfo()
+	return strings.TrimSpace(scanner.Text()), true
 }
 
-func queryBlockByNumber(client *blockchain.Client, scanner *bufio.Scanner) {
-	fmt.Print("请输入区块号: ")
-	if !scanner.Scan() {
-		return
+// reportError 打印交互式菜单中操作失败的原因
+func reportError(err error) {
+	fmt.Fprintf(ui, "❌ %v\n", err)
+}
+
+func (a *app) queryLatestBlock() {
+	fmt.Fprintln(ui, "\n🔍 查询最新区块...")
+	if err := a.runBlock(nil); err != nil {
+		reportError(err)
 	}
+}
 
-	blockNumberStr := strings.TrimSpace(scanner.Text())
-	blockNumber, ok := new(big.Int).SetString(blockNumberStr, 10)
+func (a *app) queryBlockByNumber(scanner *bufio.Scanner) {
+	blockNumber, ok := prompt(scanner, "请输入区块号: ")
 	if !ok {
-		fmt.Println("❌ 无效的区块号")
 		return
 	}
 
-	fmt.Printf("\n🔍 查询区块 %s...\n", blockNumber.String())
-	blockInfo, err := client.QueryBlockByNumber(blockNumber)
-	if err != nil {
-		log.Printf("查询失败: %v", err)
+	fmt.Fprintf(ui, "\n🔍 查询区块 %s...\n", blockNumber)
+	if err := a.runBlock([]string{blockNumber}); err != nil {
+		reportError(err)
+	}
+}
+
+func (a *app) queryBlockByHash(scanner *bufio.Scanner) {
+	blockHash, ok := prompt(scanner, "请输入区块哈希: ")
+	if !ok {
 		return
 	}
-	blockInfo.PrintBlockInfo()
+	if blockHash == "" {
+		fmt.Fprintln(ui, "❌ 区块哈希不能为空")
+		return
+	}
+
+	fmt.Fprintf(ui, "\n🔍 查询区块 %s...\n", blockHash)
+	if err := a.runBlock([]string{blockHash}); err != nil {
+		reportError(err)
+	}
 }
 
-func queryMultipleBlocks(client *blockchain.Client, scanner *bufio.Scanner) {
-	fmt.Print("请输入起始区块号: ")
-	if !scanner.Scan() {
+func (a *app) queryMultipleBlocks(scanner *bufio.Scanner) {
+	startBlock, ok := prompt(scanner, "请输入起始区块号: ")
+	if !ok {
 		return
 	}
-	startBlockStr := strings.TrimSpace(scanner.Text())
-	startBlock, err := strconv.ParseInt(startBlockStr, 10, 64)
-	if err != nil {
-		fmt.Println("❌ 无效的起始区块号")
+	count, ok := prompt(scanner, "请输入查询数量: ")
+	if !ok {
 		return
 	}
 
-	fmt.Print("请输入查询数量: ")
-	if !scanner.Scan() {
+	fmt.Fprintf(ui, "\n🔍 查询从区块 %s 开始的 %s 个区块...\n", startBlock, count)
+	if err := a.runBlocks([]string{startBlock, count}); err != nil {
+		reportError(err)
+	}
+}
+
+func (a *app) checkBalance(scanner *bufio.Scanner) {
+	address, ok := prompt(scanner, "请输入地址: ")
+	if !ok {
 		return
 	}
-	countStr := strings.TrimSpace(scanner.Text())
-	count, err := strconv.ParseInt(countStr, 10, 64)
-	if err != nil || count <= 0 || count > 10 {
-		fmt.Println("❌ 无效的查询数量 (1-10)")
+
+	fmt.Fprintf(ui, "\n💰 查询地址余额: %s\n", address)
+	if err := a.runBalance([]string{address}); err != nil {
+		reportError(err)
+	}
+}
+
+func (a *app) sendTransaction(scanner *bufio.Scanner) {
+	if a.signer == nil {
+		reportError(errNoSigner)
 		return
 	}
 
-	fmt.Printf("\n🔍 查询从区块 %d 开始的 %d 个区块...\n", startBlock, count)
-	blocks, err := client.QueryMultipleBlocks(startBlock, count)
-	if err != nil {
-		log.Printf("查询失败: %v", err)
+	toAddress, ok := prompt(scanner, "请输入接收方地址 (留空使用默认): ")
+	if !ok {
+		return
+	}
+	amount, ok := prompt(scanner, "请输入转账金额 (ETH): ")
+	if !ok {
 		return
 	}
 
-	for i, block := range blocks {
-		fmt.Printf("\n--- 区块 %d ---", i+1)
-		block.PrintBlockInfo()
+	args := []string{"-amount", amount}
+	if toAddress != "" {
+		args = append(args, "-to", toAddress)
+	}
+	if err := a.runSend(args); err != nil {
+		reportError(err)
 	}
 }
 
-func checkBalance(client *blockchain.Client, scanner *bufio.Scanner) {
-	fmt.Print("请输入地址: ")
-	if !scanner.Scan() {
+func (a *app) replaceTransaction(scanner *bufio.Scanner, cancel bool) {
+	if a.signer == nil {
+		reportError(errNoSigner)
 		return
 	}
 
-	address := strings.TrimSpace(scanner.Text())
-	if address == "" {
-		fmt.Println("❌ 地址不能为空")
+	txHash, ok := prompt(scanner, "请输入待处理交易哈希: ")
+	if !ok {
+		return
+	}
+	bump, ok := prompt(scanner, fmt.Sprintf("请输入费用涨幅百分比 (留空使用 %d%%): ", blockchain.MinReplacementBump))
+	if !ok {
 		return
 	}
 
-	fmt.Printf("\n💰 查询地址余额: %s\n", address)
-	balance, err := client.GetBalance(address)
+	args := []string{txHash}
+	if bump != "" {
+		args = append(args, "-bump", bump)
+	}
+
+	var err error
+	if cancel {
+		err = a.runCancel(args)
+	} else {
+		err = a.runSpeedUp(args)
+	}
+	if err != nil {
+		reportError(err)
+	}
+}
+
+// errNoSigner 发送交易时未配置签名者
+var errNoSigner = errors.New("未配置签名者，无法发送交易，请在 .env 文件中设置 KEYSTORE_PATH/KEYSTORE_PASSWORD 或 PRIVATE_KEY")
+
+// runBlock 查询区块，参数为 latest、区块号或区块哈希
+func (a *app) runBlock(args []string) error {
+	if len(args) > 1 {
+		return errors.New("用法: block [latest|<区块号>|<区块哈希>]")
+	}
+	id := "latest"
+	if len(args) == 1 && args[0] != "" {
+		id = args[0]
+	}
+
+	var blockInfo *blockchain.BlockInfo
+	var err error
+	switch {
+	case id == "latest":
+		blockInfo, err = a.client.QueryLatestBlock()
+	case strings.HasPrefix(id, "0x"):
+		blockInfo, err = a.client.QueryBlockByHash(id)
+		if errors.Is(err, blockchain.ErrBlockNotFound) {
+			return fmt.Errorf("未找到该区块: %s", id)
+		}
+	default:
+		blockNumber, ok := new(big.Int).SetString(id, 10)
+		if !ok || blockNumber.Sign() < 0 {
+			return fmt.Errorf("无效的区块号: %s", id)
+		}
+		blockInfo, err = a.client.QueryBlockByNumber(blockNumber)
+	}
 	if err != nil {
-		log.Printf("查询余额失败: %v", err)
-		return
+		return fmt.Errorf("查询失败: %w", err)
+	}
+
+	printBlock(blockInfo)
+	return nil
+}
+
+// runBlocks 查询从起始区块开始的多个区块，部分区块失败时返回错误
+func (a *app) runBlocks(args []string) error {
+	if len(args) != 2 {
+		return errors.New("用法: blocks <起始区块号> <数量>")
+	}
+	startBlock, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || startBlock < 0 {
+		return fmt.Errorf("无效的起始区块号: %s", args[0])
 	}
+	count, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || count <= 0 || count > maxBlockQueryCount {
+		return fmt.Errorf("无效的查询数量 %s (1-%d)", args[1], maxBlockQueryCount)
+	}
+
+	result, err := a.client.QueryMultipleBlocks(a.client.GetContext(), startBlock, count, blockchain.DefaultQueryWorkers)
+	if err != nil {
+		return fmt.Errorf("查询失败: %w", err)
+	}
+
+	printBlocks(result.Blocks)
+
+	if result.Failed() > 0 {
+		fmt.Fprintf(ui, "\n⚠️  %d 个区块查询失败:\n", result.Failed())
+		for number := startBlock; number < startBlock+count; number++ {
+			if err, ok := result.Errors[number]; ok {
+				fmt.Fprintf(ui, "  区块 %d: %v\n", number, err)
+			}
+		}
+		return fmt.Errorf("%d 个区块查询失败", result.Failed())
+	}
+	return nil
+}
 
-	// 转换为ETH
-	balanceEth := new(big.Float)
-	balanceEth.SetString(balance.String())
-	balanceEth = balanceEth.Quo(balanceEth, big.NewFloat(1e18))
+// runBalance 查询地址余额
+func (a *app) runBalance(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("用法: balance <地址>")
+	}
+	address := args[0]
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("无效的地址: %s", address)
+	}
+
+	balance, err := a.client.GetBalance(address)
+	if err != nil {
+		return fmt.Errorf("查询余额失败: %w", err)
+	}
+
+	if outputFormat != output.FormatText {
+		printRecord(&output.Balance{Address: common.HexToAddress(address), Wei: balance})
+		return nil
+	}
 
 	fmt.Printf("余额: %s Wei\n", balance.String())
-	fmt.Printf("余额: %s ETH\n", balanceEth.String())
+	fmt.Printf("余额: %s ETH\n", utils.WeiToEther(balance))
+	return nil
 }
 
-func sendTransaction(client *blockchain.Client, cfg *config.Config, scanner *bufio.Scanner) {
-	if cfg.PrivateKey == "" {
-		fmt.Println("❌ 未配置私钥，无法发送交易")
-		fmt.Println("请在 .env 文件中设置 PRIVATE_KEY")
-		return
+// runSend 发送转账交易，-to 未指定时使用配置中的 TO_ADDRESS
+func (a *app) runSend(args []string) error {
+	if a.signer == nil {
+		return errNoSigner
 	}
 
-	fmt.Print("请输入接收方地址 (留空使用默认): ")
-	if !scanner.Scan() {
-		return
+	fs := newFlagSet("send")
+	toAddress := fs.String("to", a.cfg.ToAddress, "接收方地址")
+	amountStr := fs.String("amount", "", "转账金额 (ETH)")
+	yes := fs.Bool("yes", false, "不确认直接发送")
+	if positional, err := parseArgs(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		return fmt.Errorf("多余的参数: %s", strings.Join(positional, " "))
 	}
 
-	toAddress := strings.TrimSpace(scanner.Text())
-	if toAddress == "" {
-		toAddress = cfg.ToAddress
-		if toAddress == "" {
-			fmt.Println("❌ 未指定接收方地址")
-			return
+	if *toAddress == "" {
+		return errors.New("未指定接收方地址")
+	}
+	if !common.IsHexAddress(*toAddress) {
+		return fmt.Errorf("无效的接收方地址: %s", *toAddress)
+	}
+	amount, err := parseEtherAmount(*amountStr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ui, "\n⛽ 估算 Gas 和手续费...\n")
+	cost, err := a.client.EstimateTransaction(a.signer.Address(), *toAddress, amount)
+	if err != nil {
+		return err
+	}
+	cost.Print(ui)
+	if err := cost.CheckBalance(); err != nil {
+		return err
+	}
+	if !*yes && !confirm("确认发送以上交易? (y/N): ") {
+		return errors.New("已取消发送")
+	}
+
+	fmt.Fprintf(ui, "\n💸 发送转账交易...\n")
+	txInfo, err := a.client.SendEstimatedTransaction(a.signer, cost)
+	if err != nil {
+		return fmt.Errorf("发送交易失败: %w", err)
+	}
+
+	printTransaction(txInfo)
+	fmt.Fprintf(ui, "🔗 查看交易: https://sepolia.etherscan.io/tx/%s\n", txInfo.Hash)
+	return nil
+}
+
+// runSpeedUp 以更高费用重发同一 nonce 的交易
+func (a *app) runSpeedUp(args []string) error {
+	return a.runReplace("speedup", args, false)
+}
+
+// runCancel 以更高费用向自己发送 0 ETH 占用同一 nonce
+func (a *app) runCancel(args []string) error {
+	return a.runReplace("cancel", args, true)
+}
+
+// runReplace 加速或取消待处理交易
+func (a *app) runReplace(name string, args []string, cancel bool) error {
+	if a.signer == nil {
+		return errNoSigner
+	}
+
+	fs := newFlagSet(name)
+	bumpPercent := fs.Int64("bump", blockchain.MinReplacementBump, "费用涨幅百分比")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] == "" {
+		return fmt.Errorf("用法: %s <交易哈希> [-bump <百分比>]", name)
+	}
+	if *bumpPercent < blockchain.MinReplacementBump {
+		return fmt.Errorf("无效的涨幅 %d%%，至少为 %d%%", *bumpPercent, blockchain.MinReplacementBump)
+	}
+	txHash := positional[0]
+
+	var txInfo *blockchain.TransactionInfo
+	if cancel {
+		fmt.Fprintf(ui, "\n🛑 取消交易 %s...\n", txHash)
+		txInfo, err = a.client.CancelTransaction(a.signer, txHash, *bumpPercent)
+	} else {
+		fmt.Fprintf(ui, "\n🚀 加速交易 %s...\n", txHash)
+		txInfo, err = a.client.SpeedUpTransaction(a.signer, txHash, *bumpPercent)
+	}
+	if err != nil {
+		return fmt.Errorf("替换交易失败: %w", err)
+	}
+
+	printTransaction(txInfo)
+	fmt.Fprintf(ui, "🔗 查看交易: https://sepolia.etherscan.io/tx/%s\n", txInfo.Hash)
+	return nil
+}
+
+// runBuild 构建未签名的转账交易并写入文件，交给离线机器签名
+// -from 未指定时使用配置中签名者的地址
+func (a *app) runBuild(args []string) error {
+	fs := newFlagSet("build")
+	defaultFrom := ""
+	if a.signer != nil {
+		defaultFrom = a.signer.Address().Hex()
+	}
+	fromAddress := fs.String("from", defaultFrom, "发送方地址，即离线签名者的地址")
+	toAddress := fs.String("to", a.cfg.ToAddress, "接收方地址")
+	amountStr := fs.String("amount", "", "转账金额 (ETH)")
+	gasLimit := fs.Uint64("gas-limit", 0, "Gas 限制，0 表示按估算值加余量")
+	outFile := fs.String("out", "", "未签名交易的输出文件")
+	if positional, err := parseArgs(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		return fmt.Errorf("多余的参数: %s", strings.Join(positional, " "))
+	}
+
+	if *outFile == "" {
+		return errors.New("未指定输出文件 -out")
+	}
+	if !common.IsHexAddress(*fromAddress) {
+		return fmt.Errorf("无效的发送方地址: %q", *fromAddress)
+	}
+	if *toAddress == "" {
+		return errors.New("未指定接收方地址")
+	}
+	amount, err := parseEtherAmount(*amountStr)
+	if err != nil {
+		return err
+	}
+
+	cost, err := a.client.EstimateTransaction(common.HexToAddress(*fromAddress), *toAddress, amount)
+	if err != nil {
+		return err
+	}
+	if *gasLimit != 0 {
+		cost.GasLimit = *gasLimit
+	}
+	cost.Print(ui)
+	if err := cost.CheckBalance(); err != nil {
+		return err
+	}
+
+	otx, err := a.client.BuildTransaction(cost)
+	if err != nil {
+		return fmt.Errorf("构建交易失败: %w", err)
+	}
+	if err := blockchain.WriteOfflineTransaction(*outFile, otx); err != nil {
+		return err
+	}
+
+	printRecord(otx)
+	fmt.Fprintf(ui, "📝 未签名交易已写入 %s，请在离线机器上核对签名哈希后签名\n", *outFile)
+	return nil
+}
+
+// runSign 在离线机器上为交易文件签名，签名前显示交易内容并要求确认
+// 指定 -keystore 时使用该 KeyStore 文件，密码来自 -password-file 或 KEYSTORE_PASSWORD，否则使用配置中的签名者
+func (a *app) runSign(args []string) error {
+	fs := newFlagSet("sign")
+	outFile := fs.String("out", "", "已签名交易的输出文件")
+	keystorePath := fs.String("keystore", "", "KeyStore 文件路径")
+	passwordFile := fs.String("password-file", "", "KeyStore 密码文件")
+	yes := fs.Bool("yes", false, "不确认直接签名")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("用法: sign <文件> -out <文件>")
+	}
+	if *outFile == "" {
+		return errors.New("未指定输出文件 -out")
+	}
+
+	otx, err := blockchain.ReadOfflineTransaction(positional[0])
+	if err != nil {
+		return err
+	}
+
+	signer := a.signer
+	if *keystorePath != "" {
+		password := a.cfg.KeystorePassword
+		if *passwordFile != "" {
+			data, err := os.ReadFile(*passwordFile)
+			if err != nil {
+				return fmt.Errorf("读取密码文件失败: %w", err)
+			}
+			password = strings.TrimRight(string(data), "\r\n")
 		}
+		if signer, err = utils.NewKeystoreSigner(*keystorePath, password); err != nil {
+			return fmt.Errorf("加载 KeyStore 失败: %w", err)
+		}
+	}
+	if signer == nil {
+		return errNoSigner
 	}
 
-	fmt.Print("请输入转账金额 (ETH): ")
-	if !scanner.Scan() {
-		return
+	// 显示将要签名的内容，签名哈希可与联网机器上的输出核对
+	if err := output.Render(ui, output.FormatText, otx); err != nil {
+		return err
+	}
+	if !*yes && !confirm(fmt.Sprintf("使用 %s 签名以上交易? (y/N): ", signer.Address().Hex())) {
+		return errors.New("已取消签名")
 	}
 
-	amountStr := strings.TrimSpace(scanner.Text())
-	amountFloat, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil || amountFloat <= 0 {
-		fmt.Println("❌ 无效的转账金额")
-		return
+	signed, err := blockchain.SignOfflineTransaction(signer, otx, a.cfg.AllowMainnet)
+	if err != nil {
+		return err
+	}
+	if err := blockchain.WriteOfflineTransaction(*outFile, signed); err != nil {
+		return err
+	}
+
+	if outputFormat != output.FormatText {
+		printRecord(signed)
+	}
+	fmt.Fprintf(ui, "✍️  已签名交易已写入 %s，交易哈希: %s\n", *outFile, signed.Tx.Hash().Hex())
+	return nil
+}
+
+// runBroadcast 广播已签名的交易文件，文件也可以只包含已签名交易的十六进制编码
+func (a *app) runBroadcast(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("用法: broadcast <文件>")
+	}
+
+	otx, err := blockchain.ReadOfflineTransaction(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ui, "\n📡 广播交易 %s...\n", otx.Tx.Hash().Hex())
+	txInfo, err := a.client.BroadcastTransaction(otx)
+	if err != nil {
+		return err
+	}
+
+	printTransaction(txInfo)
+	fmt.Fprintf(ui, "🔗 查看交易: https://sepolia.etherscan.io/tx/%s\n", txInfo.Hash)
+	return nil
+}
+
+// runDecode 显示交易文件的内容、签名哈希和 RLP 编码，不需要连接节点
+func (a *app) runDecode(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("用法: decode <文件>")
 	}
 
-	// 转换为Wei
-	amount := blockchain.EtherToWei(amountFloat)
+	otx, err := blockchain.ReadOfflineTransaction(args[0])
+	if err != nil {
+		return err
+	}
+	printRecord(otx)
+	return nil
+}
 
-	fmt.Printf("\n💸 发送转账交易...\n")
-	fmt.Printf("接收方: %s\n", toAddress)
-	fmt.Printf("金额: %s ETH (%s Wei)\n", amountStr, amount.String())
+// confirm 打印提示并从标准输入读取确认，输入 y 或 yes 时返回 true
+// 批处理文件从标准输入读取时无法确认，直接返回 false
+func confirm(message string) bool {
+	if stdinIsBatch {
+		fmt.Fprintln(ui, "⚠️  批处理从标准输入读取，无法确认，请使用 -yes")
+		return false
+	}
+	answer, ok := prompt(stdin, message)
+	if !ok {
+		return false
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// newFlagSet 创建子命令的参数集，解析错误以 error 返回而不是退出进程
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
 
-	txInfo, err := client.SendTransaction(cfg.PrivateKey, toAddress, amount)
+// parseArgs 解析参数，允许位置参数和选项交替出现，返回位置参数
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", fs.Name(), err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// parseEtherAmount 精确解析以 ETH 为单位的正数金额并转换为 Wei，最多 18 位小数
+func parseEtherAmount(amountStr string) (*big.Int, error) {
+	amount, err := blockchain.EtherToWei(amountStr)
 	if err != nil {
-		log.Printf("发送交易失败: %v", err)
+		return nil, fmt.Errorf("无效的转账金额: %w", err)
+	}
+	if amount.Sign() == 0 {
+		return nil, fmt.Errorf("无效的转账金额: %q 必须大于 0", amountStr)
+	}
+	return amount, nil
+}
+
+// printBlock 按输出格式打印区块信息
+func printBlock(info *blockchain.BlockInfo) {
+	if outputFormat == output.FormatText {
+		info.PrintBlockInfo()
 		return
 	}
+	printRecord(info)
+}
 
-	txInfo.PrintTransactionInfo()
-	fmt.Printf("🔗 查看交易: https://sepolia.etherscan.io/tx/%s\n", txInfo.Hash)
+// printBlocks 按输出格式打印多个区块，JSON 格式输出为数组
+func printBlocks(blocks []*blockchain.BlockInfo) {
+	if outputFormat == output.FormatText {
+		for i, block := range blocks {
+			fmt.Printf("\n--- 区块 %d ---", i+1)
+			block.PrintBlockInfo()
+		}
+		return
+	}
+
+	records := make([]output.Record, len(blocks))
+	for i, block := range blocks {
+		records[i] = block
+	}
+	if err := output.RenderList(os.Stdout, outputFormat, records); err != nil {
+		log.Printf("输出结果失败: %v", err)
+	}
+}
+
+// printTransaction 按输出格式打印交易信息
+func printTransaction(info *blockchain.TransactionInfo) {
+	if outputFormat == output.FormatText {
+		info.PrintTransactionInfo()
+		return
+	}
+	printRecord(info)
+}
+
+// printRecord 以机器可读格式输出单条结果到 stdout
+func printRecord(record output.Record) {
+	if err := output.Render(os.Stdout, outputFormat, record); err != nil {
+		log.Printf("输出结果失败: %v", err)
+	}
 }
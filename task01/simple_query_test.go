@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/local/dapp-basics-task01/blockchain"
-	"github.com/local/dapp-basics-task01/config"
+	"github.com/local/go-eth-demo/config"
 )
 
 func main() {
@@ -15,7 +15,10 @@ func main() {
 	fmt.Println("==================")
 
 	// 加载配置
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 	fmt.Printf("📡 网络: %s\n", cfg.NetworkName)
 
 	// 尝试多个可靠的RPC端点
@@ -26,11 +29,10 @@ func main() {
 	}
 
 	var client *blockchain.Client
-	var err error
 
 	for i, endpoint := range rpcEndpoints {
 		fmt.Printf("\n🔄 尝试端点 %d: %s\n", i+1, endpoint)
-		client, err = blockchain.NewClient(endpoint)
+		client, err = blockchain.NewClient(endpoint, cfg.ChainID)
 		if err != nil {
 			fmt.Printf("❌ 连接失败: %v\n", err)
 			continue
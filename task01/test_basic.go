@@ -6,7 +6,8 @@ import (
 	"math/big"
 
 	"github.com/local/dapp-basics-task01/blockchain"
-	"github.com/local/dapp-basics-task01/config"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/utils"
 )
 
 func main() {
@@ -14,14 +15,17 @@ func main() {
 	fmt.Println("================")
 
 	// 加载配置
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 	fmt.Printf("✅ 配置加载成功\n")
 	fmt.Printf("📡 网络: %s\n", cfg.NetworkName)
 	fmt.Printf("🔗 RPC URL: %s\n", cfg.EthereumRPCURL)
 
 	// 测试区块链连接
 	fmt.Println("\n🔍 测试区块链连接...")
-	client, err := blockchain.NewClient(cfg.EthereumRPCURL)
+	client, err := blockchain.NewClientFromConfig(cfg)
 	if err != nil {
 		log.Fatalf("❌ 连接失败: %v", err)
 	}
@@ -58,10 +62,7 @@ func main() {
 	if err != nil {
 		log.Printf("❌ 查询余额失败: %v", err)
 	} else {
-		balanceEth := new(big.Float)
-		balanceEth.SetString(balance.String())
-		balanceEth = balanceEth.Quo(balanceEth, big.NewFloat(1e18))
-		fmt.Printf("✅ 地址 %s 余额: %s ETH\n", testAddress, balanceEth.String())
+		fmt.Printf("✅ 地址 %s 余额: %s ETH\n", testAddress, utils.WeiToEther(balance))
 	}
 
 	fmt.Println("\n🎉 基础功能测试完成!")
@@ -6,7 +6,8 @@ import (
 	"math/big"
 
 	"github.com/local/dapp-basics-task01/blockchain"
-	"github.com/local/dapp-basics-task01/config"
+	"github.com/local/go-eth-demo/config"
+	"github.com/local/go-eth-demo/utils"
 )
 
 func main() {
@@ -14,7 +15,10 @@ func main() {
 	fmt.Println("==================")
 
 	// 加载配置
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 	fmt.Printf("📡 网络: %s\n", cfg.NetworkName)
 	fmt.Printf("🔗 RPC URL: %s\n", cfg.EthereumRPCURL)
 
@@ -27,11 +31,10 @@ func main() {
 	}
 
 	var client *blockchain.Client
-	var err error
 
 	for i, endpoint := range rpcEndpoints {
 		fmt.Printf("\n🔄 尝试连接端点 %d: %s\n", i+1, endpoint)
-		client, err = blockchain.NewClient(endpoint)
+		client, err = blockchain.NewClient(endpoint, cfg.ChainID)
 		if err != nil {
 			fmt.Printf("❌ 连接失败: %v\n", err)
 			continue
@@ -96,10 +99,7 @@ func main() {
 			continue
 		}
 
-		balanceEth := new(big.Float)
-		balanceEth.SetString(balance.String())
-		balanceEth = balanceEth.Quo(balanceEth, big.NewFloat(1e18))
-		fmt.Printf("✅ 余额: %s ETH\n", balanceEth.String())
+		fmt.Printf("✅ 余额: %s ETH\n", utils.WeiToEther(balance))
 	}
 
 	fmt.Println("\n🎉 真实区块查询测试完成!")
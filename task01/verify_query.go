@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/local/go-eth-demo/utils"
 )
 
 func main() {
@@ -75,10 +76,7 @@ func main() {
 		if err != nil {
 			fmt.Printf("❌ 余额查询失败: %v\n", err)
 		} else {
-			balanceEth := new(big.Float)
-			balanceEth.SetString(balance.String())
-			balanceEth = balanceEth.Quo(balanceEth, big.NewFloat(1e18))
-			fmt.Printf("✅ 地址余额: %s ETH\n", balanceEth.String())
+			fmt.Printf("✅ 地址余额: %s ETH\n", utils.WeiToEther(balance))
 		}
 
 		client.Close()